@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/avito/internal/config"
+	"github.com/avito/internal/domain"
+	"github.com/avito/internal/repository/postgres"
+	"github.com/avito/internal/service"
+	"github.com/joho/godotenv"
+)
+
+const usage = `Управление правами администратора.
+
+Использование:
+  admin grant USERNAME    выдать пользователю права администратора
+  admin revoke USERNAME   отозвать права администратора
+`
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Загрузка переменных окружения из .env файла, если он есть
+	_ = godotenv.Load()
+
+	cfg, err := config.New()
+	if err != nil {
+		fail(err)
+	}
+
+	if err := run(cfg, os.Args[1], os.Args[2]); err != nil {
+		fail(err)
+	}
+}
+
+func run(cfg *config.Config, command, username string) error {
+	var isAdmin bool
+	switch command {
+	case "grant":
+		isAdmin = true
+	case "revoke":
+		isAdmin = false
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+
+	repo, err := postgres.New(cfg.Postgres.DSN())
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	users := postgres.NewUserRepository(repo)
+	audit := service.NewAuditService(postgres.NewAuditRepository(repo))
+
+	// Действие выполняется вне HTTP-запроса, в журнале оно отличается по user agent
+	ctx := domain.WithRequestMeta(context.Background(), domain.RequestMeta{UserAgent: "cmd/admin"})
+
+	previous, err := users.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return fmt.Errorf("user %q not found", username)
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := users.SetAdmin(ctx, username, isAdmin)
+	if err != nil {
+		return err
+	}
+
+	err = audit.Record(ctx, domain.AuditUserAdminChange, "user", fmt.Sprint(user.ID),
+		map[string]any{"is_admin": previous.IsAdmin}, map[string]any{"is_admin": user.IsAdmin})
+	if err != nil {
+		return fmt.Errorf("admin flag updated, but audit failed: %w", err)
+	}
+
+	fmt.Printf("%s: is_admin=%t\n", user.Username, user.IsAdmin)
+	return nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "admin:", err)
+	os.Exit(1)
+}
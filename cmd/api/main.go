@@ -1,25 +1,28 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/avito/internal/app"
 	"github.com/joho/godotenv"
 )
 
-func init() {
+func main() {
 	// Загрузка переменных окружения из .env файла
-	if err := godotenv.Load(); err != nil {
-		log.Printf("No .env file found")
-	}
-}
+	envErr := godotenv.Load()
 
-func main() {
-	// Инициализация приложения
+	// Инициализация приложения, в том числе логгера по умолчанию
 	app, err := app.NewApp()
 	if err != nil {
-		log.Fatalf("Failed to initialize app: %v", err)
+		slog.Error("failed to initialize app", "error", err)
+		os.Exit(1)
+	}
+	if envErr != nil {
+		slog.Info("no .env file found")
 	}
 
 	// Получение порта из переменных окружения или использование порта по умолчанию
@@ -28,8 +31,17 @@ func main() {
 		port = "8080"
 	}
 
-	// Запуск сервера
-	if err := app.Run(":" + port); err != nil {
-		log.Fatalf("Error starting server: %v", err)
+	// Остановка по SIGINT/SIGTERM. Повторный сигнал завершает процесс сразу,
+	// не дожидаясь окончания плавной остановки
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	// Запуск сервера до получения сигнала
+	if err := app.Run(ctx, ":"+port); err != nil {
+		slog.Error("server stopped", "error", err)
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/avito/internal/config"
+	"github.com/avito/internal/repository/postgres"
+	"github.com/joho/godotenv"
+	"github.com/pressly/goose/v3"
+)
+
+const usage = `Применение встроенных миграций базы данных.
+
+Использование:
+  migrate up              применить все новые миграции
+  migrate down            откатить последнюю миграцию
+  migrate status          показать состояние миграций
+  migrate to VERSION      применить или откатить миграции до версии VERSION
+  migrate create NAME     создать файл новой миграции в MIGRATIONS_DIR
+`
+
+// migrationTemplate повторяет заголовки существующих миграций
+const migrationTemplate = `-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+`
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Загрузка переменных окружения из .env файла, если он есть
+	_ = godotenv.Load()
+
+	cfg, err := config.New()
+	if err != nil {
+		fail(err)
+	}
+
+	if err := run(cfg, os.Args[1], os.Args[2:]); err != nil {
+		fail(err)
+	}
+}
+
+func run(cfg *config.Config, command string, args []string) error {
+	// Создание файла не требует подключения к базе
+	if command == "create" {
+		if len(args) != 1 {
+			return errors.New("usage: migrate create NAME")
+		}
+		return create(cfg.Migrations.Dir, args[0])
+	}
+
+	repo, err := postgres.New(cfg.Postgres.DSN())
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	migrator, err := postgres.NewMigrator(repo)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		results, err := migrator.Up(ctx)
+		printResults(results)
+		return err
+	case "down":
+		result, err := migrator.Down(ctx)
+		if errors.Is(err, goose.ErrNoNextVersion) {
+			fmt.Println("no migrations to roll back")
+			return nil
+		}
+		if result != nil {
+			printResults([]*goose.MigrationResult{result})
+		}
+		return err
+	case "to":
+		if len(args) != 1 {
+			return errors.New("usage: migrate to VERSION")
+		}
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[0])
+		}
+		results, err := migrator.To(ctx, version)
+		printResults(results)
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		printStatus(statuses)
+		return nil
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func printResults(results []*goose.MigrationResult) {
+	if len(results) == 0 {
+		fmt.Println("no migrations to apply")
+		return
+	}
+	for _, result := range results {
+		fmt.Println(result)
+	}
+}
+
+func printStatus(statuses []*goose.MigrationStatus) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED AT\tFILE")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.UTC().Format(time.DateTime)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n",
+			status.Source.Version, status.State, appliedAt, filepath.Base(status.Source.Path))
+	}
+	_ = w.Flush()
+}
+
+// create создает файл NNN_name.sql со следующим по порядку номером
+func create(dir, name string) error {
+	name = strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+	if !migrationName.MatchString(name) {
+		return fmt.Errorf("invalid migration name %q: use letters, digits and underscores", name)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+
+	var latest int64
+	for _, file := range files {
+		prefix, _, ok := strings.Cut(filepath.Base(file), "_")
+		if !ok {
+			continue
+		}
+		if version, err := strconv.ParseInt(prefix, 10, 64); err == nil {
+			latest = max(latest, version)
+		}
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%03d_%s.sql", latest+1, name))
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create migration: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(migrationTemplate); err != nil {
+		return fmt.Errorf("failed to write migration: %w", err)
+	}
+
+	fmt.Println("created", path)
+	return nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "migrate:", err)
+	os.Exit(1)
+}
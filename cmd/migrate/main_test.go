@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreate(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		input    string
+		wantFile string
+		wantErr  bool
+	}{
+		{name: "empty dir", input: "init", wantFile: "001_init.sql"},
+		{
+			name:     "next version",
+			existing: []string{"001_init.sql", "017_analytics.sql", "009_wishlist.sql"},
+			input:    "add_index",
+			wantFile: "018_add_index.sql",
+		},
+		{
+			name:     "ignores other files",
+			existing: []string{"002_orders.sql", "embed.go", "999_notes.txt", "draft.sql"},
+			input:    "refunds",
+			wantFile: "003_refunds.sql",
+		},
+		{name: "normalizes name", input: "Add-Kudos", wantFile: "001_add_kudos.sql"},
+		{name: "invalid name", input: "drop table;", wantErr: true},
+		{name: "empty name", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.existing {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := create(dir, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("create(%q) error = %v, want error: %t", tt.input, err, tt.wantErr)
+			}
+
+			files, _ := filepath.Glob(filepath.Join(dir, "*"))
+			if tt.wantErr {
+				// Ошибка не оставляет файлов
+				if len(files) != len(tt.existing) {
+					t.Errorf("files = %v, want only existing", files)
+				}
+				return
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, tt.wantFile))
+			if err != nil {
+				t.Fatalf("migration %s not created: %v (files %v)", tt.wantFile, err, files)
+			}
+			if string(data) != migrationTemplate {
+				t.Errorf("content = %q, want template", data)
+			}
+		})
+	}
+}
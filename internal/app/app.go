@@ -1,17 +1,52 @@
 package app
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/avito/internal/config"
 	"github.com/avito/internal/delivery/http/handler"
+	"github.com/avito/internal/delivery/http/middleware"
 	"github.com/avito/internal/domain"
+	"github.com/avito/internal/health"
+	"github.com/avito/internal/logger"
+	"github.com/avito/internal/metrics"
+	"github.com/avito/internal/publisher"
+	"github.com/avito/internal/repository/filesystem"
 	"github.com/avito/internal/repository/postgres"
 	"github.com/avito/internal/service"
+	"github.com/avito/internal/tracing"
+	"github.com/avito/internal/version"
+	"github.com/avito/internal/webhook"
+	"github.com/avito/internal/worker"
 	"github.com/gin-gonic/gin"
 )
 
 type App struct {
-	router *gin.Engine
-	cfg    *config.Config
+	router    *gin.Engine
+	cfg       *config.Config
+	logger    *slog.Logger
+	repos     *postgres.Repositories
+	publisher domain.Publisher
+	outbox    *worker.OutboxRelay
+	webhooks  *worker.WebhookDispatcher
+	allowance *worker.AllowanceScheduler
+	analytics *worker.AnalyticsRefresher
+	// metricsServer отдает /metrics на отдельном порту, nil - метрики на основном роутере
+	metricsServer *http.Server
+	// shutdownTracing дописывает накопленные спаны в экспортер
+	shutdownTracing func(context.Context) error
+	// ready снимается в начале остановки, чтобы /readyz исключил реплику до завершения запросов
+	ready *atomic.Bool
 }
 
 func NewApp() (*App, error) {
@@ -21,39 +56,306 @@ func NewApp() (*App, error) {
 		return nil, err
 	}
 
+	// Инициализируем логгер и делаем его логгером по умолчанию для кода вне приложения
+	appLogger, err := logger.New(os.Stdout, cfg.Log.Level)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(appLogger)
+
+	// Инициализируем трассировку до подключения к базе, чтобы запросы попадали в трассы
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		return nil, err
+	}
+
 	// Инициализируем репозитории
-	repos, err := postgres.NewRepositories(cfg.Postgres.DSN())
+	repos, err := postgres.NewRepositories(cfg.Postgres.DSN(), appLogger)
 	if err != nil {
 		return nil, err
 	}
 
+	// Применяем миграции, если включено. Реплики ждут друг друга на advisory lock.
+	migrator, err := postgres.NewMigrator(repos.DB)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Migrations.AutoMigrate {
+		results, err := migrator.Up(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply migrations: %w", err)
+		}
+		for _, result := range results {
+			appLogger.Info("migration applied", "version", result.Source.Version, "duration", result.Duration)
+		}
+	}
+
+	// Инициализируем файловое хранилище
+	blobs, err := filesystem.NewBlobStore(cfg.Storage.Dir, cfg.Storage.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Инициализируем метрики
+	appMetrics := metrics.New()
+	appMetrics.RegisterDB(repos.DB.DB(), cfg.Postgres.DBName)
+
 	// Инициализируем сервисы
 	deps := domain.Deps{
 		Repos: &domain.Repositories{
-			User:        repos.User,
-			Merch:       repos.Merch,
-			Purchase:    repos.Purchase,
-			Transaction: repos.Transaction,
+			User:         repos.User,
+			Merch:        repos.Merch,
+			Purchase:     repos.Purchase,
+			Transaction:  repos.Transaction,
+			Variant:      repos.Variant,
+			Cart:         repos.Cart,
+			Order:        repos.Order,
+			Snapshot:     repos.Snapshot,
+			Wishlist:     repos.Wishlist,
+			Notification: repos.Notification,
+			Outbox:       repos.Outbox,
+			Webhook:      repos.Webhook,
+			Audit:        repos.Audit,
+			Allowance:    repos.Allowance,
+			Kudos:        repos.Kudos,
+			Analytics:    repos.Analytics,
 		},
-		TokenSecret: cfg.JWT.SecretKey,
+		Blobs:        blobs,
+		Events:       repos.Events,
+		TokenSecret:  cfg.JWT.SecretKey,
+		MaxImageSize: cfg.Storage.MaxImageSize,
+		CoinExpiry: domain.ExpiryRule{
+			Months: cfg.Allowance.ExpiryMonths,
+			Day:    cfg.Allowance.ExpiryDay,
+		},
+		Metrics: appMetrics,
+		Logger:  appLogger,
 	}
 	services := service.NewServices(deps)
 
+	// Инициализируем отправку событий outbox: во внешний публикатор и в очередь вебхуков
+	outboxPublisher, err := newPublisher(cfg.Outbox)
+	if err != nil {
+		return nil, err
+	}
+	outboxRelay := worker.NewOutboxRelay(
+		repos.Outbox,
+		publisher.NewMultiPublisher(outboxPublisher, services.Webhook),
+		cfg.Outbox.Interval,
+		cfg.Outbox.BatchSize,
+		appLogger,
+	)
+	webhookDispatcher := worker.NewWebhookDispatcher(
+		repos.Webhook,
+		webhook.NewSender(cfg.Webhook.Timeout),
+		cfg.Webhook.Interval,
+		cfg.Webhook.BatchSize,
+		cfg.Webhook.MaxAttempts,
+		appLogger,
+	)
+
+	allowanceScheduler := worker.NewAllowanceScheduler(services.Allowance, cfg.Allowance.Interval, appLogger)
+	analyticsRefresher := worker.NewAnalyticsRefresher(services.Analytics, cfg.Analytics.RefreshInterval, appLogger)
+
 	// Инициализируем handler
 	h := handler.NewHandler(services)
 
 	// Создаем новый роутер
-	router := gin.Default()
+	router := gin.New()
+	router.Use(
+		middleware.RequestMeta(),
+		middleware.Tracing(cfg.Tracing.ServiceName, pollingPaths...),
+		middleware.AccessLog(appLogger, pollingPaths...),
+		middleware.Recovery(appLogger),
+		middleware.Metrics(appMetrics),
+	)
 
 	// Инициализируем маршруты через handler
 	h.Init(router, cfg.JWT.SecretKey)
 
+	// Инициализируем пробы оркестратора
+
+	ready := &atomic.Bool{}
+	checker := health.New(cfg.Health.CheckTimeout, appLogger)
+	checker.Add("server", health.Func(func() error {
+		if !ready.Load() {
+			return errors.New("server is not accepting requests")
+		}
+		return nil
+	}))
+	checker.Add("database", repos.DB.Ping)
+	checker.Add("migrations", health.Migrations(repos.DB.MigrationVersion, migrator.Latest()))
+	checker.Add("outbox_relay", health.Func(outboxRelay.Health))
+	checker.Add("webhook_dispatcher", health.Func(webhookDispatcher.Health))
+	checker.Add("allowance_scheduler", health.Func(allowanceScheduler.Health))
+	checker.Add("analytics_refresher", health.Func(analyticsRefresher.Health))
+	h.InitProbes(router, checker, version.Info())
+
+	// Раздаем загруженные файлы
+	router.Static(cfg.Storage.BaseURL, cfg.Storage.Dir)
+
+	// Отдаем метрики на отдельном порту или на основном роутере
+	var metricsServer *http.Server
+	metricsHandler := appMetrics.Handler(cfg.Metrics.Token)
+	if cfg.Metrics.Addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metricsHandler)
+		metricsServer = &http.Server{
+			Addr:              cfg.Metrics.Addr,
+			Handler:           mux,
+			ReadHeaderTimeout: cfg.HTTP.ReadTimeout,
+		}
+	} else {
+		router.GET("/metrics", gin.WrapH(metricsHandler))
+	}
+
 	return &App{
-		router: router,
-		cfg:    cfg,
+		router:    router,
+		cfg:       cfg,
+		logger:    appLogger,
+		repos:     repos,
+		publisher: outboxPublisher,
+		outbox:    outboxRelay,
+		webhooks:  webhookDispatcher,
+		allowance: allowanceScheduler,
+		analytics: analyticsRefresher,
+
+		metricsServer:   metricsServer,
+		shutdownTracing: shutdownTracing,
+		ready:           ready,
 	}, nil
 }
 
-func (a *App) Run(addr string) error {
-	return a.router.Run(addr)
+// Run запускает сервер и фоновые обработчики и блокируется до отмены ctx
+// или ошибки сервера, после чего останавливает приложение
+func (a *App) Run(ctx context.Context, addr string) error {
+	// Порты занимаются до запуска воркеров и отметки о готовности:
+	// если адрес занят, реплика не объявляет себя готовой и сразу завершается с ошибкой
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	var metricsListener net.Listener
+	if a.metricsServer != nil {
+		metricsListener, err = net.Listen("tcp", a.metricsServer.Addr)
+		if err != nil {
+			_ = listener.Close()
+			return fmt.Errorf("failed to listen on %s: %w", a.metricsServer.Addr, err)
+		}
+	}
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+	for _, run := range []func(context.Context){a.outbox.Run, a.webhooks.Run, a.allowance.Run, a.analytics.Run} {
+		workers.Add(1)
+		go func(run func(context.Context)) {
+			defer workers.Done()
+			run(workersCtx)
+		}(run)
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           a.router,
+		ReadHeaderTimeout: a.cfg.HTTP.ReadTimeout,
+		ReadTimeout:       a.cfg.HTTP.ReadTimeout,
+		WriteTimeout:      a.cfg.HTTP.WriteTimeout,
+	}
+	// Shutdown не прерывает потоковые ответы, поэтому подписки на события закрываются отдельно
+	server.RegisterOnShutdown(a.repos.Events.Disconnect)
+
+	serverErr := make(chan error, 2)
+	serve := func(srv *http.Server, ln net.Listener) {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- fmt.Errorf("server %s: %w", srv.Addr, err)
+		}
+	}
+	go serve(server, listener)
+	if a.metricsServer != nil {
+		go serve(a.metricsServer, metricsListener)
+	}
+
+	a.ready.Store(true)
+	a.logger.Info("server started", "addr", addr)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.logger.Info("shutdown started")
+	case runErr = <-serverErr:
+		a.logger.Error("server failed", "error", runErr)
+	}
+
+	a.shutdown(server, stopWorkers, &workers)
+	a.logger.Info("shutdown completed")
+
+	return runErr
+}
+
+// shutdown останавливает приложение по шагам: снимает готовность, дожидается текущих запросов,
+// останавливает фоновые обработчики и только после этого закрывает подключения, которыми они пользуются
+func (a *App) shutdown(server *http.Server, stopWorkers context.CancelFunc, workers *sync.WaitGroup) {
+	a.ready.Store(false)
+	if a.cfg.HTTP.DrainDelay > 0 {
+		time.Sleep(a.cfg.HTTP.DrainDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		a.logger.Error("failed to drain requests", "error", err)
+		_ = server.Close()
+	}
+
+	stopWorkers()
+	workers.Wait()
+
+	if a.metricsServer != nil {
+		if err := a.metricsServer.Shutdown(ctx); err != nil {
+			_ = a.metricsServer.Close()
+		}
+	}
+
+	if closer, ok := a.publisher.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			a.logger.Error("failed to close outbox publisher", "error", err)
+		}
+	}
+	if err := a.repos.Events.Close(); err != nil {
+		a.logger.Error("failed to close events listener", "error", err)
+	}
+	if err := a.repos.DB.Close(); err != nil {
+		a.logger.Error("failed to close database", "error", err)
+	}
+
+	// Спаны дописываются последними, чтобы в них попали и запросы остановки
+	tracingCtx, cancelTracing := context.WithTimeout(context.Background(), a.cfg.HTTP.ShutdownTimeout)
+	defer cancelTracing()
+	if err := a.shutdownTracing(tracingCtx); err != nil {
+		a.logger.Error("failed to shut down tracing", "error", err)
+	}
+}
+
+// pollingPaths - пробы оркестратора и метрики. Их опрашивают постоянно,
+// поэтому они не пишутся в журнал запросов и трассы.
+var pollingPaths = []string{"/healthz", "/readyz", "/version", "/metrics"}
+
+// newPublisher выбирает способ доставки событий outbox по конфигурации
+func newPublisher(cfg config.OutboxConfig) (domain.Publisher, error) {
+	switch cfg.Publisher {
+	case "stdout":
+		return publisher.NewStdoutPublisher(), nil
+	case "file":
+		return publisher.NewFilePublisher(cfg.FilePath)
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, errors.New("OUTBOX_WEBHOOK_URL is required for webhook publisher")
+		}
+		return publisher.NewWebhookPublisher(cfg.WebhookURL, cfg.WebhookTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", cfg.Publisher)
+	}
 }
@@ -8,16 +8,29 @@ import (
 )
 
 type Config struct {
-	HTTP     HTTPConfig
-	Postgres PostgresConfig
-	JWT      JWTConfig
+	HTTP       HTTPConfig
+	Postgres   PostgresConfig
+	JWT        JWTConfig
+	Storage    StorageConfig
+	Outbox     OutboxConfig
+	Webhook    WebhookConfig
+	Allowance  AllowanceConfig
+	Analytics  AnalyticsConfig
+	Metrics    MetricsConfig
+	Tracing    TracingConfig
+	Log        LogConfig
+	Health     HealthConfig
+	Migrations MigrationsConfig
 }
 
 type HTTPConfig struct {
 	Port            string
 	ShutdownTimeout time.Duration
-	ReadTimeout     time.Duration
-	WriteTimeout    time.Duration
+	// DrainDelay - пауза между снятием готовности и остановкой приема запросов,
+	// за которую балансировщик успевает исключить реплику
+	DrainDelay   time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
 }
 
 type PostgresConfig struct {
@@ -34,6 +47,79 @@ type JWTConfig struct {
 	TTL       time.Duration
 }
 
+type StorageConfig struct {
+	// Dir - каталог на диске для загруженных файлов
+	Dir string
+	// BaseURL - префикс, по которому файлы раздаются статикой
+	BaseURL      string
+	MaxImageSize int64
+}
+
+type OutboxConfig struct {
+	// Publisher - способ доставки событий: stdout, file или webhook
+	Publisher      string
+	FilePath       string
+	WebhookURL     string
+	WebhookTimeout time.Duration
+	Interval       time.Duration
+	BatchSize      int
+}
+
+type WebhookConfig struct {
+	Timeout time.Duration
+	// MaxAttempts - число попыток, после которого доставка переходит в dead
+	MaxAttempts int
+	Interval    time.Duration
+	BatchSize   int
+}
+
+type AllowanceConfig struct {
+	// Interval - период проверки наступивших начислений и сгорания
+	Interval time.Duration
+	// ExpiryMonths - возраст монет, после которого они сгорают, 0 отключает сгорание
+	ExpiryMonths int
+	ExpiryDay    int
+}
+
+type AnalyticsConfig struct {
+	// RefreshInterval - период обновления витрин аналитики
+	RefreshInterval time.Duration
+}
+
+type MetricsConfig struct {
+	// Addr - отдельный адрес для /metrics, пустой означает основной роутер
+	Addr string
+	// Token, если задан, требуется в заголовке Authorization: Bearer
+	Token string
+}
+
+type TracingConfig struct {
+	// Exporter - куда отправлять спаны: none, stdout или otlp
+	Exporter string
+	// Endpoint - адрес OTLP/HTTP коллектора, пустой означает OTEL_EXPORTER_OTLP_ENDPOINT
+	Endpoint    string
+	ServiceName string
+	// SampleRatio - доля записываемых трасс, начатых этим сервисом
+	SampleRatio float64
+}
+
+type LogConfig struct {
+	// Level - минимальный уровень записей: debug, info, warn или error
+	Level string
+}
+
+type HealthConfig struct {
+	// CheckTimeout - общий таймаут проверок /readyz
+	CheckTimeout time.Duration
+}
+
+type MigrationsConfig struct {
+	// Dir - каталог исходников миграций, в котором cmd/migrate create создает новые файлы
+	Dir string
+	// AutoMigrate применяет встроенные миграции при запуске API
+	AutoMigrate bool
+}
+
 func New() (*Config, error) {
 	httpPort := os.Getenv("HTTP_PORT")
 	if httpPort == "" {
@@ -45,6 +131,11 @@ func New() (*Config, error) {
 		shutdownTimeout = 5
 	}
 
+	drainDelay, err := strconv.Atoi(os.Getenv("HTTP_DRAIN_DELAY"))
+	if err != nil || drainDelay < 0 {
+		drainDelay = 0
+	}
+
 	readTimeout, err := strconv.Atoi(os.Getenv("HTTP_READ_TIMEOUT"))
 	if err != nil {
 		readTimeout = 5
@@ -60,10 +151,126 @@ func New() (*Config, error) {
 		jwtTTL = 24
 	}
 
+	storageDir := os.Getenv("STORAGE_DIR")
+	if storageDir == "" {
+		storageDir = "./uploads"
+	}
+
+	storageBaseURL := os.Getenv("STORAGE_BASE_URL")
+	if storageBaseURL == "" {
+		storageBaseURL = "/static"
+	}
+
+	maxImageSize, err := strconv.Atoi(os.Getenv("STORAGE_MAX_IMAGE_SIZE"))
+	if err != nil {
+		maxImageSize = 5
+	}
+
+	outboxPublisher := os.Getenv("OUTBOX_PUBLISHER")
+	if outboxPublisher == "" {
+		outboxPublisher = "stdout"
+	}
+
+	outboxFile := os.Getenv("OUTBOX_FILE")
+	if outboxFile == "" {
+		outboxFile = "./outbox.jsonl"
+	}
+
+	outboxWebhookTimeout, err := strconv.Atoi(os.Getenv("OUTBOX_WEBHOOK_TIMEOUT"))
+	if err != nil {
+		outboxWebhookTimeout = 5
+	}
+
+	outboxInterval, err := strconv.Atoi(os.Getenv("OUTBOX_INTERVAL"))
+	if err != nil || outboxInterval <= 0 {
+		outboxInterval = 1
+	}
+
+	outboxBatchSize, err := strconv.Atoi(os.Getenv("OUTBOX_BATCH_SIZE"))
+	if err != nil || outboxBatchSize <= 0 {
+		outboxBatchSize = 100
+	}
+
+	webhookTimeout, err := strconv.Atoi(os.Getenv("WEBHOOK_TIMEOUT"))
+	if err != nil {
+		webhookTimeout = 10
+	}
+
+	webhookMaxAttempts, err := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	if err != nil || webhookMaxAttempts <= 0 {
+		webhookMaxAttempts = 8
+	}
+
+	webhookInterval, err := strconv.Atoi(os.Getenv("WEBHOOK_INTERVAL"))
+	if err != nil || webhookInterval <= 0 {
+		webhookInterval = 1
+	}
+
+	webhookBatchSize, err := strconv.Atoi(os.Getenv("WEBHOOK_BATCH_SIZE"))
+	if err != nil || webhookBatchSize <= 0 {
+		webhookBatchSize = 20
+	}
+
+	allowanceInterval, err := strconv.Atoi(os.Getenv("ALLOWANCE_INTERVAL"))
+	if err != nil || allowanceInterval <= 0 {
+		allowanceInterval = 3600
+	}
+
+	coinExpiryMonths, err := strconv.Atoi(os.Getenv("COIN_EXPIRY_MONTHS"))
+	if err != nil || coinExpiryMonths < 0 {
+		coinExpiryMonths = 0
+	}
+
+	coinExpiryDay, err := strconv.Atoi(os.Getenv("COIN_EXPIRY_DAY"))
+	if err != nil || coinExpiryDay < 1 || coinExpiryDay > 28 {
+		coinExpiryDay = 1
+	}
+
+	analyticsRefreshInterval, err := strconv.Atoi(os.Getenv("ANALYTICS_REFRESH_INTERVAL"))
+	if err != nil || analyticsRefreshInterval <= 0 {
+		analyticsRefreshInterval = 300
+	}
+
+	tracingExporter := os.Getenv("TRACING_EXPORTER")
+	if tracingExporter == "" {
+		tracingExporter = "none"
+	}
+
+	tracingServiceName := os.Getenv("TRACING_SERVICE_NAME")
+	if tracingServiceName == "" {
+		tracingServiceName = "merch-shop"
+	}
+
+	tracingSampleRatio, err := strconv.ParseFloat(os.Getenv("TRACING_SAMPLE_RATIO"), 64)
+	if err != nil || tracingSampleRatio < 0 || tracingSampleRatio > 1 {
+		tracingSampleRatio = 1
+	}
+
+	logLevel := os.Getenv("LOG_LEVEL")
+	if logLevel == "" {
+		logLevel = "info"
+	}
+
+	healthCheckTimeout, err := strconv.Atoi(os.Getenv("HEALTH_CHECK_TIMEOUT"))
+	if err != nil || healthCheckTimeout <= 0 {
+		healthCheckTimeout = 2
+	}
+
+	migrationsDir := os.Getenv("MIGRATIONS_DIR")
+	if migrationsDir == "" {
+		migrationsDir = "./migrations"
+	}
+
+	autoMigrate, err := strconv.ParseBool(os.Getenv("MIGRATE_ON_START"))
+	if err != nil {
+		autoMigrate = false
+	}
+
 	return &Config{
 		HTTP: HTTPConfig{
 			Port:            httpPort,
 			ShutdownTimeout: time.Duration(shutdownTimeout) * time.Second,
+			DrainDelay:      time.Duration(drainDelay) * time.Second,
 			ReadTimeout:     time.Duration(readTimeout) * time.Second,
 			WriteTimeout:    time.Duration(writeTimeout) * time.Second,
 		},
@@ -79,6 +286,53 @@ func New() (*Config, error) {
 			SecretKey: os.Getenv("JWT_SECRET_KEY"),
 			TTL:       time.Duration(jwtTTL) * time.Hour,
 		},
+		Storage: StorageConfig{
+			Dir:          storageDir,
+			BaseURL:      storageBaseURL,
+			MaxImageSize: int64(maxImageSize) << 20,
+		},
+		Outbox: OutboxConfig{
+			Publisher:      outboxPublisher,
+			FilePath:       outboxFile,
+			WebhookURL:     os.Getenv("OUTBOX_WEBHOOK_URL"),
+			WebhookTimeout: time.Duration(outboxWebhookTimeout) * time.Second,
+			Interval:       time.Duration(outboxInterval) * time.Second,
+			BatchSize:      outboxBatchSize,
+		},
+		Webhook: WebhookConfig{
+			Timeout:     time.Duration(webhookTimeout) * time.Second,
+			MaxAttempts: webhookMaxAttempts,
+			Interval:    time.Duration(webhookInterval) * time.Second,
+			BatchSize:   webhookBatchSize,
+		},
+		Allowance: AllowanceConfig{
+			Interval:     time.Duration(allowanceInterval) * time.Second,
+			ExpiryMonths: coinExpiryMonths,
+			ExpiryDay:    coinExpiryDay,
+		},
+		Analytics: AnalyticsConfig{
+			RefreshInterval: time.Duration(analyticsRefreshInterval) * time.Second,
+		},
+		Metrics: MetricsConfig{
+			Addr:  os.Getenv("METRICS_ADDR"),
+			Token: os.Getenv("METRICS_TOKEN"),
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracingExporter,
+			Endpoint:    os.Getenv("TRACING_OTLP_ENDPOINT"),
+			ServiceName: tracingServiceName,
+			SampleRatio: tracingSampleRatio,
+		},
+		Log: LogConfig{
+			Level: logLevel,
+		},
+		Health: HealthConfig{
+			CheckTimeout: time.Duration(healthCheckTimeout) * time.Second,
+		},
+		Migrations: MigrationsConfig{
+			Dir:         migrationsDir,
+			AutoMigrate: autoMigrate,
+		},
 	}, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
 	"github.com/gin-gonic/gin"
 )
 
@@ -11,16 +12,25 @@ import (
 type ErrorResponse struct {
 	Message string `json:"message"`
 	Code    string `json:"code"`
+	// RequestID позволяет найти запрос в логах сервера
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // NewErrorResponse отправляет ответ с ошибкой
 func NewErrorResponse(c *gin.Context, statusCode int, message, code string) {
 	c.AbortWithStatusJSON(statusCode, ErrorResponse{
-		Message: message,
-		Code:    code,
+		Message:   message,
+		Code:      code,
+		RequestID: domain.RequestMetaFrom(c.Request.Context()).RequestID,
 	})
 }
 
+// internalError скрывает от клиента текст ошибки и передает ее в журнал запросов
+func internalError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	NewErrorResponse(c, http.StatusInternalServerError, "internal server error", "internal_error")
+}
+
 // HandleError обрабатывает ошибки и отправляет соответствующий HTTP-ответ
 func HandleError(c *gin.Context, err error) {
 	switch err {
@@ -38,9 +48,56 @@ func HandleError(c *gin.Context, err error) {
 		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_amount")
 	case domain.ErrInvalidQuantity:
 		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_quantity")
+	case domain.ErrCartEmpty:
+		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "cart_empty")
+	case domain.ErrCartItemNotFound:
+		NewErrorResponse(c, http.StatusNotFound, err.Error(), "cart_item_not_found")
+	case domain.ErrOrderNotFound:
+		NewErrorResponse(c, http.StatusNotFound, err.Error(), "order_not_found")
+	case domain.ErrInvalidOrderStatus:
+		NewErrorResponse(c, http.StatusConflict, err.Error(), "invalid_order_status")
+	case domain.ErrInvalidDelivery:
+		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_delivery")
+	case domain.ErrVariantNotFound:
+		NewErrorResponse(c, http.StatusNotFound, err.Error(), "variant_not_found")
+	case domain.ErrVariantRequired:
+		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "variant_required")
+	case domain.ErrOutOfStock:
+		NewErrorResponse(c, http.StatusConflict, err.Error(), "out_of_stock")
+	case domain.ErrInvalidFilter:
+		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_filter")
+	case pagination.ErrInvalidLimit:
+		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_page_size")
+	case pagination.ErrInvalidCursor:
+		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_cursor")
+	case domain.ErrInvalidImage:
+		NewErrorResponse(c, http.StatusUnsupportedMediaType, err.Error(), "invalid_image")
+	case domain.ErrImageTooLarge:
+		NewErrorResponse(c, http.StatusRequestEntityTooLarge, err.Error(), "image_too_large")
+	case domain.ErrWishlistItemNotFound:
+		NewErrorResponse(c, http.StatusNotFound, err.Error(), "wishlist_item_not_found")
+	case domain.ErrNotificationNotFound:
+		NewErrorResponse(c, http.StatusNotFound, err.Error(), "notification_not_found")
+	case domain.ErrWebhookNotFound:
+		NewErrorResponse(c, http.StatusNotFound, err.Error(), "webhook_not_found")
+	case domain.ErrWebhookDeliveryNotFound:
+		NewErrorResponse(c, http.StatusNotFound, err.Error(), "webhook_delivery_not_found")
+	case domain.ErrInvalidWebhook:
+		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_webhook")
+	case domain.ErrInvalidAdjustment:
+		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_adjustment")
+	case domain.ErrGrantScheduleNotFound:
+		NewErrorResponse(c, http.StatusNotFound, err.Error(), "grant_schedule_not_found")
+	case domain.ErrInvalidGrantSchedule:
+		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_grant_schedule")
+	case domain.ErrInvalidKudos:
+		NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_kudos")
+	case domain.ErrForbidden:
+		NewErrorResponse(c, http.StatusForbidden, err.Error(), "forbidden")
 	case domain.ErrTransactionFailed:
+		_ = c.Error(err)
 		NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "transaction_failed")
 	default:
-		NewErrorResponse(c, http.StatusInternalServerError, "internal server error", "internal_error")
+		internalError(c, err)
 	}
 }
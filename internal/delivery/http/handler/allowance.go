@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type allowanceHandler struct {
+	allowanceService domain.AllowanceService
+}
+
+func NewAllowanceHandler(allowanceService domain.AllowanceService) *allowanceHandler {
+	return &allowanceHandler{
+		allowanceService: allowanceService,
+	}
+}
+
+type createGrantScheduleInput struct {
+	Name       string `json:"name" binding:"required"`
+	Amount     int64  `json:"amount" binding:"required,min=1"`
+	DayOfMonth int    `json:"day_of_month" binding:"required,min=1,max=28"`
+}
+
+func (h *allowanceHandler) CreateSchedule(c *gin.Context) {
+	var input createGrantScheduleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	schedule, err := h.allowanceService.CreateSchedule(c.Request.Context(), input.Name, input.Amount, input.DayOfMonth)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.Created(c, "grant schedule created", schedule)
+}
+
+func (h *allowanceHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.allowanceService.ListSchedules(c.Request.Context())
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", schedules)
+}
+
+func (h *allowanceHandler) DeactivateSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	if err := h.allowanceService.DeactivateSchedule(c.Request.Context(), id); err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "grant schedule disabled", nil)
+}
+
+func (h *allowanceHandler) Runs(c *gin.Context) {
+	params, err := httpDelivery.NewPaginationParams(c)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	runs, err := h.allowanceService.Runs(c.Request.Context(), params)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OKPage(c, "Успешный ответ", runs)
+}
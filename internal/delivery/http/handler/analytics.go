@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAnalyticsLimit - размер топа, если limit не указан
+const defaultAnalyticsLimit = 10
+
+type analyticsHandler struct {
+	analyticsService domain.AnalyticsService
+}
+
+func NewAnalyticsHandler(analyticsService domain.AnalyticsService) *analyticsHandler {
+	return &analyticsHandler{
+		analyticsService: analyticsService,
+	}
+}
+
+func (h *analyticsHandler) TopSpenders(c *gin.Context) {
+	rng, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	totals, err := h.analyticsService.TopSpenders(c.Request.Context(), rng)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", totals)
+}
+
+func (h *analyticsHandler) TopReceivers(c *gin.Context) {
+	rng, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	totals, err := h.analyticsService.TopReceivers(c.Request.Context(), rng)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", totals)
+}
+
+func (h *analyticsHandler) TopMerch(c *gin.Context) {
+	rng, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	totals, err := h.analyticsService.TopMerch(c.Request.Context(), rng)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", totals)
+}
+
+func (h *analyticsHandler) Economy(c *gin.Context) {
+	rng, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.analyticsService.Economy(c.Request.Context(), rng)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", summary)
+}
+
+func (h *analyticsHandler) DailyVolumes(c *gin.Context) {
+	rng, ok := parseAnalyticsRange(c)
+	if !ok {
+		return
+	}
+
+	volumes, err := h.analyticsService.DailyVolumes(c.Request.Context(), rng)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", volumes)
+}
+
+// parseAnalyticsRange читает from, to и limit из query string.
+// При ошибке отвечает 400 и возвращает false.
+func parseAnalyticsRange(c *gin.Context) (domain.AnalyticsRange, bool) {
+	rng := domain.AnalyticsRange{Limit: defaultAnalyticsLimit}
+
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"from", &rng.From},
+		{"to", &rng.To},
+	} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := parseDate(raw)
+		if err != nil {
+			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, fmt.Sprintf("invalid %s", p.name), "invalid_filter")
+			return rng, false
+		}
+		*p.dst = &t
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid limit", "invalid_filter")
+			return rng, false
+		}
+		rng.Limit = limit
+	}
+
+	return rng, true
+}
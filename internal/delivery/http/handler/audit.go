@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type auditHandler struct {
+	auditService domain.AuditService
+}
+
+func NewAuditHandler(auditService domain.AuditService) *auditHandler {
+	return &auditHandler{
+		auditService: auditService,
+	}
+}
+
+func (h *auditHandler) List(c *gin.Context) {
+	params, err := httpDelivery.NewPaginationParams(c)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	filter, err := parseAuditFilter(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_filter")
+		return
+	}
+
+	entries, err := h.auditService.List(c.Request.Context(), filter, params)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OKPage(c, "Успешный ответ", entries)
+}
+
+func (h *auditHandler) Verify(c *gin.Context) {
+	result, err := h.auditService.Verify(c.Request.Context())
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", result)
+}
+
+// parseAuditFilter читает параметры поиска по журналу аудита из query string
+func parseAuditFilter(c *gin.Context) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{
+		Action:     domain.AuditAction(c.Query("action")),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+	}
+
+	if raw := c.Query("actor_id"); raw != "" {
+		actorID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return filter, errors.New("invalid actor_id")
+		}
+		filter.ActorID = &actorID
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := parseDate(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s", p.name)
+		}
+		*p.dst = &t
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/avito/internal/delivery/http/middleware"
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type cartHandler struct {
+	cartService domain.CartService
+}
+
+func NewCartHandler(cartService domain.CartService) *cartHandler {
+	return &cartHandler{
+		cartService: cartService,
+	}
+}
+
+type addCartItemInput struct {
+	MerchID   int64  `json:"merch_id" binding:"required"`
+	VariantID *int64 `json:"variant_id"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+type updateCartItemInput struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+func (h *cartHandler) Get(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	cart, err := h.cartService.Get(c.Request.Context(), userID)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", cart)
+}
+
+func (h *cartHandler) AddItem(c *gin.Context) {
+	var input addCartItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	if err := h.cartService.AddItem(c.Request.Context(), userID, input.MerchID, input.VariantID, input.Quantity); err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "item added to cart", nil)
+}
+
+func (h *cartHandler) UpdateItem(c *gin.Context) {
+	merchID, variantID, err := parseCartItemKey(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	var input updateCartItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	if err := h.cartService.UpdateItem(c.Request.Context(), userID, merchID, variantID, input.Quantity); err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "cart item updated", nil)
+}
+
+func (h *cartHandler) RemoveItem(c *gin.Context) {
+	merchID, variantID, err := parseCartItemKey(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	if err := h.cartService.RemoveItem(c.Request.Context(), userID, merchID, variantID); err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "cart item removed", nil)
+}
+
+func (h *cartHandler) Checkout(c *gin.Context) {
+	var input deliveryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	order, err := h.cartService.Checkout(c.Request.Context(), userID, input.toDomain())
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.Created(c, "order created", order)
+}
+
+// parseCartItemKey извлекает товар из пути и необязательный вариант из параметра variant_id
+func parseCartItemKey(c *gin.Context) (int64, *int64, error) {
+	merchID, err := strconv.ParseInt(c.Param("merch_id"), 10, 64)
+	if err != nil {
+		return 0, nil, errors.New("invalid merch id")
+	}
+
+	raw := c.Query("variant_id")
+	if raw == "" {
+		return merchID, nil, nil
+	}
+
+	variantID, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, nil, errors.New("invalid variant id")
+	}
+
+	return merchID, &variantID, nil
+}
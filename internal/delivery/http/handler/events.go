@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/avito/internal/delivery/http/middleware"
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// eventsHeartbeat - интервал комментариев-пингов, не дающих прокси закрыть простаивающее соединение
+const eventsHeartbeat = 25 * time.Second
+
+type eventsHandler struct {
+	events domain.EventBus
+}
+
+func NewEventsHandler(events domain.EventBus) *eventsHandler {
+	return &eventsHandler{
+		events: events,
+	}
+}
+
+// Stream отдает события пользователя в формате Server-Sent Events до отключения клиента
+func (h *eventsHandler) Stream(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	events, unsubscribe := h.events.Subscribe(userID)
+	defer unsubscribe()
+
+	disableWriteTimeout(c)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event)
+			return true
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
+// disableWriteTimeout снимает WriteTimeout сервера для потоковых ответов, длительность которых не ограничена
+func disableWriteTimeout(c *gin.Context) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+}
@@ -7,32 +7,69 @@ import (
 )
 
 type Handler struct {
-	userService        domain.UserService
-	merchService       domain.MerchService
-	transactionService domain.TransactionService
+	userService         domain.UserService
+	merchService        domain.MerchService
+	transactionService  domain.TransactionService
+	cartService         domain.CartService
+	orderService        domain.OrderService
+	statementService    domain.StatementService
+	wishlistService     domain.WishlistService
+	notificationService domain.NotificationService
+	webhookService      domain.WebhookService
+	auditService        domain.AuditService
+	allowanceService    domain.AllowanceService
+	kudosService        domain.KudosService
+	analyticsService    domain.AnalyticsService
+	events              domain.EventBus
+	metrics             domain.BusinessMetrics
 }
 
 func NewHandler(services *domain.Services) *Handler {
 	return &Handler{
-		userService:        services.User,
-		merchService:       services.Merch,
-		transactionService: services.Transaction,
+		userService:         services.User,
+		merchService:        services.Merch,
+		transactionService:  services.Transaction,
+		cartService:         services.Cart,
+		orderService:        services.Order,
+		statementService:    services.Statement,
+		wishlistService:     services.Wishlist,
+		notificationService: services.Notification,
+		webhookService:      services.Webhook,
+		auditService:        services.Audit,
+		allowanceService:    services.Allowance,
+		kudosService:        services.Kudos,
+		analyticsService:    services.Analytics,
+		events:              services.Events,
+		metrics:             services.Metrics,
 	}
 }
 
 func (h *Handler) Init(router *gin.Engine, tokenSecret string) {
 	authMiddleware := middleware.AuthMiddleware(tokenSecret)
+	if h.metrics != nil {
+		authMiddleware = middleware.CountAuthFailures(authMiddleware, h.metrics)
+	}
+	adminMiddleware := middleware.AdminMiddleware(h.userService)
 
 	v1 := router.Group("/api")
 	{
-		userHandler := NewUserHandler(h.userService, h.transactionService, h.merchService)
+		userHandler := NewUserHandler(h.userService, h.transactionService, h.merchService, h.allowanceService)
 		v1.POST("/auth", userHandler.Auth)
 		
 		v1.GET("/info", authMiddleware, userHandler.GetInfo)
 
+		merchHandler := NewMerchHandler(h.merchService)
+		v1.GET("/purchases", authMiddleware, merchHandler.GetUserPurchases)
+
+		meGroup := v1.Group("/me")
+		meGroup.Use(authMiddleware)
+		{
+			statementHandler := NewStatementHandler(h.statementService)
+			meGroup.GET("/statement", statementHandler.Export)
+		}
+
 		merchGroup := v1.Group("/merch")
 		{
-			merchHandler := NewMerchHandler(h.merchService)
 			merchGroup.GET("", merchHandler.GetList)
 			merchGroup.GET("/:id", merchHandler.GetByID)
 
@@ -47,7 +84,92 @@ func (h *Handler) Init(router *gin.Engine, tokenSecret string) {
 		transactionGroup.Use(authMiddleware)
 		{
 			transactionHandler := NewTransactionHandler(h.transactionService)
+			transactionGroup.GET("", transactionHandler.GetHistory)
 			transactionGroup.POST("/transfer", transactionHandler.Transfer)
 		}
+
+		cartGroup := v1.Group("/cart")
+		cartGroup.Use(authMiddleware)
+		{
+			cartHandler := NewCartHandler(h.cartService)
+			cartGroup.GET("", cartHandler.Get)
+			cartGroup.POST("/items", cartHandler.AddItem)
+			cartGroup.PUT("/items/:merch_id", cartHandler.UpdateItem)
+			cartGroup.DELETE("/items/:merch_id", cartHandler.RemoveItem)
+			cartGroup.POST("/checkout", cartHandler.Checkout)
+		}
+
+		wishlistGroup := v1.Group("/wishlist")
+		wishlistGroup.Use(authMiddleware)
+		{
+			wishlistHandler := NewWishlistHandler(h.wishlistService)
+			wishlistGroup.GET("", wishlistHandler.List)
+			wishlistGroup.POST("", wishlistHandler.Add)
+			wishlistGroup.DELETE("/:merch_id", wishlistHandler.Remove)
+		}
+
+		notificationGroup := v1.Group("/notifications")
+		notificationGroup.Use(authMiddleware)
+		{
+			notificationHandler := NewNotificationHandler(h.notificationService)
+			notificationGroup.GET("", notificationHandler.List)
+			notificationGroup.GET("/unread-count", notificationHandler.UnreadCount)
+			notificationGroup.POST("/read-all", notificationHandler.MarkAllRead)
+			notificationGroup.POST("/:id/read", notificationHandler.MarkRead)
+		}
+
+		feedGroup := v1.Group("/feed")
+		feedGroup.Use(authMiddleware)
+		{
+			kudosHandler := NewKudosHandler(h.kudosService)
+			feedGroup.GET("", kudosHandler.Feed)
+			feedGroup.GET("/leaderboard", kudosHandler.Leaderboard)
+		}
+
+		eventsHandler := NewEventsHandler(h.events)
+		v1.GET("/events", authMiddleware, eventsHandler.Stream)
+
+		orderHandler := NewOrderHandler(h.orderService)
+
+		orderGroup := v1.Group("/orders")
+		orderGroup.Use(authMiddleware)
+		{
+			orderGroup.GET("", orderHandler.List)
+			orderGroup.GET("/:id", orderHandler.GetByID)
+			orderGroup.PUT("/:id/delivery", orderHandler.UpdateDelivery)
+		}
+
+		transactionHandler := NewTransactionHandler(h.transactionService)
+		webhookHandler := NewWebhookHandler(h.webhookService)
+		auditHandler := NewAuditHandler(h.auditService)
+		allowanceHandler := NewAllowanceHandler(h.allowanceService)
+		analyticsHandler := NewAnalyticsHandler(h.analyticsService)
+
+		adminGroup := v1.Group("/admin")
+		adminGroup.Use(authMiddleware, adminMiddleware)
+		{
+			adminGroup.GET("/orders", orderHandler.AdminList)
+			adminGroup.PUT("/orders/:id/status", orderHandler.AdminUpdateStatus)
+			adminGroup.POST("/merch/:id/image", merchHandler.UploadImage)
+			adminGroup.DELETE("/merch/:id/image", merchHandler.DeleteImage)
+			adminGroup.PUT("/variants/:id/stock", merchHandler.SetVariantStock)
+			adminGroup.POST("/users/:id/adjustments", transactionHandler.AdminAdjust)
+			adminGroup.GET("/grant-schedules", allowanceHandler.ListSchedules)
+			adminGroup.POST("/grant-schedules", allowanceHandler.CreateSchedule)
+			adminGroup.DELETE("/grant-schedules/:id", allowanceHandler.DeactivateSchedule)
+			adminGroup.GET("/allowance-runs", allowanceHandler.Runs)
+			adminGroup.GET("/analytics/top-spenders", analyticsHandler.TopSpenders)
+			adminGroup.GET("/analytics/top-receivers", analyticsHandler.TopReceivers)
+			adminGroup.GET("/analytics/top-merch", analyticsHandler.TopMerch)
+			adminGroup.GET("/analytics/economy", analyticsHandler.Economy)
+			adminGroup.GET("/analytics/daily", analyticsHandler.DailyVolumes)
+			adminGroup.GET("/webhooks", webhookHandler.List)
+			adminGroup.POST("/webhooks", webhookHandler.Create)
+			adminGroup.DELETE("/webhooks/:id", webhookHandler.Delete)
+			adminGroup.GET("/webhooks/:id/deliveries", webhookHandler.Deliveries)
+			adminGroup.POST("/webhook-deliveries/:id/redeliver", webhookHandler.Redeliver)
+			adminGroup.GET("/audit", auditHandler.List)
+			adminGroup.GET("/audit/verify", auditHandler.Verify)
+		}
 	}
 }
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type healthHandler struct {
+	health domain.HealthChecker
+	build  domain.BuildInfo
+}
+
+func NewHealthHandler(health domain.HealthChecker, build domain.BuildInfo) *healthHandler {
+	return &healthHandler{
+		health: health,
+		build:  build,
+	}
+}
+
+// InitProbes регистрирует пробы оркестратора. Они не требуют авторизации
+// и отвечают в простом формате, не завернутом в общий ответ API.
+func (h *Handler) InitProbes(router *gin.Engine, health domain.HealthChecker, build domain.BuildInfo) {
+	healthHandler := NewHealthHandler(health, build)
+	router.GET("/healthz", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
+	router.GET("/version", healthHandler.Version)
+}
+
+// Live отвечает, пока процесс жив, и не проверяет зависимости,
+// чтобы сбой базы не приводил к перезапуску всех реплик
+func (h *healthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready отвечает 503, пока реплика не может обслуживать запросы
+func (h *healthHandler) Ready(c *gin.Context) {
+	report := h.health.Ready(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+func (h *healthHandler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, h.build)
+}
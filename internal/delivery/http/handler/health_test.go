@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeHealthChecker struct {
+	report *domain.HealthReport
+}
+
+func (c fakeHealthChecker) Ready(context.Context) *domain.HealthReport {
+	return c.report
+}
+
+func TestHealthHandlerReady(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		report     *domain.HealthReport
+		wantStatus int
+	}{
+		{
+			name:       "ready",
+			report:     &domain.HealthReport{Ready: true, Checks: map[string]string{"database": "ok", "server": "ok"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "database down",
+			report:     &domain.HealthReport{Checks: map[string]string{"database": "fail", "server": "ok"}},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		// Во время остановки реплика снимает готовность до закрытия сервера
+		{
+			name:       "shutting down",
+			report:     &domain.HealthReport{Checks: map[string]string{"database": "ok", "server": "fail"}},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			(&Handler{}).InitProbes(router, fakeHealthChecker{report: tt.report}, domain.BuildInfo{})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var got domain.HealthReport
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to decode body %s: %v", rec.Body, err)
+			}
+			if !reflect.DeepEqual(&got, tt.report) {
+				t.Errorf("body = %+v, want %+v", got, tt.report)
+			}
+		})
+	}
+}
+
+func TestHealthHandlerLiveIgnoresDependencies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	down := &domain.HealthReport{Checks: map[string]string{"database": "fail"}}
+	(&Handler{}).InitProbes(router, fakeHealthChecker{report: down}, domain.BuildInfo{})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
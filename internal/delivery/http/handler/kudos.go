@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultLeaderboardLimit - размер рейтинга, если limit не указан
+const defaultLeaderboardLimit = 10
+
+type kudosHandler struct {
+	kudosService domain.KudosService
+}
+
+func NewKudosHandler(kudosService domain.KudosService) *kudosHandler {
+	return &kudosHandler{
+		kudosService: kudosService,
+	}
+}
+
+func (h *kudosHandler) Feed(c *gin.Context) {
+	params, err := httpDelivery.NewPaginationParams(c)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	category := domain.KudosCategory(c.Query("category"))
+
+	feed, err := h.kudosService.Feed(c.Request.Context(), category, params)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OKPage(c, "Успешный ответ", feed)
+}
+
+// Leaderboard возвращает рейтинг за текущую неделю, месяц, год или за все время (по умолчанию месяц)
+func (h *kudosHandler) Leaderboard(c *gin.Context) {
+	period := domain.LeaderboardPeriod(c.DefaultQuery("period", string(domain.LeaderboardMonth)))
+	category := domain.KudosCategory(c.Query("category"))
+
+	limit := defaultLeaderboardLimit
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil {
+			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid limit", "invalid_filter")
+			return
+		}
+	}
+
+	entries, err := h.kudosService.Leaderboard(c.Request.Context(), period, category, limit)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", entries)
+}
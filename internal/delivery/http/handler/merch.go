@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -21,21 +23,31 @@ func NewMerchHandler(merchService domain.MerchService) *merchHandler {
 }
 
 type buyMerchInput struct {
-	MerchID  int64 `json:"merch_id" binding:"required"`
-	Quantity int   `json:"quantity" binding:"required,min=1"`
+	MerchID   int64  `json:"merch_id" binding:"required"`
+	VariantID *int64 `json:"variant_id"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
 }
 
 func (h *merchHandler) GetList(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	params, err := httpDelivery.NewPaginationParams(c)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	filter, err := parseMerchFilter(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_filter")
+		return
+	}
 
-	items, err := h.merchService.List(c.Request.Context(), page, pageSize)
+	page, err := h.merchService.List(c.Request.Context(), filter, params)
 	if err != nil {
-		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+		httpDelivery.HandleError(c, err)
 		return
 	}
 
-	httpDelivery.OK(c, "Успешный ответ", items)
+	httpDelivery.OKPage(c, "Успешный ответ", page)
 }
 
 func (h *merchHandler) GetByID(c *gin.Context) {
@@ -51,7 +63,7 @@ func (h *merchHandler) GetByID(c *gin.Context) {
 			httpDelivery.NewErrorResponse(c, http.StatusNotFound, err.Error(), "merch_not_found")
 			return
 		}
-		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+		httpDelivery.HandleError(c, err)
 		return
 	}
 
@@ -71,7 +83,7 @@ func (h *merchHandler) Buy(c *gin.Context) {
 		return
 	}
 
-	err = h.merchService.Buy(c.Request.Context(), userID, input.MerchID, input.Quantity)
+	_, err = h.merchService.Buy(c.Request.Context(), userID, input.MerchID, input.VariantID, input.Quantity)
 	if err != nil {
 		switch err {
 		case domain.ErrMerchNotFound:
@@ -80,8 +92,14 @@ func (h *merchHandler) Buy(c *gin.Context) {
 			httpDelivery.NewErrorResponse(c, http.StatusPaymentRequired, err.Error(), "insufficient_funds")
 		case domain.ErrInvalidQuantity:
 			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_quantity")
+		case domain.ErrVariantNotFound:
+			httpDelivery.NewErrorResponse(c, http.StatusNotFound, err.Error(), "variant_not_found")
+		case domain.ErrVariantRequired:
+			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "variant_required")
+		case domain.ErrOutOfStock:
+			httpDelivery.NewErrorResponse(c, http.StatusConflict, err.Error(), "out_of_stock")
 		default:
-			httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+			httpDelivery.HandleError(c, err)
 		}
 		return
 	}
@@ -96,15 +114,136 @@ func (h *merchHandler) GetUserPurchases(c *gin.Context) {
 		return
 	}
 
-	purchases, err := h.merchService.GetUserPurchases(c.Request.Context(), userID)
+	params, err := httpDelivery.NewPaginationParams(c)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	purchases, err := h.merchService.GetUserPurchases(c.Request.Context(), userID, params)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OKPage(c, "Успешный ответ", purchases)
+}
+
+func (h *merchHandler) UploadImage(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	// Читаем multipart потоком, чтобы не складывать файл во временный каталог
+	reader, err := c.Request.MultipartReader()
 	if err != nil {
-		if err == domain.ErrUserNotFound {
-			httpDelivery.NewErrorResponse(c, http.StatusNotFound, err.Error(), "user_not_found")
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "multipart/form-data expected", "invalid_input")
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "image field is required", "invalid_input")
 			return
 		}
-		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+		if err != nil {
+			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid multipart body", "invalid_input")
+			return
+		}
+
+		if part.FormName() != "image" {
+			_ = part.Close()
+			continue
+		}
+
+		merch, err := h.merchService.UploadImage(c.Request.Context(), id, part)
+		_ = part.Close()
+		if err != nil {
+			httpDelivery.HandleError(c, err)
+			return
+		}
+
+		httpDelivery.OK(c, "image uploaded", merch)
+		return
+	}
+}
+
+func (h *merchHandler) DeleteImage(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	if err := h.merchService.DeleteImage(c.Request.Context(), id); err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "image deleted", nil)
+}
+
+// parseMerchFilter читает параметры поиска по каталогу из query string
+func parseMerchFilter(c *gin.Context) (domain.MerchFilter, error) {
+	filter := domain.MerchFilter{
+		Category: c.Query("category"),
+		Tag:      c.Query("tag"),
+		Query:    c.Query("q"),
+		Sort:     domain.MerchSort(c.Query("sort")),
+	}
+
+	if raw := c.Query("min_price"); raw != "" {
+		minPrice, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || minPrice < 0 {
+			return filter, errors.New("invalid min_price")
+		}
+		filter.MinPrice = &minPrice
+	}
+
+	if raw := c.Query("max_price"); raw != "" {
+		maxPrice, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || maxPrice < 0 {
+			return filter, errors.New("invalid max_price")
+		}
+		filter.MaxPrice = &maxPrice
+	}
+
+	if raw := c.Query("in_stock"); raw != "" {
+		inStock, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, errors.New("invalid in_stock")
+		}
+		filter.InStock = inStock
+	}
+
+	return filter, nil
+}
+
+type setVariantStockInput struct {
+	Stock *int `json:"stock" binding:"required,min=0"`
+}
+
+func (h *merchHandler) SetVariantStock(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	var input setVariantStockInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	variant, err := h.merchService.SetVariantStock(c.Request.Context(), id, *input.Stock)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
 		return
 	}
 
-	httpDelivery.OK(c, "Успешный ответ", purchases)
+	httpDelivery.OK(c, "variant stock updated", variant)
 }
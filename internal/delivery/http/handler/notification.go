@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/avito/internal/delivery/http/middleware"
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type notificationHandler struct {
+	notificationService domain.NotificationService
+}
+
+func NewNotificationHandler(notificationService domain.NotificationService) *notificationHandler {
+	return &notificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+type unreadCountResponse struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type markAllReadResponse struct {
+	Marked int64 `json:"marked"`
+}
+
+func (h *notificationHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	params, err := httpDelivery.NewPaginationParams(c)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	unreadOnly := false
+	if raw := c.Query("unread"); raw != "" {
+		unreadOnly, err = strconv.ParseBool(raw)
+		if err != nil {
+			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid unread flag", "invalid_filter")
+			return
+		}
+	}
+
+	notifications, err := h.notificationService.List(c.Request.Context(), userID, unreadOnly, params)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.SetPageLinks(c, notifications.Page)
+	httpDelivery.OK(c, "Успешный ответ", notifications)
+}
+
+func (h *notificationHandler) UnreadCount(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	count, err := h.notificationService.UnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", unreadCountResponse{UnreadCount: count})
+}
+
+func (h *notificationHandler) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	if err := h.notificationService.MarkRead(c.Request.Context(), userID, id); err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "notification marked as read", nil)
+}
+
+func (h *notificationHandler) MarkAllRead(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	marked, err := h.notificationService.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "notifications marked as read", markAllReadResponse{Marked: marked})
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/avito/internal/delivery/http/middleware"
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type orderHandler struct {
+	orderService domain.OrderService
+}
+
+func NewOrderHandler(orderService domain.OrderService) *orderHandler {
+	return &orderHandler{
+		orderService: orderService,
+	}
+}
+
+type deliveryInput struct {
+	DeliveryMethod string  `json:"delivery_method" binding:"required,oneof=office pickup"`
+	OfficeLocation *string `json:"office_location"`
+	PickupPoint    *string `json:"pickup_point"`
+}
+
+func (i deliveryInput) toDomain() domain.DeliveryDetails {
+	return domain.DeliveryDetails{
+		Method:         domain.DeliveryMethod(i.DeliveryMethod),
+		OfficeLocation: i.OfficeLocation,
+		PickupPoint:    i.PickupPoint,
+	}
+}
+
+type updateOrderStatusInput struct {
+	Status string `json:"status" binding:"required"`
+}
+
+func (h *orderHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	orders, err := h.orderService.List(c.Request.Context(), userID)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", orders)
+}
+
+func (h *orderHandler) GetByID(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	order, err := h.orderService.GetByID(c.Request.Context(), userID, orderID)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", order)
+}
+
+func (h *orderHandler) UpdateDelivery(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	var input deliveryInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	if err := h.orderService.UpdateDelivery(c.Request.Context(), userID, orderID, input.toDomain()); err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "delivery updated", nil)
+}
+
+func (h *orderHandler) AdminList(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	status := domain.OrderStatus(c.Query("status"))
+
+	orders, err := h.orderService.ListAll(c.Request.Context(), status, page, pageSize)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", orders)
+}
+
+func (h *orderHandler) AdminUpdateStatus(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	var input updateOrderStatusInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	order, err := h.orderService.UpdateStatus(c.Request.Context(), orderID, domain.OrderStatus(input.Status))
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "order status updated", order)
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/avito/internal/delivery/http/middleware"
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type statementHandler struct {
+	statementService domain.StatementService
+}
+
+func NewStatementHandler(statementService domain.StatementService) *statementHandler {
+	return &statementHandler{
+		statementService: statementService,
+	}
+}
+
+func (h *statementHandler) Export(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	var from, to time.Time
+	if raw := c.Query("from"); raw != "" {
+		if from, err = parseDate(raw); err != nil {
+			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid from", "invalid_filter")
+			return
+		}
+	}
+	if raw := c.Query("to"); raw != "" {
+		if to, err = parseDate(raw); err != nil {
+			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid to", "invalid_filter")
+			return
+		}
+	}
+
+	var w statementWriter
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		w = &jsonStatementWriter{c: c}
+	case "csv":
+		w = &csvStatementWriter{c: c}
+	default:
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "format must be csv or json", "invalid_format")
+		return
+	}
+
+	disableWriteTimeout(c)
+
+	err = h.statementService.Export(c.Request.Context(), userID, from, to, w)
+	if err != nil {
+		// После начала выгрузки статус уже отправлен, остается только оборвать ответ
+		if w.started() {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		httpDelivery.HandleError(c, err)
+	}
+}
+
+type statementWriter interface {
+	domain.StatementWriter
+	started() bool
+}
+
+// writeStatementHeaders отправляет заголовки выгрузки, после чего ответ уже нельзя заменить ошибкой
+func writeStatementHeaders(c *gin.Context, contentType, ext string, from, to time.Time) {
+	filename := fmt.Sprintf("statement-%s-%s.%s", from.Format(time.DateOnly), to.Format(time.DateOnly), ext)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+}
+
+// csvStatementWriter пишет выписку в CSV, входящий и исходящий остатки идут отдельными строками
+type csvStatementWriter struct {
+	c  *gin.Context
+	w  *csv.Writer
+	to time.Time
+	ok bool
+}
+
+func (w *csvStatementWriter) started() bool {
+	return w.ok
+}
+
+func (w *csvStatementWriter) Begin(from, to time.Time, openingBalance int64) error {
+	writeStatementHeaders(w.c, "text/csv; charset=utf-8", "csv", from, to)
+	w.ok = true
+	w.to = to
+	w.w = csv.NewWriter(w.c.Writer)
+
+	if err := w.w.Write([]string{"date", "type", "reference_id", "amount", "balance", "counterparty", "description"}); err != nil {
+		return err
+	}
+	return w.write([]string{from.Format(time.RFC3339), "opening_balance", "", "", strconv.FormatInt(openingBalance, 10), "", ""})
+}
+
+func (w *csvStatementWriter) Entry(entry *domain.StatementEntry) error {
+	return w.write([]string{
+		entry.CreatedAt.Format(time.RFC3339),
+		string(entry.Type),
+		strconv.FormatInt(entry.ReferenceID, 10),
+		strconv.FormatInt(entry.Amount, 10),
+		strconv.FormatInt(entry.Balance, 10),
+		stringOrEmpty(entry.Counterparty),
+		stringOrEmpty(entry.Description),
+	})
+}
+
+func (w *csvStatementWriter) End(closingBalance int64) error {
+	return w.write([]string{w.to.Format(time.RFC3339), "closing_balance", "", "", strconv.FormatInt(closingBalance, 10), "", ""})
+}
+
+func (w *csvStatementWriter) write(record []string) error {
+	if err := w.w.Write(record); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// jsonStatementWriter пишет выписку одним JSON-объектом, элементы entries кодируются по одному
+type jsonStatementWriter struct {
+	c     *gin.Context
+	enc   *json.Encoder
+	ok    bool
+	count int
+}
+
+func (w *jsonStatementWriter) started() bool {
+	return w.ok
+}
+
+func (w *jsonStatementWriter) Begin(from, to time.Time, openingBalance int64) error {
+	writeStatementHeaders(w.c, "application/json; charset=utf-8", "json", from, to)
+	w.ok = true
+	w.enc = json.NewEncoder(w.c.Writer)
+
+	_, err := fmt.Fprintf(w.c.Writer, `{"from":%q,"to":%q,"opening_balance":%d,"entries":[`,
+		from.Format(time.RFC3339), to.Format(time.RFC3339), openingBalance)
+	return err
+}
+
+func (w *jsonStatementWriter) Entry(entry *domain.StatementEntry) error {
+	if w.count > 0 {
+		if _, err := w.c.Writer.WriteString(","); err != nil {
+			return err
+		}
+	}
+	w.count++
+	return w.enc.Encode(entry)
+}
+
+func (w *jsonStatementWriter) End(closingBalance int64) error {
+	_, err := fmt.Fprintf(w.c.Writer, `],"closing_balance":%d}`, closingBalance)
+	return err
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
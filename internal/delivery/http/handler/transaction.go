@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	httpDelivery "github.com/avito/internal/delivery/http"
 	"github.com/avito/internal/delivery/http/middleware"
@@ -23,6 +26,9 @@ type transferInput struct {
 	ToUserID    int64  `json:"to_user_id" binding:"required"`
 	Amount      int64  `json:"amount" binding:"required,min=1"`
 	Description string `json:"description"`
+	// Public публикует перевод в ленте благодарностей вместе с описанием
+	Public   bool   `json:"public"`
+	Category string `json:"category"`
 }
 
 func (h *transactionHandler) Transfer(c *gin.Context) {
@@ -43,7 +49,8 @@ func (h *transactionHandler) Transfer(c *gin.Context) {
 		return
 	}
 
-	err = h.transactionService.Transfer(c.Request.Context(), fromUserID, input.ToUserID, input.Amount, input.Description)
+	kudos := domain.KudosOptions{Public: input.Public, Category: domain.KudosCategory(input.Category)}
+	_, err = h.transactionService.Transfer(c.Request.Context(), fromUserID, input.ToUserID, input.Amount, input.Description, kudos)
 	if err != nil {
 		switch err {
 		case domain.ErrUserNotFound:
@@ -52,8 +59,10 @@ func (h *transactionHandler) Transfer(c *gin.Context) {
 			httpDelivery.NewErrorResponse(c, http.StatusPaymentRequired, err.Error(), "insufficient_funds")
 		case domain.ErrInvalidAmount:
 			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_amount")
+		case domain.ErrInvalidKudos:
+			httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_kudos")
 		default:
-			httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+			httpDelivery.HandleError(c, err)
 		}
 		return
 	}
@@ -61,6 +70,48 @@ func (h *transactionHandler) Transfer(c *gin.Context) {
 	httpDelivery.OK(c, "transfer successful", nil)
 }
 
+type adjustmentInput struct {
+	Direction  string `json:"direction" binding:"required,oneof=credit debit"`
+	Amount     int64  `json:"amount" binding:"required,min=1"`
+	ReasonCode string `json:"reason_code" binding:"required"`
+	Comment    string `json:"comment" binding:"required"`
+}
+
+// AdminAdjust начисляет или списывает монеты пользователю через казначейство
+func (h *transactionHandler) AdminAdjust(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	var input adjustmentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	adminID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	transaction, err := h.transactionService.Adjust(c.Request.Context(), adminID, domain.BalanceAdjustment{
+		UserID:     userID,
+		Direction:  domain.AdjustmentDirection(input.Direction),
+		Amount:     input.Amount,
+		ReasonCode: domain.AdjustmentReason(input.ReasonCode),
+		Comment:    input.Comment,
+	})
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.Created(c, "balance adjusted", transaction)
+}
+
 func (h *transactionHandler) GetHistory(c *gin.Context) {
 	userID, err := middleware.GetUserID(c)
 	if err != nil {
@@ -68,15 +119,78 @@ func (h *transactionHandler) GetHistory(c *gin.Context) {
 		return
 	}
 
-	transactions, err := h.transactionService.GetUserTransactions(c.Request.Context(), userID)
+	params, err := httpDelivery.NewPaginationParams(c)
 	if err != nil {
-		if err == domain.ErrUserNotFound {
-			httpDelivery.NewErrorResponse(c, http.StatusNotFound, err.Error(), "user_not_found")
-			return
-		}
-		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+		httpDelivery.HandleError(c, err)
 		return
 	}
 
-	httpDelivery.OK(c, "Успешный ответ", transactions)
+	filter, err := parseTransactionFilter(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_filter")
+		return
+	}
+
+	transactions, err := h.transactionService.GetUserTransactions(c.Request.Context(), userID, filter, params)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OKPage(c, "Успешный ответ", transactions)
+}
+
+// parseTransactionFilter читает параметры поиска по истории переводов из query string.
+// Даты принимаются в формате RFC 3339 или YYYY-MM-DD, граница to не включается.
+func parseTransactionFilter(c *gin.Context) (domain.TransactionFilter, error) {
+	filter := domain.TransactionFilter{
+		Direction:    domain.TransactionDirection(c.Query("direction")),
+		Counterparty: c.Query("counterparty"),
+		Description:  c.Query("q"),
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **int64
+	}{
+		{"min_amount", &filter.MinAmount},
+		{"max_amount", &filter.MaxAmount},
+	} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		amount, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || amount < 0 {
+			return filter, fmt.Errorf("invalid %s", p.name)
+		}
+		*p.dst = &amount
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	} {
+		raw := c.Query(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := parseDate(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid %s", p.name)
+		}
+		*p.dst = &t
+	}
+
+	return filter, nil
+}
+
+func parseDate(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, raw)
 }
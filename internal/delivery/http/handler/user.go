@@ -2,10 +2,12 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	httpDelivery "github.com/avito/internal/delivery/http"
 	"github.com/avito/internal/delivery/http/middleware"
 	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,17 +15,20 @@ type userHandler struct {
 	userService        domain.UserService
 	transactionService domain.TransactionService
 	merchService       domain.MerchService
+	allowanceService   domain.AllowanceService
 }
 
 func NewUserHandler(
 	userService domain.UserService,
 	transactionService domain.TransactionService,
 	merchService domain.MerchService,
+	allowanceService domain.AllowanceService,
 ) *userHandler {
 	return &userHandler{
 		userService:        userService,
 		transactionService: transactionService,
 		merchService:       merchService,
+		allowanceService:   allowanceService,
 	}
 }
 
@@ -55,7 +60,7 @@ func (h *userHandler) SignUp(c *gin.Context) {
 			httpDelivery.NewErrorResponse(c, http.StatusConflict, err.Error(), "user_exists")
 			return
 		}
-		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+		httpDelivery.HandleError(c, err)
 		return
 	}
 
@@ -79,7 +84,7 @@ func (h *userHandler) SignIn(c *gin.Context) {
 			httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "invalid_credentials")
 			return
 		}
-		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+		httpDelivery.HandleError(c, err)
 		return
 	}
 
@@ -101,7 +106,7 @@ func (h *userHandler) GetBalance(c *gin.Context) {
 			httpDelivery.NewErrorResponse(c, http.StatusNotFound, err.Error(), "user_not_found")
 			return
 		}
-		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+		httpDelivery.HandleError(c, err)
 		return
 	}
 
@@ -123,7 +128,7 @@ func (h *userHandler) Auth(c *gin.Context) {
 			httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "invalid_credentials")
 			return
 		}
-		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+		httpDelivery.HandleError(c, err)
 		return
 	}
 
@@ -145,30 +150,42 @@ func (h *userHandler) GetInfo(c *gin.Context) {
 			httpDelivery.NewErrorResponse(c, http.StatusNotFound, err.Error(), "user_not_found")
 			return
 		}
-		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+		httpDelivery.HandleError(c, err)
 		return
 	}
 
-	transactions, err := h.transactionService.GetUserTransactions(c.Request.Context(), userID)
+	// В сводке отдается первая страница истории, остальное доступно через курсоры
+	firstPage := pagination.Params{Limit: pagination.DefaultLimit}
+
+	transactions, err := h.transactionService.GetUserTransactions(c.Request.Context(), userID, domain.TransactionFilter{}, firstPage)
 	if err != nil {
 		if err == domain.ErrUserNotFound {
 			httpDelivery.NewErrorResponse(c, http.StatusNotFound, err.Error(), "user_not_found")
 			return
 		}
-		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	purchases, err := h.merchService.GetUserPurchases(c.Request.Context(), userID, firstPage)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
 		return
 	}
 
-	purchases, err := h.merchService.GetUserPurchases(c.Request.Context(), userID)
+	allowance, err := h.allowanceService.Outlook(c.Request.Context(), userID, time.Now())
 	if err != nil {
-		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, err.Error(), "internal_error")
+		httpDelivery.HandleError(c, err)
 		return
 	}
 
 	response := &domain.UserInfoResponse{
-		Balance:      balance,
-		Transactions: transactions,
-		Purchases:    purchases,
+		Balance:                balance,
+		Transactions:           transactions.Items,
+		TransactionsNextCursor: transactions.NextCursor,
+		Purchases:              purchases.Items,
+		PurchasesNextCursor:    purchases.NextCursor,
+		Allowance:              allowance,
 	}
 
 	httpDelivery.OK(c, "Успешный ответ", response)
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type webhookHandler struct {
+	webhookService domain.WebhookService
+}
+
+func NewWebhookHandler(webhookService domain.WebhookService) *webhookHandler {
+	return &webhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+type createWebhookInput struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"event_types" binding:"required,min=1"`
+	// Secret необязателен: если не передан, будет сгенерирован
+	Secret string `json:"secret"`
+}
+
+func (h *webhookHandler) Create(c *gin.Context) {
+	var input createWebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	subscription, err := h.webhookService.Create(c.Request.Context(), input.URL, input.EventTypes, input.Secret)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.Created(c, "webhook created", subscription)
+}
+
+func (h *webhookHandler) List(c *gin.Context) {
+	subscriptions, err := h.webhookService.List(c.Request.Context())
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", subscriptions)
+}
+
+func (h *webhookHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	if err := h.webhookService.Delete(c.Request.Context(), id); err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "webhook deleted", nil)
+}
+
+func (h *webhookHandler) Deliveries(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	params, err := httpDelivery.NewPaginationParams(c)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	status := domain.WebhookDeliveryStatus(c.Query("status"))
+
+	deliveries, err := h.webhookService.Deliveries(c.Request.Context(), id, status, params)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OKPage(c, "Успешный ответ", deliveries)
+}
+
+func (h *webhookHandler) Redeliver(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, "invalid id", "invalid_input")
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), id)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "webhook delivery scheduled", delivery)
+}
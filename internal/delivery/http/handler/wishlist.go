@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/avito/internal/delivery/http/middleware"
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type wishlistHandler struct {
+	wishlistService domain.WishlistService
+}
+
+func NewWishlistHandler(wishlistService domain.WishlistService) *wishlistHandler {
+	return &wishlistHandler{
+		wishlistService: wishlistService,
+	}
+}
+
+type addWishlistItemInput struct {
+	MerchID   int64  `json:"merch_id" binding:"required"`
+	VariantID *int64 `json:"variant_id"`
+}
+
+func (h *wishlistHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	items, err := h.wishlistService.List(c.Request.Context(), userID)
+	if err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "Успешный ответ", items)
+}
+
+func (h *wishlistHandler) Add(c *gin.Context) {
+	var input addWishlistItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	if err := h.wishlistService.Add(c.Request.Context(), userID, input.MerchID, input.VariantID); err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "item added to wishlist", nil)
+}
+
+func (h *wishlistHandler) Remove(c *gin.Context) {
+	merchID, variantID, err := parseCartItemKey(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusBadRequest, err.Error(), "invalid_input")
+		return
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		httpDelivery.NewErrorResponse(c, http.StatusUnauthorized, err.Error(), "unauthorized")
+		return
+	}
+
+	if err := h.wishlistService.Remove(c.Request.Context(), userID, merchID, variantID); err != nil {
+		httpDelivery.HandleError(c, err)
+		return
+	}
+
+	httpDelivery.OK(c, "wishlist item removed", nil)
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -12,6 +13,7 @@ import (
 const (
 	authorizationHeader = "Authorization"
 	userCtx             = "userId"
+	adminCtx            = "isAdmin"
 )
 
 func AuthMiddleware(tokenSecret string) gin.HandlerFunc {
@@ -21,6 +23,7 @@ func AuthMiddleware(tokenSecret string) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"message": "empty auth header",
 				"description":    "unauthorized",
+				"request_id": requestID(c),
 			})
 			return
 		}
@@ -30,6 +33,7 @@ func AuthMiddleware(tokenSecret string) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"message": "invalid auth header",
 				"description":    "unauthorized",
+				"request_id": requestID(c),
 			})
 			return
 		}
@@ -38,6 +42,7 @@ func AuthMiddleware(tokenSecret string) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"message": "token is empty",
 				"description":    "unauthorized",
+				"request_id": requestID(c),
 			})
 			return
 		}
@@ -53,6 +58,7 @@ func AuthMiddleware(tokenSecret string) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"message": "invalid token",
 				"description":    "unauthorized",
+				"request_id": requestID(c),
 			})
 			return
 		}
@@ -62,6 +68,7 @@ func AuthMiddleware(tokenSecret string) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"message": "invalid token claims",
 				"description":    "unauthorized",
+				"request_id": requestID(c),
 			})
 			return
 		}
@@ -71,15 +78,66 @@ func AuthMiddleware(tokenSecret string) gin.HandlerFunc {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"message": "invalid user id",
 				"description":    "unauthorized",
+				"request_id": requestID(c),
 			})
 			return
 		}
 
+		isAdmin, _ := claims["is_admin"].(bool)
+
 		c.Set(userCtx, int64(userID))
+		c.Set(adminCtx, isAdmin)
+		c.Request = c.Request.WithContext(domain.WithActor(c.Request.Context(), int64(userID)))
+		c.Next()
+	}
+}
+
+// AdminMiddleware пропускает только администраторов, должен идти после AuthMiddleware.
+// Признак is_admin перечитывается из базы: токен живет до истечения срока,
+// а отозванные права должны перестать действовать сразу.
+func AdminMiddleware(users domain.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := GetUserID(c)
+		if err == nil {
+			var user *domain.User
+			user, err = users.GetByID(c.Request.Context(), userID)
+			switch {
+			case errors.Is(err, domain.ErrUserNotFound):
+				// Пользователь из токена удален
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"message":     "user not found",
+					"description": "unauthorized",
+				})
+				return
+			case err != nil:
+				_ = c.Error(err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"message":     "failed to check admin access",
+					"description": "internal error",
+				})
+				return
+			}
+			c.Set(adminCtx, user.IsAdmin)
+		}
+
+		if !IsAdmin(c) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"message":     "admin access required",
+				"description": "forbidden",
+				"request_id":  requestID(c),
+			})
+			return
+		}
 		c.Next()
 	}
 }
 
+// IsAdmin сообщает, является ли текущий пользователь администратором. До AdminMiddleware
+// значение берется из токена и может устареть до истечения его срока.
+func IsAdmin(c *gin.Context) bool {
+	return c.GetBool(adminCtx)
+}
+
 // GetUserID получает ID пользователя из контекста
 func GetUserID(c *gin.Context) (int64, error) {
 	id, ok := c.Get(userCtx)
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type fakeUserService struct {
+	domain.UserService
+	user *domain.User
+	err  error
+}
+
+func (s *fakeUserService) GetByID(context.Context, int64) (*domain.User, error) {
+	return s.user, s.err
+}
+
+func TestAdminMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		tokenAdmin bool
+		user       *domain.User
+		err        error
+		wantStatus int
+	}{
+		{name: "admin", tokenAdmin: true, user: &domain.User{ID: 1, IsAdmin: true}, wantStatus: http.StatusOK},
+		{name: "granted after login", user: &domain.User{ID: 1, IsAdmin: true}, wantStatus: http.StatusOK},
+		{name: "revoked after login", tokenAdmin: true, user: &domain.User{ID: 1}, wantStatus: http.StatusForbidden},
+		{name: "deleted user", tokenAdmin: true, err: domain.ErrUserNotFound, wantStatus: http.StatusUnauthorized},
+		{name: "database error", tokenAdmin: true, err: errors.New("connection reset"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/admin",
+				func(c *gin.Context) {
+					c.Set(userCtx, int64(1))
+					c.Set(adminCtx, tt.tokenAdmin)
+				},
+				AdminMiddleware(&fakeUserService{user: tt.user, err: tt.err}),
+				func(c *gin.Context) { c.Status(http.StatusOK) },
+			)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestAdminMiddlewareWithoutAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/admin", AdminMiddleware(&fakeUserService{}), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	httpDelivery "github.com/avito/internal/delivery/http"
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog пишет строку журнала на каждый запрос, кроме путей skipPaths.
+// Внутренние ошибки, скрытые от клиента, попадают сюда через c.Error вместе с id запроса из контекста.
+func AccessLog(logger *slog.Logger, skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"bytes", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+		}
+		if len(c.Errors) > 0 {
+			errs := make([]error, 0, len(c.Errors))
+			for _, err := range c.Errors {
+				errs = append(errs, err.Err)
+			}
+			attrs = append(attrs, "error", errors.Join(errs...))
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		logger.Log(c.Request.Context(), level, "http request", attrs...)
+	}
+}
+
+// Recovery перехватывает панику обработчика, пишет ее в журнал со стеком
+// и отвечает клиенту 500 без подробностей
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		logger.ErrorContext(c.Request.Context(), "panic recovered",
+			"panic", recovered,
+			"stack", string(debug.Stack()),
+		)
+		httpDelivery.NewErrorResponse(c, http.StatusInternalServerError, "internal server error", "internal_error")
+	})
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute - метка для запросов к несуществующим маршрутам, чтобы не плодить серии по путям
+const unmatchedRoute = "unmatched"
+
+// Metrics учитывает количество и длительность запросов по шаблону маршрута и статусу
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.ObserveHTTP(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
+// CountAuthFailures учитывает запросы, отклоненные auth: пользователь не установлен, а цепочка прервана
+func CountAuthFailures(auth gin.HandlerFunc, m domain.BusinessMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		auth(c)
+
+		if _, authenticated := c.Get(userCtx); !authenticated && c.IsAborted() {
+			m.AuthFailed("invalid_token")
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/avito/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+const requestIDHeader = "X-Request-ID"
+
+// RequestMeta сохраняет в контексте запроса его id, IP и user agent для журнала аудита.
+// Переданный клиентом X-Request-ID сохраняется, иначе генерируется новый.
+func RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = newRequestID()
+		}
+		c.Header(requestIDHeader, requestID)
+
+		ctx := domain.WithRequestMeta(c.Request.Context(), domain.RequestMeta{
+			RequestID: requestID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// requestID возвращает id текущего запроса для тел ответов с ошибкой
+func requestID(c *gin.Context) string {
+	return domain.RequestMetaFrom(c.Request.Context()).RequestID
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Tracing начинает спан на каждый запрос, кроме путей skipPaths, продолжая трассу из заголовка traceparent.
+// Спан кладется в контекст запроса, откуда его подхватывают сервисы и запросы к базе.
+func Tracing(serviceName string, skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return otelgin.Middleware(serviceName,
+		otelgin.WithFilter(func(r *http.Request) bool {
+			return !skip[r.URL.Path]
+		}),
+	)
+}
@@ -1,6 +1,9 @@
 package http
 
 import (
+	"fmt"
+
+	"github.com/avito/pkg/pagination"
 	"github.com/gin-gonic/gin"
 )
 
@@ -33,6 +36,34 @@ func OK(c *gin.Context, message string, data interface{}) {
 	NewResponse(c, 200, message, data)
 }
 
+// OKPage отправляет страницу результатов и ссылку на следующую страницу
+// в поле links и в заголовке Link
+func OKPage[T any](c *gin.Context, message string, page *pagination.Page[T]) {
+	SetPageLinks(c, page)
+	OK(c, message, page)
+}
+
+// SetPageLinks заполняет ссылку на следующую страницу для ответов,
+// которые оборачивают страницу в собственную структуру
+func SetPageLinks[T any](c *gin.Context, page *pagination.Page[T]) {
+	if page.NextCursor == "" {
+		return
+	}
+
+	next := *c.Request.URL
+	query := next.Query()
+	query.Set("cursor", page.NextCursor)
+	next.RawQuery = query.Encode()
+
+	page.Links = &pagination.Links{Next: next.RequestURI()}
+	c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, page.Links.Next))
+}
+
+// NewPaginationParams читает page_size и cursor из запроса
+func NewPaginationParams(c *gin.Context) (pagination.Params, error) {
+	return pagination.NewParams(c.Query("page_size"), c.Query("cursor"))
+}
+
 type errorResponse struct {
 	Message string `json:"message"`
 	Code    string `json:"code"`
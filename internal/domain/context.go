@@ -0,0 +1,31 @@
+package domain
+
+import "context"
+
+// RequestMeta описывает источник запроса: кто и откуда выполняет действие
+type RequestMeta struct {
+	RequestID string
+	IP        string
+	UserAgent string
+	ActorID   *int64
+}
+
+type requestMetaKey struct{}
+
+// WithRequestMeta сохраняет сведения о запросе в контексте
+func WithRequestMeta(ctx context.Context, meta RequestMeta) context.Context {
+	return context.WithValue(ctx, requestMetaKey{}, meta)
+}
+
+// RequestMetaFrom возвращает сведения о запросе или пустую структуру для фоновых операций
+func RequestMetaFrom(ctx context.Context) RequestMeta {
+	meta, _ := ctx.Value(requestMetaKey{}).(RequestMeta)
+	return meta
+}
+
+// WithActor дополняет сведения о запросе пользователем, выполняющим действие
+func WithActor(ctx context.Context, userID int64) context.Context {
+	meta := RequestMetaFrom(ctx)
+	meta.ActorID = &userID
+	return WithRequestMeta(ctx, meta)
+}
@@ -28,5 +28,68 @@ var (
 
 	// ErrTransactionFailed возвращается при ошибке проведения транзакции
 	ErrTransactionFailed = errors.New("transaction failed")
+
+	// ErrCartEmpty возвращается при попытке оформить пустую корзину
+	ErrCartEmpty = errors.New("cart is empty")
+
+	// ErrCartItemNotFound возвращается, когда позиции нет в корзине
+	ErrCartItemNotFound = errors.New("cart item not found")
+
+	// ErrOrderNotFound возвращается, когда заказ не найден
+	ErrOrderNotFound = errors.New("order not found")
+
+	// ErrInvalidOrderStatus возвращается при недопустимой смене статуса заказа
+	ErrInvalidOrderStatus = errors.New("invalid order status transition")
+
+	// ErrInvalidDelivery возвращается при некорректных данных доставки
+	ErrInvalidDelivery = errors.New("invalid delivery details")
+
+	// ErrVariantNotFound возвращается, когда вариант товара не найден
+	ErrVariantNotFound = errors.New("merch variant not found")
+
+	// ErrVariantRequired возвращается, если у товара есть варианты, а вариант не выбран
+	ErrVariantRequired = errors.New("merch variant required")
+
+	// ErrOutOfStock возвращается, когда остатка варианта недостаточно
+	ErrOutOfStock = errors.New("out of stock")
+
+	// ErrInvalidFilter возвращается при некорректных параметрах поиска
+	ErrInvalidFilter = errors.New("invalid filter")
+
+	// ErrInvalidImage возвращается при неподдерживаемом или поврежденном изображении
+	ErrInvalidImage = errors.New("invalid image")
+
+	// ErrImageTooLarge возвращается, если изображение превышает допустимый размер
+	ErrImageTooLarge = errors.New("image too large")
+
+	// ErrWishlistItemNotFound возвращается, когда товара нет в списке желаний
+	ErrWishlistItemNotFound = errors.New("wishlist item not found")
+
+	// ErrNotificationNotFound возвращается, когда уведомление не найдено
+	ErrNotificationNotFound = errors.New("notification not found")
+
+	// ErrWebhookNotFound возвращается, когда подписка на вебхуки не найдена
+	ErrWebhookNotFound = errors.New("webhook not found")
+
+	// ErrWebhookDeliveryNotFound возвращается, когда доставка вебхука не найдена
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+	// ErrInvalidWebhook возвращается при некорректном URL или типах событий подписки
+	ErrInvalidWebhook = errors.New("invalid webhook")
+
+	// ErrInvalidAdjustment возвращается при некорректном направлении, коде причины или пустом комментарии
+	ErrInvalidAdjustment = errors.New("invalid balance adjustment")
+
+	// ErrGrantScheduleNotFound возвращается, когда расписание начислений не найдено
+	ErrGrantScheduleNotFound = errors.New("grant schedule not found")
+
+	// ErrInvalidGrantSchedule возвращается при некорректной сумме или дне начисления
+	ErrInvalidGrantSchedule = errors.New("invalid grant schedule")
+
+	// ErrInvalidKudos возвращается при неизвестной категории благодарности
+	ErrInvalidKudos = errors.New("invalid kudos category")
+
+	// ErrForbidden возвращается при недостаточных правах доступа
+	ErrForbidden = errors.New("forbidden")
 )
 
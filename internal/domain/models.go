@@ -1,7 +1,14 @@
 package domain
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
 	"time"
+
+	"github.com/avito/pkg/pagination"
 )
 
 // User представляет пользователя системы
@@ -10,10 +17,15 @@ type User struct {
 	Username  string    `json:"username" db:"username"`
 	Password  string    `json:"-" db:"password_hash"`
 	Balance   int64     `json:"balance" db:"balance"`
+	IsAdmin   bool      `json:"is_admin" db:"is_admin"`
+	IsSystem  bool      `json:"-" db:"is_system"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// TreasuryUsername - системный счет, выпускающий и принимающий монеты при начислениях, списаниях и возвратах
+const TreasuryUsername = "treasury"
+
 // Merch представляет товар в магазине
 type Merch struct {
 	ID          int64     `json:"id" db:"id"`
@@ -21,43 +33,813 @@ type Merch struct {
 	Description string    `json:"description" db:"description"`
 	Price       int64     `json:"price" db:"price"`
 	Quantity    int       `json:"quantity" db:"quantity"`
+	Category    *string   `json:"category,omitempty" db:"category"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+
+	ImageKey     *string `json:"-" db:"image_key"`
+	ThumbnailKey *string `json:"-" db:"thumbnail_key"`
+	ImageURL     string  `json:"image_url,omitempty" db:"-"`
+	ThumbnailURL string  `json:"thumbnail_url,omitempty" db:"-"`
+
+	Tags     []string        `json:"tags" db:"-"`
+	Variants []*MerchVariant `json:"variants,omitempty" db:"-"`
 }
 
-// Purchase представляет покупку мерча пользователем
-type Purchase struct {
+// MerchSort задает порядок сортировки каталога
+type MerchSort string
+
+const (
+	MerchSortDefault    MerchSort = ""
+	MerchSortPriceAsc   MerchSort = "price"
+	MerchSortPriceDesc  MerchSort = "-price"
+	MerchSortName       MerchSort = "name"
+	MerchSortPopularity MerchSort = "popularity"
+	MerchSortNewest     MerchSort = "newest"
+)
+
+// IsValid сообщает, поддерживается ли порядок сортировки
+func (s MerchSort) IsValid() bool {
+	switch s {
+	case MerchSortDefault, MerchSortPriceAsc, MerchSortPriceDesc,
+		MerchSortName, MerchSortPopularity, MerchSortNewest:
+		return true
+	}
+	return false
+}
+
+// MerchFilter содержит параметры поиска по каталогу, пустые поля не участвуют в фильтрации
+type MerchFilter struct {
+	Category string
+	Tag      string
+	MinPrice *int64
+	MaxPrice *int64
+	Query    string
+	InStock  bool
+	Sort     MerchSort
+}
+
+// MerchVariant представляет вариант товара (размер, цвет) с собственным остатком
+type MerchVariant struct {
 	ID        int64     `json:"id" db:"id"`
-	UserID    int64     `json:"user_id" db:"user_id"`
 	MerchID   int64     `json:"merch_id" db:"merch_id"`
-	Quantity  int       `json:"quantity" db:"quantity"`
+	SKU       string    `json:"sku" db:"sku"`
+	Size      *string   `json:"size,omitempty" db:"size"`
+	Color     *string   `json:"color,omitempty" db:"color"`
+	Price     *int64    `json:"price,omitempty" db:"price"`
+	Stock     int       `json:"stock" db:"stock"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// PriceFor возвращает цену варианта с учетом цены базового товара
+func (v *MerchVariant) PriceFor(merch *Merch) int64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return merch.Price
+}
+
+// Purchase представляет покупку мерча пользователем.
+// UnitPrice и TotalPrice фиксируют цену на момент покупки.
+type Purchase struct {
+	ID         int64     `json:"id" db:"id"`
+	UserID     int64     `json:"user_id" db:"user_id"`
+	MerchID    int64     `json:"merch_id" db:"merch_id"`
+	VariantID  *int64    `json:"variant_id,omitempty" db:"variant_id"`
+	Quantity   int       `json:"quantity" db:"quantity"`
+	UnitPrice  int64     `json:"unit_price" db:"unit_price"`
+	TotalPrice int64     `json:"total_price" db:"total_price"`
+	OrderID    int64     `json:"order_id" db:"order_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	// BuyerBalance - баланс покупателя сразу после списания. Не хранится.
+	BuyerBalance int64 `json:"-" db:"-"`
 }
 
 // PurchaseResponse представляет покупку мерча с дополнительной информацией
 type PurchaseResponse struct {
+	ID          int64       `json:"id" db:"id"`
+	UserID      int64       `json:"user_id" db:"user_id"`
+	MerchID     int64       `json:"merch_id" db:"merch_id"`
+	MerchName   string      `json:"merch_name" db:"merch_name"`
+	VariantID   *int64      `json:"variant_id,omitempty" db:"variant_id"`
+	VariantSKU  *string     `json:"variant_sku,omitempty" db:"variant_sku"`
+	Quantity    int         `json:"quantity" db:"quantity"`
+	UnitPrice   int64       `json:"unit_price" db:"unit_price"`
+	TotalPrice  int64       `json:"total_price" db:"total_price"`
+	OrderID     int64       `json:"order_id" db:"order_id"`
+	OrderStatus OrderStatus `json:"order_status" db:"order_status"`
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+}
+
+// CartItem представляет позицию в корзине пользователя
+type CartItem struct {
 	ID         int64     `json:"id" db:"id"`
-	UserID     int64     `json:"user_id" db:"user_id"`
+	UserID     int64     `json:"-" db:"user_id"`
 	MerchID    int64     `json:"merch_id" db:"merch_id"`
 	MerchName  string    `json:"merch_name" db:"merch_name"`
-	MerchPrice int64     `json:"merch_price" db:"merch_price"`
+	VariantID  *int64    `json:"variant_id,omitempty" db:"variant_id"`
+	VariantSKU *string   `json:"variant_sku,omitempty" db:"variant_sku"`
+	UnitPrice  int64     `json:"unit_price" db:"unit_price"`
 	Quantity   int       `json:"quantity" db:"quantity"`
+	TotalPrice int64     `json:"total_price" db:"total_price"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Cart представляет корзину пользователя с итоговой стоимостью
+type Cart struct {
+	Items      []*CartItem `json:"items"`
+	TotalPrice int64       `json:"total_price"`
+}
+
+// OrderStatus описывает этап выполнения заказа
+type OrderStatus string
+
+const (
+	OrderStatusPlaced    OrderStatus = "placed"
+	OrderStatusPacked    OrderStatus = "packed"
+	OrderStatusShipped   OrderStatus = "shipped"
+	OrderStatusDelivered OrderStatus = "delivered"
+	OrderStatusCancelled OrderStatus = "cancelled"
+)
+
+// orderTransitions содержит допустимые переходы между статусами заказа
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderStatusPlaced:  {OrderStatusPacked, OrderStatusCancelled},
+	OrderStatusPacked:  {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped: {OrderStatusDelivered},
+}
+
+// CanTransitionTo сообщает, можно ли перевести заказ в указанный статус
+func (s OrderStatus) CanTransitionTo(next OrderStatus) bool {
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// DeliveryMethod описывает способ получения заказа
+type DeliveryMethod string
+
+const (
+	DeliveryMethodOffice DeliveryMethod = "office"
+	DeliveryMethodPickup DeliveryMethod = "pickup"
+)
+
+// DeliveryDetails содержит данные о доставке заказа
+type DeliveryDetails struct {
+	Method         DeliveryMethod `json:"delivery_method" db:"delivery_method"`
+	OfficeLocation *string        `json:"office_location,omitempty" db:"office_location"`
+	PickupPoint    *string        `json:"pickup_point,omitempty" db:"pickup_point"`
+}
+
+// Validate проверяет, что для выбранного способа указано место получения
+func (d DeliveryDetails) Validate() error {
+	switch d.Method {
+	case DeliveryMethodOffice:
+		if d.OfficeLocation == nil || *d.OfficeLocation == "" || d.PickupPoint != nil {
+			return ErrInvalidDelivery
+		}
+	case DeliveryMethodPickup:
+		if d.PickupPoint == nil || *d.PickupPoint == "" || d.OfficeLocation != nil {
+			return ErrInvalidDelivery
+		}
+	default:
+		return ErrInvalidDelivery
+	}
+	return nil
+}
+
+// Order представляет заказ, объединяющий покупки одного оформления
+type Order struct {
+	ID         int64       `json:"id" db:"id"`
+	UserID     int64       `json:"user_id" db:"user_id"`
+	TotalPrice int64       `json:"total_price" db:"total_price"`
+	Status     OrderStatus `json:"status" db:"status"`
+	DeliveryDetails
+	CreatedAt time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at" db:"updated_at"`
+	Purchases []*PurchaseResponse `json:"purchases" db:"-"`
+	// BuyerBalance - баланс покупателя сразу после оформления или отмены заказа. Не хранится.
+	BuyerBalance int64 `json:"-" db:"-"`
 }
 
 // Transaction представляет операцию с монетами
 type Transaction struct {
-	ID          int64     `json:"id" db:"id"`
-	FromUserID  int64     `json:"from_user_id" db:"from_user_id"`
-	ToUserID    int64     `json:"to_user_id" db:"to_user_id"`
-	Amount      int64     `json:"amount" db:"amount"`
-	Description *string   `json:"description,omitempty" db:"description"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	ID           int64             `json:"id" db:"id"`
+	FromUserID   int64             `json:"from_user_id" db:"from_user_id"`
+	FromUsername string            `json:"from_username,omitempty" db:"from_username"`
+	ToUserID     int64             `json:"to_user_id" db:"to_user_id"`
+	ToUsername   string            `json:"to_username,omitempty" db:"to_username"`
+	Amount       int64             `json:"amount" db:"amount"`
+	Description  *string           `json:"description,omitempty" db:"description"`
+	Kind         TransactionKind   `json:"kind" db:"kind"`
+	ReasonCode   *AdjustmentReason `json:"reason_code,omitempty" db:"reason_code"`
+	CreatedBy    *int64            `json:"created_by,omitempty" db:"created_by"`
+	IsPublic     bool              `json:"is_public" db:"is_public"`
+	Category     *KudosCategory    `json:"category,omitempty" db:"category"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	// Балансы участников сразу после перевода. Не хранятся.
+	SenderBalance    int64 `json:"-" db:"-"`
+	RecipientBalance int64 `json:"-" db:"-"`
+}
+
+// KudosCategory - категория благодарности, указываемая при переводе
+type KudosCategory string
+
+const (
+	KudosTeamwork  KudosCategory = "teamwork"
+	KudosHelp      KudosCategory = "help"
+	KudosMentoring KudosCategory = "mentoring"
+)
+
+func (c KudosCategory) IsValid() bool {
+	switch c {
+	case KudosTeamwork, KudosHelp, KudosMentoring:
+		return true
+	}
+	return false
+}
+
+// KudosOptions задает публикацию перевода в ленте благодарностей.
+// Пустая категория означает перевод без категории.
+type KudosOptions struct {
+	Public   bool
+	Category KudosCategory
+}
+
+// Kudos - публичная благодарность в общей ленте
+type Kudos struct {
+	ID           int64          `json:"id" db:"id"`
+	FromUserID   int64          `json:"from_user_id" db:"from_user_id"`
+	FromUsername string         `json:"from_username" db:"from_username"`
+	ToUserID     int64          `json:"to_user_id" db:"to_user_id"`
+	ToUsername   string         `json:"to_username" db:"to_username"`
+	Amount       int64          `json:"amount" db:"amount"`
+	Category     *KudosCategory `json:"category,omitempty" db:"category"`
+	Message      *string        `json:"message,omitempty" db:"description"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+}
+
+// LeaderboardPeriod - календарный период рейтинга благодарностей в UTC
+type LeaderboardPeriod string
+
+const (
+	LeaderboardWeek  LeaderboardPeriod = "week"
+	LeaderboardMonth LeaderboardPeriod = "month"
+	LeaderboardYear  LeaderboardPeriod = "year"
+	LeaderboardAll   LeaderboardPeriod = "all"
+)
+
+func (p LeaderboardPeriod) IsValid() bool {
+	switch p {
+	case LeaderboardWeek, LeaderboardMonth, LeaderboardYear, LeaderboardAll:
+		return true
+	}
+	return false
+}
+
+// Start возвращает начало текущего периода, nil для рейтинга за все время.
+// Неделя начинается с понедельника.
+func (p LeaderboardPeriod) Start(now time.Time) *time.Time {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var start time.Time
+	switch p {
+	case LeaderboardWeek:
+		start = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	case LeaderboardMonth:
+		start = today.AddDate(0, 0, 1-today.Day())
+	case LeaderboardYear:
+		start = today.AddDate(0, 0, 1-today.YearDay())
+	default:
+		return nil
+	}
+	return &start
+}
+
+// LeaderboardEntry - строка рейтинга пользователей по полученным публичным благодарностям
+type LeaderboardEntry struct {
+	Rank          int    `json:"rank" db:"rank"`
+	UserID        int64  `json:"user_id" db:"user_id"`
+	Username      string `json:"username" db:"username"`
+	KudosCount    int64  `json:"kudos_count" db:"kudos_count"`
+	CoinsReceived int64  `json:"coins_received" db:"coins_received"`
+}
+
+// AnalyticsRange задает период [From, To) и размер топа для аналитики, nil означает без границы
+type AnalyticsRange struct {
+	From  *time.Time
+	To    *time.Time
+	Limit int
+}
+
+// UserTotal - строка топа пользователей по сумме монет
+type UserTotal struct {
+	UserID   int64  `json:"user_id" db:"user_id"`
+	Username string `json:"username" db:"username"`
+	Amount   int64  `json:"amount" db:"amount"`
+	Count    int64  `json:"count" db:"count"`
+}
+
+// MerchTotal - строка топа товаров по количеству купленных единиц
+type MerchTotal struct {
+	MerchID  int64  `json:"merch_id" db:"merch_id"`
+	Name     string `json:"name" db:"name"`
+	Quantity int64  `json:"quantity" db:"quantity"`
+	Buyers   int64  `json:"buyers" db:"buyers"`
+	Revenue  int64  `json:"revenue" db:"revenue"`
+}
+
+// EconomySummary описывает денежную массу и оборот монет за период.
+// Velocity - сколько раз за период каждая монета в обороте в среднем сменила владельца.
+type EconomySummary struct {
+	Circulation    int64   `json:"circulation" db:"circulation"`
+	Issued         int64   `json:"issued" db:"issued"`
+	Spent          int64   `json:"spent" db:"spent"`
+	TransferVolume int64   `json:"transfer_volume" db:"transfer_volume"`
+	PurchaseVolume int64   `json:"purchase_volume" db:"purchase_volume"`
+	Velocity       float64 `json:"velocity" db:"-"`
+}
+
+// DailyVolume - объемы покупок и переводов за день (UTC)
+type DailyVolume struct {
+	Day            time.Time `json:"day" db:"day"`
+	PurchasesCount int64     `json:"purchases_count" db:"purchases_count"`
+	PurchasedItems int64     `json:"purchased_items" db:"purchased_items"`
+	PurchaseVolume int64     `json:"purchase_volume" db:"purchase_volume"`
+	TransfersCount int64     `json:"transfers_count" db:"transfers_count"`
+	TransferVolume int64     `json:"transfer_volume" db:"transfer_volume"`
+}
+
+// TransactionKind описывает происхождение перевода
+type TransactionKind string
+
+const (
+	TransactionKindTransfer   TransactionKind = "transfer"
+	TransactionKindGrant      TransactionKind = "grant"
+	TransactionKindAdjustment TransactionKind = "adjustment"
+	TransactionKindRefund     TransactionKind = "refund"
+	TransactionKindExpiry     TransactionKind = "expiry"
+)
+
+// AdjustmentReason - обязательный код причины ручного начисления или списания
+type AdjustmentReason string
+
+const (
+	AdjustmentReasonBonus      AdjustmentReason = "bonus"
+	AdjustmentReasonAward      AdjustmentReason = "award"
+	AdjustmentReasonCorrection AdjustmentReason = "correction"
+	AdjustmentReasonRefund     AdjustmentReason = "refund"
+	AdjustmentReasonPenalty    AdjustmentReason = "penalty"
+)
+
+func (r AdjustmentReason) IsValid() bool {
+	switch r {
+	case AdjustmentReasonBonus, AdjustmentReasonAward, AdjustmentReasonCorrection,
+		AdjustmentReasonRefund, AdjustmentReasonPenalty:
+		return true
+	}
+	return false
+}
+
+// AdjustmentDirection описывает направление ручной корректировки баланса
+type AdjustmentDirection string
+
+const (
+	AdjustmentCredit AdjustmentDirection = "credit"
+	AdjustmentDebit  AdjustmentDirection = "debit"
+)
+
+// GrantSchedule описывает регулярное начисление Amount монет каждому пользователю.
+// DayOfMonth ограничен 28, чтобы начисление было в каждом месяце.
+type GrantSchedule struct {
+	ID         int64     `json:"id" db:"id"`
+	Name       string    `json:"name" db:"name"`
+	Amount     int64     `json:"amount" db:"amount"`
+	DayOfMonth int       `json:"day_of_month" db:"day_of_month"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// DueDate возвращает последнюю дату начисления не позже now (в UTC)
+func (s *GrantSchedule) DueDate(now time.Time) time.Time {
+	return monthlyDate(now, s.DayOfMonth, 0)
+}
+
+// NextDate возвращает ближайшую дату начисления после now (в UTC)
+func (s *GrantSchedule) NextDate(now time.Time) time.Time {
+	return monthlyDate(now, s.DayOfMonth, 1)
+}
+
+// monthlyDate возвращает day число месяца: последнее наступившее при shift = 0
+// или следующее за ним при shift = 1
+func monthlyDate(now time.Time, day, shift int) time.Time {
+	now = now.UTC()
+	date := time.Date(now.Year(), now.Month(), day, 0, 0, 0, 0, time.UTC)
+	if date.After(now) {
+		date = date.AddDate(0, -1, 0)
+	}
+	return date.AddDate(0, shift, 0)
+}
+
+// ExpiryRule задает сгорание монет: в ExpiryDay числа каждого месяца сгорают монеты,
+// полученные раньше чем Months месяцев назад. Монеты тратятся в порядке поступления (FIFO).
+type ExpiryRule struct {
+	Months int
+	Day    int
+}
+
+func (r ExpiryRule) Enabled() bool {
+	return r.Months > 0
+}
+
+// DueDate возвращает последнюю дату сгорания не позже now
+func (r ExpiryRule) DueDate(now time.Time) time.Time {
+	return monthlyDate(now, r.Day, 0)
+}
+
+// NextDate возвращает ближайшую дату сгорания после now
+func (r ExpiryRule) NextDate(now time.Time) time.Time {
+	return monthlyDate(now, r.Day, 1)
+}
+
+// Cutoff возвращает границу: монеты, полученные до нее, сгорают в date
+func (r ExpiryRule) Cutoff(date time.Time) time.Time {
+	return date.AddDate(0, -r.Months, 0)
+}
+
+// AllowanceRunKind описывает тип запуска фонового начисления
+type AllowanceRunKind string
+
+const (
+	AllowanceRunGrant  AllowanceRunKind = "grant"
+	AllowanceRunExpiry AllowanceRunKind = "expiry"
+)
+
+// AllowanceRun - проведенный запуск начисления или сгорания за период
+type AllowanceRun struct {
+	ID         int64            `json:"id" db:"id"`
+	Kind       AllowanceRunKind `json:"kind" db:"kind"`
+	ScheduleID *int64           `json:"schedule_id,omitempty" db:"schedule_id"`
+	Period     time.Time        `json:"period" db:"period"`
+	UsersCount int              `json:"users_count" db:"users_count"`
+	Amount     int64            `json:"amount" db:"amount"`
+	CreatedAt  time.Time        `json:"created_at" db:"created_at"`
+}
+
+// UserBalance - баланс пользователя сразу после изменения
+type UserBalance struct {
+	UserID  int64 `db:"user_id"`
+	Balance int64 `db:"balance"`
+}
+
+// UpcomingGrant - ближайшее начисление по расписанию
+type UpcomingGrant struct {
+	Name   string    `json:"name"`
+	Amount int64     `json:"amount"`
+	Date   time.Time `json:"date"`
+}
+
+// AllowanceOutlook показывает пользователю ближайшие начисления и сгорание монет
+type AllowanceOutlook struct {
+	UpcomingGrants []*UpcomingGrant `json:"upcoming_grants"`
+	// ExpiringAmount монет сгорит в ExpiresAt, если не потратить их раньше
+	ExpiringAmount int64      `json:"expiring_amount"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
 }
 
-// UserInfoResponse представляет полную информацию о пользователе
+// BalanceAdjustment описывает начисление или списание монет администратором
+type BalanceAdjustment struct {
+	UserID     int64
+	Direction  AdjustmentDirection
+	Amount     int64
+	ReasonCode AdjustmentReason
+	Comment    string
+}
+
+// TransactionDirection задает направление перевода относительно пользователя
+type TransactionDirection string
+
+const (
+	TransactionDirectionAll      TransactionDirection = ""
+	TransactionDirectionSent     TransactionDirection = "sent"
+	TransactionDirectionReceived TransactionDirection = "received"
+)
+
+// TransactionFilter содержит параметры поиска по истории переводов, пустые поля не участвуют в фильтрации
+type TransactionFilter struct {
+	Direction    TransactionDirection
+	Counterparty string
+	MinAmount    *int64
+	MaxAmount    *int64
+	From         *time.Time
+	To           *time.Time
+	Description  string
+}
+
+// WishlistItem представляет товар в списке желаний пользователя.
+// Affordable показывает, хватает ли текущего баланса на товар.
+type WishlistItem struct {
+	ID         int64     `json:"id" db:"id"`
+	UserID     int64     `json:"-" db:"user_id"`
+	MerchID    int64     `json:"merch_id" db:"merch_id"`
+	MerchName  string    `json:"merch_name" db:"merch_name"`
+	VariantID  *int64    `json:"variant_id,omitempty" db:"variant_id"`
+	VariantSKU *string   `json:"variant_sku,omitempty" db:"variant_sku"`
+	Price      int64     `json:"price" db:"price"`
+	InStock    bool      `json:"in_stock" db:"in_stock"`
+	Affordable bool      `json:"affordable" db:"affordable"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// NotificationType описывает событие, о котором уведомляется пользователь
+type NotificationType string
+
+const (
+	NotificationCoinsReceived      NotificationType = "coins_received"
+	NotificationBalanceAdjusted    NotificationType = "balance_adjusted"
+	NotificationPurchaseCompleted  NotificationType = "purchase_completed"
+	NotificationOrderAccepted      NotificationType = "order_accepted"
+	NotificationOrderShipped       NotificationType = "order_shipped"
+	NotificationWishlistAffordable NotificationType = "wishlist_affordable"
+	NotificationWishlistRestocked  NotificationType = "wishlist_restocked"
+)
+
+// Notification представляет уведомление пользователя внутри приложения
+type Notification struct {
+	ID        int64            `json:"id" db:"id"`
+	UserID    int64            `json:"-" db:"user_id"`
+	Type      NotificationType `json:"type" db:"type"`
+	Message   string           `json:"message" db:"message"`
+	Payload   json.RawMessage  `json:"payload" db:"payload"`
+	ReadAt    *time.Time       `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+}
+
+// NotificationPage представляет страницу уведомлений вместе с числом непрочитанных
+type NotificationPage struct {
+	*pagination.Page[*Notification]
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// EventType описывает событие, доставляемое клиентам в реальном времени
+type EventType string
+
+const (
+	EventBalanceChanged     EventType = "balance_changed"
+	EventTransferReceived   EventType = "transfer_received"
+	EventOrderStatusChanged EventType = "order_status_changed"
+)
+
+// Event представляет событие для подключенных клиентов пользователя
+type Event struct {
+	Type      EventType       `json:"type"`
+	UserID    int64           `json:"-"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Типы событий outbox для внешних систем
+const (
+	OutboxEventTransferCompleted = "transfer.completed"
+	OutboxEventPurchaseCreated   = "purchase.created"
+	OutboxEventOrderCancelled    = "order.cancelled"
+)
+
+// OutboxEvent представляет событие, ожидающее отправки во внешние системы.
+// ID постоянен между повторными отправками и служит ключом дедупликации.
+type OutboxEvent struct {
+	ID            string          `json:"id" db:"id"`
+	EventType     string          `json:"type" db:"event_type"`
+	AggregateType string          `json:"aggregate_type" db:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id" db:"aggregate_id"`
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+	Attempts      int             `json:"-" db:"attempts"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// WebhookAllEvents подписывает вебхук на все типы событий
+const WebhookAllEvents = "*"
+
+// WebhookSubscription представляет подписку внешней системы на события.
+// Secret возвращается только при создании подписки.
+type WebhookSubscription struct {
+	ID         int64     `json:"id" db:"id"`
+	URL        string    `json:"url" db:"url"`
+	Secret     string    `json:"secret,omitempty" db:"secret"`
+	EventTypes []string  `json:"event_types" db:"-"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookDeliveryStatus описывает состояние доставки вебхука
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryDead - попытки исчерпаны, доставка возможна только вручную
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery представляет запись журнала доставки события подписчику
+type WebhookDelivery struct {
+	ID             int64                 `json:"id" db:"id"`
+	SubscriptionID int64                 `json:"subscription_id" db:"subscription_id"`
+	EventID        string                `json:"event_id" db:"event_id"`
+	EventType      string                `json:"event_type" db:"event_type"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" db:"next_attempt_at"`
+	LastStatusCode *int                  `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      *string               `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// WebhookDispatch содержит все необходимое для отправки одной доставки
+type WebhookDispatch struct {
+	Delivery *WebhookDelivery
+	URL      string
+	Secret   string
+	Event    *OutboxEvent
+}
+
+// AuditAction описывает действие, фиксируемое в журнале аудита
+type AuditAction string
+
+const (
+	AuditUserLogin            AuditAction = "user.login"
+	AuditUserLoginFailed      AuditAction = "user.login_failed"
+	AuditUserAdminChange      AuditAction = "user.admin"
+	AuditTransfer             AuditAction = "transaction.transfer"
+	AuditBalanceAdjust        AuditAction = "balance.adjust"
+	AuditPurchase             AuditAction = "merch.buy"
+	AuditCheckout             AuditAction = "cart.checkout"
+	AuditMerchImageUpload     AuditAction = "merch.image_upload"
+	AuditMerchImageDelete     AuditAction = "merch.image_delete"
+	AuditVariantStockUpdate   AuditAction = "merch.variant_stock"
+	AuditOrderStatusUpdate    AuditAction = "order.status"
+	AuditOrderCancel          AuditAction = "order.cancel"
+	AuditWebhookCreate        AuditAction = "webhook.create"
+	AuditWebhookDelete        AuditAction = "webhook.delete"
+	AuditGrantScheduleCreate  AuditAction = "grant_schedule.create"
+	AuditGrantScheduleDisable AuditAction = "grant_schedule.disable"
+)
+
+// AuditGenesisHash - предыдущий хеш для первой записи журнала
+var AuditGenesisHash = strings.Repeat("0", 64)
+
+// AuditEntry представляет запись журнала аудита.
+// Hash вычисляется от PrevHash и содержимого записи, образуя цепочку.
+type AuditEntry struct {
+	ID         int64           `json:"id" db:"id"`
+	ActorID    *int64          `json:"actor_id,omitempty" db:"actor_id"`
+	Action     AuditAction     `json:"action" db:"action"`
+	TargetType string          `json:"target_type" db:"target_type"`
+	TargetID   string          `json:"target_id" db:"target_id"`
+	Before     json.RawMessage `json:"before,omitempty" db:"before"`
+	After      json.RawMessage `json:"after,omitempty" db:"after"`
+	RequestID  string          `json:"request_id" db:"request_id"`
+	IP         string          `json:"ip" db:"ip"`
+	UserAgent  string          `json:"user_agent" db:"user_agent"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	PrevHash   string          `json:"prev_hash" db:"prev_hash"`
+	Hash       string          `json:"hash" db:"hash"`
+}
+
+// ComputeHash вычисляет SHA-256 записи. JSON-поля приводятся к каноническому виду,
+// поэтому хеш совпадает и до сохранения, и после чтения из JSONB.
+func (e *AuditEntry) ComputeHash() (string, error) {
+	before, err := canonicalJSON(e.Before)
+	if err != nil {
+		return "", err
+	}
+	after, err := canonicalJSON(e.After)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(struct {
+		PrevHash   string          `json:"prev_hash"`
+		ActorID    *int64          `json:"actor_id"`
+		Action     AuditAction     `json:"action"`
+		TargetType string          `json:"target_type"`
+		TargetID   string          `json:"target_id"`
+		Before     json.RawMessage `json:"before"`
+		After      json.RawMessage `json:"after"`
+		RequestID  string          `json:"request_id"`
+		IP         string          `json:"ip"`
+		UserAgent  string          `json:"user_agent"`
+		CreatedAt  string          `json:"created_at"`
+	}{
+		PrevHash:   e.PrevHash,
+		ActorID:    e.ActorID,
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Before:     before,
+		After:      after,
+		RequestID:  e.RequestID,
+		IP:         e.IP,
+		UserAgent:  e.UserAgent,
+		CreatedAt:  e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalJSON переупорядочивает ключи и убирает пробелы, сохраняя числа как есть
+func canonicalJSON(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return json.RawMessage("null"), nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+// AuditFilter описывает параметры поиска по журналу аудита
+type AuditFilter struct {
+	ActorID    *int64
+	Action     AuditAction
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditVerification содержит результат проверки цепочки хешей
+type AuditVerification struct {
+	Valid   bool  `json:"valid"`
+	Checked int64 `json:"checked"`
+	// BrokenAt - первая запись, на которой цепочка не сходится
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+}
+
+// StatementEntryType описывает тип движения монет в выписке
+type StatementEntryType string
+
+const (
+	StatementEntryTransferIn  StatementEntryType = "transfer_in"
+	StatementEntryTransferOut StatementEntryType = "transfer_out"
+	StatementEntryPurchase    StatementEntryType = "purchase"
+	StatementEntryRefund      StatementEntryType = "refund"
+	StatementEntryGrant       StatementEntryType = "grant"
+	StatementEntryAdjustment  StatementEntryType = "adjustment"
+	StatementEntryExpiry      StatementEntryType = "expiry"
+)
+
+// StatementEntry представляет строку выписки по монетам.
+// Amount положителен для поступлений и отрицателен для списаний.
+type StatementEntry struct {
+	CreatedAt    time.Time          `json:"date" db:"created_at"`
+	Type         StatementEntryType `json:"type" db:"type"`
+	ReferenceID  int64              `json:"reference_id" db:"reference_id"`
+	Amount       int64              `json:"amount" db:"amount"`
+	Balance      int64              `json:"balance" db:"-"`
+	Counterparty *string            `json:"counterparty,omitempty" db:"counterparty"`
+	Description  *string            `json:"description,omitempty" db:"description"`
+}
+
+// UserInfoResponse представляет сводную информацию о пользователе с первыми страницами истории
 type UserInfoResponse struct {
-	Balance      int64               `json:"balance"`
-	Transactions []*Transaction      `json:"transactions"`
-	Purchases    []*PurchaseResponse `json:"inventory"`
+	Balance                int64               `json:"balance"`
+	Transactions           []*Transaction      `json:"transactions"`
+	TransactionsNextCursor string              `json:"transactions_next_cursor,omitempty"`
+	Purchases              []*PurchaseResponse `json:"inventory"`
+	PurchasesNextCursor    string              `json:"inventory_next_cursor,omitempty"`
+	Allowance              *AllowanceOutlook   `json:"allowance,omitempty"`
+}
+
+// HealthReport - результат проверки готовности реплики: общее состояние и состояние каждой проверки
+type HealthReport struct {
+	Ready  bool              `json:"ready"`
+	Checks map[string]string `json:"checks"`
+}
+
+// BuildInfo описывает сборку приложения
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
 }
@@ -0,0 +1,384 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOrderStatusCanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from OrderStatus
+		to   OrderStatus
+		want bool
+	}{
+		{OrderStatusPlaced, OrderStatusPacked, true},
+		{OrderStatusPlaced, OrderStatusCancelled, true},
+		{OrderStatusPlaced, OrderStatusShipped, false},
+		{OrderStatusPlaced, OrderStatusDelivered, false},
+		{OrderStatusPlaced, OrderStatusPlaced, false},
+		{OrderStatusPacked, OrderStatusShipped, true},
+		{OrderStatusPacked, OrderStatusCancelled, true},
+		{OrderStatusPacked, OrderStatusPlaced, false},
+		{OrderStatusShipped, OrderStatusDelivered, true},
+		{OrderStatusShipped, OrderStatusCancelled, false},
+		{OrderStatusDelivered, OrderStatusCancelled, false},
+		{OrderStatusDelivered, OrderStatusPlaced, false},
+		{OrderStatusCancelled, OrderStatusPlaced, false},
+		{OrderStatusCancelled, OrderStatusCancelled, false},
+		{OrderStatus("unknown"), OrderStatusPacked, false},
+		{OrderStatusPlaced, OrderStatus("unknown"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.want {
+				t.Errorf("%q.CanTransitionTo(%q) = %t, want %t", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveryDetailsValidate(t *testing.T) {
+	place := func(s string) *string { return &s }
+
+	tests := []struct {
+		name    string
+		details DeliveryDetails
+		wantErr bool
+	}{
+		{name: "office", details: DeliveryDetails{Method: DeliveryMethodOffice, OfficeLocation: place("Москва, 5 этаж")}},
+		{name: "pickup", details: DeliveryDetails{Method: DeliveryMethodPickup, PickupPoint: place("ПВЗ 12")}},
+		{name: "office without location", details: DeliveryDetails{Method: DeliveryMethodOffice}, wantErr: true},
+		{name: "office with empty location", details: DeliveryDetails{Method: DeliveryMethodOffice, OfficeLocation: place("")}, wantErr: true},
+		{
+			name:    "office with pickup point",
+			details: DeliveryDetails{Method: DeliveryMethodOffice, OfficeLocation: place("Москва"), PickupPoint: place("ПВЗ 12")},
+			wantErr: true,
+		},
+		{name: "pickup without point", details: DeliveryDetails{Method: DeliveryMethodPickup}, wantErr: true},
+		{name: "pickup with empty point", details: DeliveryDetails{Method: DeliveryMethodPickup, PickupPoint: place("")}, wantErr: true},
+		{
+			name:    "pickup with office location",
+			details: DeliveryDetails{Method: DeliveryMethodPickup, PickupPoint: place("ПВЗ 12"), OfficeLocation: place("Москва")},
+			wantErr: true,
+		},
+		{name: "empty method", details: DeliveryDetails{OfficeLocation: place("Москва")}, wantErr: true},
+		{name: "unknown method", details: DeliveryDetails{Method: "courier", PickupPoint: place("ПВЗ 12")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.details.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDelivery) {
+					t.Errorf("Validate() error = %v, want ErrInvalidDelivery", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Validate() error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{name: "empty", raw: "", want: "null"},
+		{name: "null", raw: "null", want: "null"},
+		{name: "sorted keys", raw: `{"b":1,"a":2}`, want: `{"a":2,"b":1}`},
+		{name: "jsonb spacing", raw: `{"b": 1, "a": {"d": [1, 2], "c": null}}`, want: `{"a":{"c":null,"d":[1,2]},"b":1}`},
+		{name: "large integer", raw: `{"balance":9007199254740993}`, want: `{"balance":9007199254740993}`},
+		{name: "decimal kept as is", raw: `{"ratio":0.10}`, want: `{"ratio":0.10}`},
+		{name: "unicode", raw: `{"comment":"Премия"}`, want: `{"comment":"Премия"}`},
+		{name: "invalid", raw: `{"a":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canonicalJSON(json.RawMessage(tt.raw))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("canonicalJSON(%q) = %s, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("canonicalJSON(%q): %v", tt.raw, err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("canonicalJSON(%q) = %s, want %s", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditEntryComputeHash(t *testing.T) {
+	actorID := int64(7)
+	otherActorID := int64(8)
+	base := AuditEntry{
+		ActorID:    &actorID,
+		Action:     AuditPurchase,
+		TargetType: "user",
+		TargetID:   "42",
+		Before:     json.RawMessage(`{"balance":100}`),
+		After:      json.RawMessage(`{"balance":150,"quantity":2}`),
+		RequestID:  "req-1",
+		IP:         "10.0.0.1",
+		UserAgent:  "curl/8.0",
+		CreatedAt:  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		PrevHash:   "abc",
+	}
+
+	want, err := base.ComputeHash()
+	if err != nil {
+		t.Fatalf("ComputeHash: %v", err)
+	}
+	if len(want) != 64 {
+		t.Fatalf("hash %q is not a hex SHA-256", want)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(e *AuditEntry)
+		same   bool
+	}{
+		{name: "unchanged", modify: func(e *AuditEntry) {}, same: true},
+		{name: "jsonb formatting", modify: func(e *AuditEntry) {
+			e.After = json.RawMessage(`{"quantity": 2, "balance": 150}`)
+		}, same: true},
+		{name: "other time zone", modify: func(e *AuditEntry) {
+			e.CreatedAt = e.CreatedAt.In(time.FixedZone("MSK", 3*60*60))
+		}, same: true},
+		{name: "ids and stored hash ignored", modify: func(e *AuditEntry) {
+			e.ID, e.Hash = 99, "stored"
+		}, same: true},
+		{name: "prev hash", modify: func(e *AuditEntry) { e.PrevHash = "abd" }},
+		{name: "actor", modify: func(e *AuditEntry) { e.ActorID = &otherActorID }},
+		{name: "no actor", modify: func(e *AuditEntry) { e.ActorID = nil }},
+		{name: "action", modify: func(e *AuditEntry) { e.Action = AuditTransfer }},
+		{name: "target", modify: func(e *AuditEntry) { e.TargetID = "43" }},
+		{name: "before", modify: func(e *AuditEntry) { e.Before = json.RawMessage(`{"balance":101}`) }},
+		{name: "missing after", modify: func(e *AuditEntry) { e.After = nil }},
+		{name: "request id", modify: func(e *AuditEntry) { e.RequestID = "req-2" }},
+		{name: "ip", modify: func(e *AuditEntry) { e.IP = "10.0.0.2" }},
+		{name: "user agent", modify: func(e *AuditEntry) { e.UserAgent = "curl/8.1" }},
+		{name: "created at", modify: func(e *AuditEntry) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := base
+			tt.modify(&entry)
+
+			got, err := entry.ComputeHash()
+			if err != nil {
+				t.Fatalf("ComputeHash: %v", err)
+			}
+			if (got == want) != tt.same {
+				t.Errorf("ComputeHash() = %s, base %s, want equal: %t", got, want, tt.same)
+			}
+		})
+	}
+
+	t.Run("invalid json", func(t *testing.T) {
+		entry := base
+		entry.Before = json.RawMessage(`{`)
+		if _, err := entry.ComputeHash(); err == nil {
+			t.Error("ComputeHash() with invalid before = nil error, want error")
+		}
+	})
+}
+
+func TestAdjustmentReasonIsValid(t *testing.T) {
+	tests := []struct {
+		reason AdjustmentReason
+		want   bool
+	}{
+		{AdjustmentReasonBonus, true},
+		{AdjustmentReasonAward, true},
+		{AdjustmentReasonCorrection, true},
+		{AdjustmentReasonRefund, true},
+		{AdjustmentReasonPenalty, true},
+		{"", false},
+		{"Bonus", false},
+		{" bonus", false},
+		{"gift", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.reason.IsValid(); got != tt.want {
+			t.Errorf("AdjustmentReason(%q).IsValid() = %t, want %t", tt.reason, got, tt.want)
+		}
+	}
+}
+
+func TestExpiryRuleDates(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	msk := time.FixedZone("MSK", 3*60*60)
+
+	tests := []struct {
+		name     string
+		rule     ExpiryRule
+		now      time.Time
+		wantDue  time.Time
+		wantNext time.Time
+	}{
+		{
+			name:     "after day in month",
+			rule:     ExpiryRule{Months: 3, Day: 10},
+			now:      time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC),
+			wantDue:  date(2024, 3, 10),
+			wantNext: date(2024, 4, 10),
+		},
+		{
+			name:     "before day in month",
+			rule:     ExpiryRule{Months: 3, Day: 10},
+			now:      time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC),
+			wantDue:  date(2024, 2, 10),
+			wantNext: date(2024, 3, 10),
+		},
+		{
+			name:     "exactly at midnight of day",
+			rule:     ExpiryRule{Months: 3, Day: 10},
+			now:      date(2024, 3, 10),
+			wantDue:  date(2024, 3, 10),
+			wantNext: date(2024, 4, 10),
+		},
+		{
+			name:     "previous year",
+			rule:     ExpiryRule{Months: 3, Day: 10},
+			now:      time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+			wantDue:  date(2023, 12, 10),
+			wantNext: date(2024, 1, 10),
+		},
+		{
+			name:     "next year",
+			rule:     ExpiryRule{Months: 3, Day: 1},
+			now:      time.Date(2023, 12, 31, 23, 59, 0, 0, time.UTC),
+			wantDue:  date(2023, 12, 1),
+			wantNext: date(2024, 1, 1),
+		},
+		{
+			name:     "day 28 in february",
+			rule:     ExpiryRule{Months: 1, Day: 28},
+			now:      time.Date(2023, 2, 28, 12, 0, 0, 0, time.UTC),
+			wantDue:  date(2023, 2, 28),
+			wantNext: date(2023, 3, 28),
+		},
+		{
+			name:     "local time converted to utc",
+			rule:     ExpiryRule{Months: 3, Day: 10},
+			now:      time.Date(2024, 3, 10, 2, 0, 0, 0, msk),
+			wantDue:  date(2024, 2, 10),
+			wantNext: date(2024, 3, 10),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.DueDate(tt.now); !got.Equal(tt.wantDue) {
+				t.Errorf("DueDate(%s) = %s, want %s", tt.now, got, tt.wantDue)
+			}
+			if got := tt.rule.NextDate(tt.now); !got.Equal(tt.wantNext) {
+				t.Errorf("NextDate(%s) = %s, want %s", tt.now, got, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestExpiryRuleCutoff(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        ExpiryRule
+		date        time.Time
+		want        time.Time
+		wantEnabled bool
+	}{
+		{
+			name: "disabled",
+			rule: ExpiryRule{Months: 0, Day: 1},
+			date: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "one month",
+			rule:        ExpiryRule{Months: 1, Day: 10},
+			date:        time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC),
+			want:        time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+			wantEnabled: true,
+		},
+		{
+			name:        "across year",
+			rule:        ExpiryRule{Months: 3, Day: 10},
+			date:        time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+			want:        time.Date(2023, 11, 10, 0, 0, 0, 0, time.UTC),
+			wantEnabled: true,
+		},
+		{
+			name:        "one year",
+			rule:        ExpiryRule{Months: 12, Day: 28},
+			date:        time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC),
+			want:        time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC),
+			wantEnabled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Enabled(); got != tt.wantEnabled {
+				t.Errorf("Enabled() = %t, want %t", got, tt.wantEnabled)
+			}
+			if got := tt.rule.Cutoff(tt.date); !got.Equal(tt.want) {
+				t.Errorf("Cutoff(%s) = %s, want %s", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeaderboardPeriodStart(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	// Среда; в Москве уже четверг
+	now := time.Date(2024, 2, 28, 22, 30, 0, 0, time.UTC)
+	msk := time.FixedZone("MSK", 3*60*60)
+
+	tests := []struct {
+		name   string
+		period LeaderboardPeriod
+		now    time.Time
+		want   *time.Time
+	}{
+		{name: "week", period: LeaderboardWeek, now: now, want: ptr(date(2024, 2, 26))},
+		{name: "week on monday", period: LeaderboardWeek, now: date(2024, 2, 26), want: ptr(date(2024, 2, 26))},
+		{name: "week on sunday", period: LeaderboardWeek, now: date(2024, 3, 3), want: ptr(date(2024, 2, 26))},
+		{name: "week across year", period: LeaderboardWeek, now: date(2025, 1, 2), want: ptr(date(2024, 12, 30))},
+		{name: "month", period: LeaderboardMonth, now: now, want: ptr(date(2024, 2, 1))},
+		{name: "year", period: LeaderboardYear, now: now, want: ptr(date(2024, 1, 1))},
+		{name: "local time converted to utc", period: LeaderboardMonth, now: time.Date(2024, 3, 1, 1, 0, 0, 0, msk), want: ptr(date(2024, 2, 1))},
+		{name: "all", period: LeaderboardAll, now: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.period.Start(tt.now)
+			if (got == nil) != (tt.want == nil) || (got != nil && !got.Equal(*tt.want)) {
+				t.Errorf("Start(%s) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -1,43 +1,239 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/avito/pkg/pagination"
+)
 
 // Repositories содержит все репозитории приложения
 type Repositories struct {
-	User        UserRepository
-	Merch       MerchRepository
-	Purchase    PurchaseRepository
-	Transaction TransactionRepository
+	User         UserRepository
+	Merch        MerchRepository
+	Purchase     PurchaseRepository
+	Transaction  TransactionRepository
+	Variant      MerchVariantRepository
+	Cart         CartRepository
+	Order        OrderRepository
+	Snapshot     Snapshotter
+	Wishlist     WishlistRepository
+	Notification NotificationRepository
+	Outbox       OutboxRepository
+	Webhook      WebhookRepository
+	Audit        AuditRepository
+	Allowance    AllowanceRepository
+	Kudos        KudosRepository
+	Analytics    AnalyticsRepository
 }
 
 // UserRepository определяет методы для работы с пользователями
 type UserRepository interface {
+	// Create создает пользователя, начальный баланс выдается казначейством
 	Create(ctx context.Context, user *User) error
 	GetByID(ctx context.Context, id int64) (*User, error)
 	GetByUsername(ctx context.Context, username string) (*User, error)
 	UpdateBalance(ctx context.Context, userID int64, amount int64) error
+	// SetAdmin выдает или отзывает права администратора
+	SetAdmin(ctx context.Context, username string, isAdmin bool) (*User, error)
 }
 
 // MerchRepository определяет методы для работы с мерчем
 type MerchRepository interface {
 	Create(ctx context.Context, merch *Merch) error
 	GetByID(ctx context.Context, id int64) (*Merch, error)
-	List(ctx context.Context, limit, offset int) ([]*Merch, error)
+	List(ctx context.Context, filter MerchFilter, params pagination.Params) (*pagination.Page[*Merch], error)
 	UpdateQuantity(ctx context.Context, merchID int64, quantity int) error
+	// SetImage сохраняет ключи изображения и миниатюры, nil удаляет изображение
+	SetImage(ctx context.Context, merchID int64, imageKey, thumbnailKey *string) error
+}
+
+// MerchVariantRepository определяет методы для работы с вариантами мерча
+type MerchVariantRepository interface {
+	GetByID(ctx context.Context, id int64) (*MerchVariant, error)
+	GetByMerchID(ctx context.Context, merchID int64) ([]*MerchVariant, error)
+	// SetStock устанавливает остаток и возвращает предыдущее значение
+	SetStock(ctx context.Context, id int64, stock int) (int, error)
 }
 
 // PurchaseRepository определяет методы для работы с покупками
 type PurchaseRepository interface {
 	Create(ctx context.Context, purchase *Purchase) error
-	GetByUserID(ctx context.Context, userID int64) ([]*PurchaseResponse, error)
+	GetByUserID(ctx context.Context, userID int64, params pagination.Params) (*pagination.Page[*PurchaseResponse], error)
+	// StatementEntries возвращает покупки в [from, to) по возрастанию времени
+	StatementEntries(ctx context.Context, userID int64, from, to time.Time) (StatementIterator, error)
+	// NetChangeSince возвращает изменение баланса от покупок начиная с since
+	NetChangeSince(ctx context.Context, userID int64, since time.Time) (int64, error)
 	GetByID(ctx context.Context, id int64) (*Purchase, error)
+	// Buy списывает стоимость покупки с баланса и создает заказ с одной покупкой в одной транзакции
+	// и заполняет BuyerBalance балансом после списания
+	Buy(ctx context.Context, purchase *Purchase) error
 }
 
 // TransactionRepository определяет методы для работы с транзакциями
 type TransactionRepository interface {
 	Create(ctx context.Context, transaction *Transaction) error
-	GetByUserID(ctx context.Context, userID int64) ([]*Transaction, error)
+	// GetByUserID возвращает переводы пользователя с именами отправителя и получателя
+	GetByUserID(ctx context.Context, userID int64, filter TransactionFilter, params pagination.Params) (*pagination.Page[*Transaction], error)
+	// StatementEntries возвращает переводы пользователя в [from, to) по возрастанию времени
+	StatementEntries(ctx context.Context, userID int64, from, to time.Time) (StatementIterator, error)
+	// NetChangeSince возвращает изменение баланса от переводов начиная с since
+	NetChangeSince(ctx context.Context, userID int64, since time.Time) (int64, error)
 	GetByID(ctx context.Context, id int64) (*Transaction, error)
-	// TransferMoney выполняет перевод денег между пользователями в транзакции
-	TransferMoney(ctx context.Context, fromUserID, toUserID int64, amount int64) error
+	// TransferMoney выполняет перевод денег между пользователями и создает запись о нем в одной транзакции.
+	// Баланс системного отправителя не проверяется.
+	// Заполняет SenderBalance и RecipientBalance балансами сразу после перевода.
+	TransferMoney(ctx context.Context, transaction *Transaction) error
+}
+
+// KudosRepository определяет методы для чтения публичных благодарностей
+type KudosRepository interface {
+	// Feed возвращает публичные переводы от новых к старым, пустая категория означает все
+	Feed(ctx context.Context, category KudosCategory, params pagination.Params) (*pagination.Page[*Kudos], error)
+	// Leaderboard возвращает пользователей с наибольшим числом благодарностей начиная с since, nil - за все время
+	Leaderboard(ctx context.Context, since *time.Time, category KudosCategory, limit int) ([]*LeaderboardEntry, error)
+}
+
+// AnalyticsRepository определяет агрегирующие запросы по покупкам и переводам.
+// Отмененные заказы не учитываются, переводами считаются только переводы между пользователями.
+type AnalyticsRepository interface {
+	TopSpenders(ctx context.Context, r AnalyticsRange) ([]*UserTotal, error)
+	TopReceivers(ctx context.Context, r AnalyticsRange) ([]*UserTotal, error)
+	TopMerch(ctx context.Context, r AnalyticsRange) ([]*MerchTotal, error)
+	Economy(ctx context.Context, r AnalyticsRange) (*EconomySummary, error)
+	DailyVolumes(ctx context.Context, r AnalyticsRange) ([]*DailyVolume, error)
+	RefreshDailyVolumes(ctx context.Context) error
+}
+
+// CartRepository определяет методы для работы с корзиной
+type CartRepository interface {
+	// AddItem добавляет товар в корзину или увеличивает его количество
+	AddItem(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) error
+	SetQuantity(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) error
+	RemoveItem(ctx context.Context, userID, merchID int64, variantID *int64) error
+	GetByUserID(ctx context.Context, userID int64) ([]*CartItem, error)
+}
+
+// OrderRepository определяет методы для работы с заказами
+type OrderRepository interface {
+	// Checkout оформляет заказ из всей корзины пользователя в одной транзакции
+	// и заполняет BuyerBalance балансом после списания
+	Checkout(ctx context.Context, userID int64, delivery DeliveryDetails) (*Order, error)
+	GetByID(ctx context.Context, id int64) (*Order, error)
+	GetByUserID(ctx context.Context, userID int64) ([]*Order, error)
+	// List возвращает заказы всех пользователей, пустой статус отключает фильтр
+	List(ctx context.Context, status OrderStatus, limit, offset int) ([]*Order, error)
+	// UpdateStatus переводит заказ в новый статус. При отмене возвращает монеты пользователю
+	// (BuyerBalance - баланс после возврата) и товар на склад, restocked - варианты, остаток которых вырос с нуля.
+	UpdateStatus(ctx context.Context, id int64, status OrderStatus) (order *Order, restocked []int64, err error)
+	// UpdateDelivery меняет данные доставки, пока заказ не собран
+	UpdateDelivery(ctx context.Context, id int64, delivery DeliveryDetails) error
+}
+
+// StatementIterator построчно читает выписку из базы, не загружая ее в память
+type StatementIterator interface {
+	Next() bool
+	Entry() *StatementEntry
+	Err() error
+	Close() error
+}
+
+// Snapshotter выполняет несколько чтений на одном согласованном снимке базы
+type Snapshotter interface {
+	// ReadSnapshot вызывает fn в read-only транзакции, репозитории читают в ней,
+	// если получают контекст, переданный в fn
+	ReadSnapshot(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// WishlistRepository определяет методы для работы со списком желаний
+type WishlistRepository interface {
+	Add(ctx context.Context, userID, merchID int64, variantID *int64) error
+	Remove(ctx context.Context, userID, merchID int64, variantID *int64) error
+	GetByUserID(ctx context.Context, userID int64) ([]*WishlistItem, error)
+	// GetPricedBetween возвращает товары пользователя с ценой в интервале (minExclusive, maxInclusive]
+	GetPricedBetween(ctx context.Context, userID, minExclusive, maxInclusive int64) ([]*WishlistItem, error)
+	// GetRestocked возвращает записи всех пользователей, которые ждали поступивших вариантов:
+	// сами варианты и товары без выбора варианта, у которых до поступления не было остатка
+	GetRestocked(ctx context.Context, variantIDs []int64) ([]*WishlistItem, error)
+}
+
+// NotificationRepository определяет методы для работы с уведомлениями
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *Notification) error
+	GetByUserID(ctx context.Context, userID int64, unreadOnly bool, params pagination.Params) (*pagination.Page[*Notification], error)
+	CountUnread(ctx context.Context, userID int64) (int64, error)
+	MarkRead(ctx context.Context, userID, id int64) error
+	// MarkAllRead отмечает все уведомления прочитанными и возвращает их количество
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+}
+
+// OutboxRepository определяет методы для отправки событий outbox
+type OutboxRepository interface {
+	// Claim резервирует до limit готовых к отправке событий на время lease,
+	// чтобы их не взял воркер другой реплики
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]*OutboxEvent, error)
+	MarkPublished(ctx context.Context, id string) error
+	// MarkFailed сохраняет ошибку и откладывает следующую попытку до retryAt
+	MarkFailed(ctx context.Context, id string, retryAt time.Time, cause string) error
+}
+
+// WebhookRepository определяет методы для работы с подписками и доставками вебхуков
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, subscription *WebhookSubscription) error
+	GetSubscriptions(ctx context.Context) ([]*WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id int64) (*WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	// EnqueueDeliveries создает доставки события для всех активных подписок на его тип
+	EnqueueDeliveries(ctx context.Context, event *OutboxEvent) error
+	GetDeliveries(ctx context.Context, subscriptionID int64, status WebhookDeliveryStatus, params pagination.Params) (*pagination.Page[*WebhookDelivery], error)
+	// ClaimDeliveries резервирует до limit готовых к отправке доставок на время lease
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*WebhookDispatch, error)
+	MarkDelivered(ctx context.Context, id int64, statusCode int) error
+	// MarkFailed сохраняет результат попытки; при retryAt == nil доставка переводится в dead
+	MarkFailed(ctx context.Context, id int64, statusCode *int, cause string, retryAt *time.Time) error
+	// Redeliver возвращает доставку в очередь с обнуленным счетчиком попыток
+	Redeliver(ctx context.Context, id int64) (*WebhookDelivery, error)
+}
+
+// WebhookSender отправляет подписанную доставку и возвращает HTTP-статус ответа
+type WebhookSender interface {
+	Send(ctx context.Context, dispatch *WebhookDispatch) (int, error)
+}
+
+// AuditRepository определяет методы для работы с журналом аудита
+type AuditRepository interface {
+	// Append дописывает запись в конец цепочки, заполняя PrevHash, Hash и ID
+	Append(ctx context.Context, entry *AuditEntry) error
+	List(ctx context.Context, filter AuditFilter, params pagination.Params) (*pagination.Page[*AuditEntry], error)
+	// Walk передает fn все записи по возрастанию id
+	Walk(ctx context.Context, fn func(entry *AuditEntry) error) error
+}
+
+// AllowanceRepository определяет методы для регулярных начислений и сгорания монет
+type AllowanceRepository interface {
+	CreateSchedule(ctx context.Context, schedule *GrantSchedule) error
+	GetSchedules(ctx context.Context, activeOnly bool) ([]*GrantSchedule, error)
+	DeactivateSchedule(ctx context.Context, id int64) error
+	GetRuns(ctx context.Context, params pagination.Params) (*pagination.Page[*AllowanceRun], error)
+	// RunGrant начисляет монеты по расписанию за период и возвращает балансы пользователей после
+	// начисления. Возвращает nil, если период уже проведен.
+	RunGrant(ctx context.Context, schedule *GrantSchedule, period time.Time) (*AllowanceRun, []UserBalance, error)
+	// RunExpiry списывает в казначейство монеты, полученные до cutoff. Возвращает nil, если период уже проведен.
+	RunExpiry(ctx context.Context, period, cutoff time.Time) (*AllowanceRun, error)
+	// ExpiringAmount возвращает монеты пользователя, полученные до cutoff и еще не потраченные
+	ExpiringAmount(ctx context.Context, userID int64, cutoff time.Time) (int64, error)
+}
+
+// Publisher доставляет события outbox во внешние системы
+type Publisher interface {
+	Publish(ctx context.Context, event *OutboxEvent) error
+}
+
+// BlobStore определяет хранилище двоичных файлов
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Delete(ctx context.Context, key string) error
+	// URL возвращает публичный адрес файла
+	URL(key string) string
 }
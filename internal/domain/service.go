@@ -1,6 +1,13 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/avito/pkg/pagination"
+)
 
 // UserService определяет методы для работы с пользователями
 type UserService interface {
@@ -13,27 +20,178 @@ type UserService interface {
 
 // MerchService определяет методы для работы с мерчем
 type MerchService interface {
-	List(ctx context.Context, page, pageSize int) ([]*Merch, error)
+	List(ctx context.Context, filter MerchFilter, params pagination.Params) (*pagination.Page[*Merch], error)
 	GetByID(ctx context.Context, id int64) (*Merch, error)
-	Buy(ctx context.Context, userID, merchID int64, quantity int) error
-	GetUserPurchases(ctx context.Context, userID int64) ([]*PurchaseResponse, error)
+	// Buy покупает товар, variantID обязателен для товаров с вариантами
+	Buy(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) (*Purchase, error)
+	GetUserPurchases(ctx context.Context, userID int64, params pagination.Params) (*pagination.Page[*PurchaseResponse], error)
+	// UploadImage заменяет изображение товара и создает миниатюру
+	UploadImage(ctx context.Context, merchID int64, r io.Reader) (*Merch, error)
+	DeleteImage(ctx context.Context, merchID int64) error
+	// SetVariantStock меняет остаток варианта и оповещает ожидающих при поступлении
+	SetVariantStock(ctx context.Context, variantID int64, stock int) (*MerchVariant, error)
 }
 
 // TransactionService определяет методы для работы с транзакциями
 type TransactionService interface {
-	Transfer(ctx context.Context, fromUserID, toUserID int64, amount int64, description string) error
-	GetUserTransactions(ctx context.Context, userID int64) ([]*Transaction, error)
+	Transfer(ctx context.Context, fromUserID, toUserID int64, amount int64, description string, kudos KudosOptions) (*Transaction, error)
+	GetUserTransactions(ctx context.Context, userID int64, filter TransactionFilter, params pagination.Params) (*pagination.Page[*Transaction], error)
+	// Adjust начисляет или списывает монеты пользователю от имени администратора через казначейство
+	Adjust(ctx context.Context, adminID int64, adjustment BalanceAdjustment) (*Transaction, error)
+}
+
+// KudosService определяет методы для ленты и рейтинга публичных благодарностей
+type KudosService interface {
+	Feed(ctx context.Context, category KudosCategory, params pagination.Params) (*pagination.Page[*Kudos], error)
+	Leaderboard(ctx context.Context, period LeaderboardPeriod, category KudosCategory, limit int) ([]*LeaderboardEntry, error)
+}
+
+// AnalyticsService определяет методы аналитики экономики магазина для администраторов
+type AnalyticsService interface {
+	TopSpenders(ctx context.Context, r AnalyticsRange) ([]*UserTotal, error)
+	TopReceivers(ctx context.Context, r AnalyticsRange) ([]*UserTotal, error)
+	TopMerch(ctx context.Context, r AnalyticsRange) ([]*MerchTotal, error)
+	Economy(ctx context.Context, r AnalyticsRange) (*EconomySummary, error)
+	// DailyVolumes берет данные из периодически обновляемой витрины
+	DailyVolumes(ctx context.Context, r AnalyticsRange) ([]*DailyVolume, error)
+	// Refresh обновляет витрину дневных объемов
+	Refresh(ctx context.Context) error
+}
+
+// CartService определяет методы для работы с корзиной
+type CartService interface {
+	Get(ctx context.Context, userID int64) (*Cart, error)
+	AddItem(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) error
+	UpdateItem(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) error
+	RemoveItem(ctx context.Context, userID, merchID int64, variantID *int64) error
+	Checkout(ctx context.Context, userID int64, delivery DeliveryDetails) (*Order, error)
+}
+
+// OrderService определяет методы для работы с заказами
+type OrderService interface {
+	List(ctx context.Context, userID int64) ([]*Order, error)
+	GetByID(ctx context.Context, userID, orderID int64) (*Order, error)
+	UpdateDelivery(ctx context.Context, userID, orderID int64, delivery DeliveryDetails) error
+	// ListAll и UpdateStatus предназначены для администраторов
+	ListAll(ctx context.Context, status OrderStatus, page, pageSize int) ([]*Order, error)
+	UpdateStatus(ctx context.Context, orderID int64, status OrderStatus) (*Order, error)
+}
+
+// WishlistService определяет методы для работы со списком желаний
+type WishlistService interface {
+	List(ctx context.Context, userID int64) ([]*WishlistItem, error)
+	Add(ctx context.Context, userID, merchID int64, variantID *int64) error
+	Remove(ctx context.Context, userID, merchID int64, variantID *int64) error
+	// NotifyAffordable уведомляет о товарах, на которые стало хватать монет после пополнения
+	NotifyAffordable(ctx context.Context, userID, oldBalance, newBalance int64) error
+	// NotifyRestocked уведомляет ожидающих о поступлении вариантов, остаток которых вырос с нуля
+	NotifyRestocked(ctx context.Context, variantIDs []int64) error
+}
+
+// NotificationService определяет методы для работы с уведомлениями
+type NotificationService interface {
+	Notify(ctx context.Context, userID int64, kind NotificationType, message string, payload map[string]any) error
+	List(ctx context.Context, userID int64, unreadOnly bool, params pagination.Params) (*NotificationPage, error)
+	UnreadCount(ctx context.Context, userID int64) (int64, error)
+	MarkRead(ctx context.Context, userID, id int64) error
+	MarkAllRead(ctx context.Context, userID int64) (int64, error)
+}
+
+// WebhookService определяет методы для управления вебхуками.
+// Publish ставит событие outbox в очередь доставки подписчикам.
+type WebhookService interface {
+	Create(ctx context.Context, url string, eventTypes []string, secret string) (*WebhookSubscription, error)
+	List(ctx context.Context) ([]*WebhookSubscription, error)
+	Delete(ctx context.Context, id int64) error
+	Deliveries(ctx context.Context, subscriptionID int64, status WebhookDeliveryStatus, params pagination.Params) (*pagination.Page[*WebhookDelivery], error)
+	Redeliver(ctx context.Context, deliveryID int64) (*WebhookDelivery, error)
+	Publish(ctx context.Context, event *OutboxEvent) error
+}
+
+// AuditService определяет методы для работы с журналом аудита.
+// Исполнитель, request id, IP и user agent берутся из контекста запроса.
+type AuditService interface {
+	Record(ctx context.Context, action AuditAction, targetType, targetID string, before, after any) error
+	List(ctx context.Context, filter AuditFilter, params pagination.Params) (*pagination.Page[*AuditEntry], error)
+	Verify(ctx context.Context) (*AuditVerification, error)
+}
+
+// AllowanceService определяет методы для регулярных начислений и сгорания монет
+type AllowanceService interface {
+	CreateSchedule(ctx context.Context, name string, amount int64, dayOfMonth int) (*GrantSchedule, error)
+	ListSchedules(ctx context.Context) ([]*GrantSchedule, error)
+	DeactivateSchedule(ctx context.Context, id int64) error
+	Runs(ctx context.Context, params pagination.Params) (*pagination.Page[*AllowanceRun], error)
+	// RunDue проводит наступившие начисления и сгорание, уже проведенные периоды пропускаются
+	RunDue(ctx context.Context, now time.Time) error
+	// Outlook возвращает ближайшие начисления и сгорание монет пользователя
+	Outlook(ctx context.Context, userID int64, now time.Time) (*AllowanceOutlook, error)
+}
+
+// BusinessMetrics принимает бизнес-события для метрик мониторинга
+type BusinessMetrics interface {
+	// PurchaseCompleted учитывает оформленную покупку, channel - buy или cart
+	PurchaseCompleted(channel string, coins int64)
+	TransferCompleted(kind TransactionKind, coins int64)
+	// TransferFailed учитывает отклоненный перевод, reason - код ошибки
+	TransferFailed(reason string)
+	AuthFailed(reason string)
+}
+
+// HealthChecker проверяет, может ли реплика обслуживать запросы
+type HealthChecker interface {
+	Ready(ctx context.Context) *HealthReport
+}
+
+// EventBus доставляет события подключенным клиентам пользователя,
+// в том числе клиентам, подключенным к другим репликам API
+type EventBus interface {
+	Publish(ctx context.Context, event *Event) error
+	// Subscribe возвращает канал событий пользователя и функцию отписки
+	Subscribe(userID int64) (<-chan *Event, func())
+}
+
+// StatementWriter принимает выписку по мере ее формирования
+type StatementWriter interface {
+	Begin(from, to time.Time, openingBalance int64) error
+	Entry(entry *StatementEntry) error
+	End(closingBalance int64) error
+}
+
+// StatementService формирует выписку по монетам пользователя
+type StatementService interface {
+	// Export передает в writer все движения за [from, to) с нарастающим балансом.
+	// Нулевые from и to означают дату регистрации пользователя и текущий момент.
+	Export(ctx context.Context, userID int64, from, to time.Time, w StatementWriter) error
 }
 
 // Services объединяет все сервисы приложения
 type Services struct {
-	User        UserService
-	Merch       MerchService
-	Transaction TransactionService
+	User         UserService
+	Merch        MerchService
+	Transaction  TransactionService
+	Cart         CartService
+	Order        OrderService
+	Statement    StatementService
+	Wishlist     WishlistService
+	Notification NotificationService
+	Webhook      WebhookService
+	Audit        AuditService
+	Allowance    AllowanceService
+	Kudos        KudosService
+	Analytics    AnalyticsService
+	Events       EventBus
+	Metrics      BusinessMetrics
 }
 
 // Deps содержит зависимости для сервисов
 type Deps struct {
-	Repos       *Repositories
-	TokenSecret string
+	Repos        *Repositories
+	Blobs        BlobStore
+	Events       EventBus
+	TokenSecret  string
+	MaxImageSize int64
+	CoinExpiry   ExpiryRule
+	Metrics      BusinessMetrics
+	Logger       *slog.Logger
 }
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/avito/internal/domain"
+)
+
+// DefaultBufferSize - сколько событий может накопиться у медленного клиента
+// до того, как новые события для него начнут отбрасываться
+const DefaultBufferSize = 16
+
+// Broker раздает события подписчикам внутри одного процесса
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[int64]map[chan *domain.Event]struct{}
+	bufferSize  int
+	closed      bool
+}
+
+// NewBroker создает новый экземпляр Broker
+func NewBroker(bufferSize int) *Broker {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	return &Broker{
+		subscribers: make(map[int64]map[chan *domain.Event]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Publish доставляет событие подписчикам этого процесса
+func (b *Broker) Publish(_ context.Context, event *domain.Event) error {
+	b.Dispatch(event)
+	return nil
+}
+
+// Dispatch отправляет событие всем подпискам пользователя без блокировки:
+// если буфер клиента заполнен, событие для него пропускается
+func (b *Broker) Dispatch(event *domain.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers[event.UserID] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe подписывает клиента на события пользователя.
+// Функция отписки закрывает канал и может вызываться повторно.
+func (b *Broker) Subscribe(userID int64) (<-chan *domain.Event, func()) {
+	ch := make(chan *domain.Event, b.bufferSize)
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan *domain.Event]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			// После Close канал уже закрыт и удален из подписок
+			if _, ok := b.subscribers[userID][ch]; !ok {
+				return
+			}
+			delete(b.subscribers[userID], ch)
+			if len(b.subscribers[userID]) == 0 {
+				delete(b.subscribers, userID)
+			}
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+// Close закрывает каналы всех подписчиков, чтобы долгие соединения завершились
+// при остановке сервера. Новые подписки после этого сразу получают закрытый канал.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for userID, channels := range b.subscribers {
+		for ch := range channels {
+			close(ch)
+		}
+		delete(b.subscribers, userID)
+	}
+}
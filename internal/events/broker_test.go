@@ -0,0 +1,94 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/avito/internal/domain"
+)
+
+func TestBrokerFanOut(t *testing.T) {
+	b := NewBroker(4)
+
+	first, unsubscribeFirst := b.Subscribe(1)
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := b.Subscribe(1)
+	defer unsubscribeSecond()
+	other, unsubscribeOther := b.Subscribe(2)
+	defer unsubscribeOther()
+
+	event := &domain.Event{Type: domain.EventBalanceChanged, UserID: 1}
+	if err := b.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	// Событие получают все подключения пользователя
+	for i, ch := range []<-chan *domain.Event{first, second} {
+		select {
+		case got := <-ch:
+			if got != event {
+				t.Errorf("subscriber %d got %+v, want %+v", i, got, event)
+			}
+		default:
+			t.Errorf("subscriber %d did not receive event", i)
+		}
+	}
+
+	// и только они
+	select {
+	case got := <-other:
+		t.Errorf("other user received %+v", got)
+	default:
+	}
+}
+
+func TestBrokerDropsEventsForSlowSubscriber(t *testing.T) {
+	const bufferSize = 2
+	b := NewBroker(bufferSize)
+
+	slow, unsubscribeSlow := b.Subscribe(1)
+	defer unsubscribeSlow()
+	fast, unsubscribeFast := b.Subscribe(1)
+	defer unsubscribeFast()
+
+	// Быстрый клиент вычитывает каждое событие, медленный - ни одного.
+	// Dispatch не должен блокироваться на заполненном буфере.
+	for i := 0; i < bufferSize+3; i++ {
+		b.Dispatch(&domain.Event{Type: domain.EventTransferReceived, UserID: 1, Data: []byte{byte('0' + i)}})
+
+		select {
+		case <-fast:
+		default:
+			t.Fatalf("fast subscriber missed event %d", i)
+		}
+	}
+
+	if got := len(slow); got != bufferSize {
+		t.Fatalf("slow subscriber buffered %d events, want %d", got, bufferSize)
+	}
+	// В буфере остаются самые ранние события, более поздние отброшены
+	for i := 0; i < bufferSize; i++ {
+		if got := (<-slow).Data; string(got) != string(rune('0'+i)) {
+			t.Errorf("buffered event %d = %s, want %c", i, got, '0'+i)
+		}
+	}
+}
+
+func TestBrokerUnsubscribe(t *testing.T) {
+	b := NewBroker(0)
+
+	ch, unsubscribe := b.Subscribe(1)
+	unsubscribe()
+	// Повторная отписка не паникует на закрытом канале
+	unsubscribe()
+
+	if _, ok := <-ch; ok {
+		t.Fatal("channel is open after unsubscribe")
+	}
+	if _, ok := b.subscribers[1]; ok {
+		t.Error("user without subscriptions is kept in broker")
+	}
+
+	// Публикация после отписки не пишет в закрытый канал
+	b.Dispatch(&domain.Event{Type: domain.EventBalanceChanged, UserID: 1})
+}
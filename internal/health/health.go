@@ -0,0 +1,82 @@
+// Package health собирает проверки готовности реплики: база, миграции и фоновые обработчики.
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+// CheckFunc проверяет одну зависимость и возвращает причину неготовности
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker выполняет проверки с общим таймаутом. Причины отказа пишутся в журнал,
+// а наружу отдается только имя проверки, поскольку пробы доступны без авторизации.
+type Checker struct {
+	checks  []check
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// New создает новый экземпляр Checker
+func New(timeout time.Duration, logger *slog.Logger) *Checker {
+	return &Checker{
+		timeout: timeout,
+		logger:  logger,
+	}
+}
+
+// Add добавляет проверку. Проверки добавляются при запуске, до начала обслуживания запросов.
+func (c *Checker) Add(name string, fn CheckFunc) {
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+func (c *Checker) Ready(ctx context.Context) *domain.HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	report := &domain.HealthReport{
+		Ready:  true,
+		Checks: make(map[string]string, len(c.checks)),
+	}
+	for _, check := range c.checks {
+		if err := check.fn(ctx); err != nil {
+			c.logger.WarnContext(ctx, "readiness check failed", "check", check.name, "error", err)
+			report.Ready = false
+			report.Checks[check.name] = "fail"
+			continue
+		}
+		report.Checks[check.name] = "ok"
+	}
+
+	return report
+}
+
+// Migrations сверяет версию схемы базы с версией последней миграции сборки
+func Migrations(current func(ctx context.Context) (int64, error), expected int64) CheckFunc {
+	return func(ctx context.Context) error {
+		version, err := current(ctx)
+		if err != nil {
+			return err
+		}
+		if version != expected {
+			return fmt.Errorf("database schema at version %d, expected %d", version, expected)
+		}
+		return nil
+	}
+}
+
+// Func оборачивает проверку, которой не нужен контекст, например состояние фонового обработчика
+func Func(fn func() error) CheckFunc {
+	return func(context.Context) error {
+		return fn()
+	}
+}
@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+func TestCheckerReady(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	// Текст ошибки может содержать адрес базы и не должен попадать в ответ пробы
+	failing := func(context.Context) error { return errors.New("dial tcp 10.0.0.5:5432: connection refused") }
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name   string
+		checks map[string]CheckFunc
+		want   *domain.HealthReport
+	}{
+		{
+			name:   "all ok",
+			checks: map[string]CheckFunc{"database": ok, "migrations": ok},
+			want:   &domain.HealthReport{Ready: true, Checks: map[string]string{"database": "ok", "migrations": "ok"}},
+		},
+		{
+			name:   "one failing",
+			checks: map[string]CheckFunc{"database": failing, "migrations": ok},
+			want:   &domain.HealthReport{Checks: map[string]string{"database": "fail", "migrations": "ok"}},
+		},
+		{
+			name:   "hanging check times out",
+			checks: map[string]CheckFunc{"database": hanging, "outbox_relay": ok},
+			want:   &domain.HealthReport{Checks: map[string]string{"database": "fail", "outbox_relay": "ok"}},
+		},
+		{
+			name: "no checks",
+			want: &domain.HealthReport{Ready: true, Checks: map[string]string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(50*time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			for name, fn := range tt.checks {
+				c.Add(name, fn)
+			}
+
+			done := make(chan *domain.HealthReport)
+			go func() { done <- c.Ready(context.Background()) }()
+
+			select {
+			case got := <-done:
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Ready() = %+v, want %+v", got, tt.want)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("Ready() did not respect the check timeout")
+			}
+		})
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	errNoTable := errors.New(`relation "goose_db_version" does not exist`)
+
+	tests := []struct {
+		name     string
+		current  int64
+		err      error
+		expected int64
+		wantErr  bool
+	}{
+		{name: "up to date", current: 18, expected: 18},
+		{name: "pending migrations", current: 17, expected: 18, wantErr: true},
+		// Схема новее сборки: реплика старой версии не должна принимать трафик
+		{name: "schema ahead of build", current: 19, expected: 18, wantErr: true},
+		{name: "version unavailable", err: errNoTable, expected: 18, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := Migrations(func(context.Context) (int64, error) { return tt.current, tt.err }, tt.expected)
+
+			err := check(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("check() error = %v, want error: %t", err, tt.wantErr)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("check() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
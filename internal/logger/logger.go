@@ -0,0 +1,47 @@
+// Package logger создает структурированный JSON-логгер, дополняющий записи сведениями о запросе из контекста.
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/avito/internal/domain"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New создает логгер уровня level (debug, info, warn, error), пишущий JSON в w
+func New(w io.Writer, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.ToUpper(level))); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: lvl})
+	return slog.New(&contextHandler{Handler: handler}), nil
+}
+
+// contextHandler добавляет к записям, сделанным с контекстом запроса, его id и id трассы
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := domain.RequestMetaFrom(ctx).RequestID; requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,136 @@
+// Package metrics собирает метрики Prometheus: HTTP-запросы, пул соединений с базой и бизнес-события.
+package metrics
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "merch"
+
+// Metrics хранит собственный реестр, чтобы в выдачу не попадали метрики сторонних пакетов
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	purchases       *prometheus.CounterVec
+	transfers       *prometheus.CounterVec
+	transfersFailed *prometheus.CounterVec
+	coinsMoved      *prometheus.CounterVec
+	authFailures    *prometheus.CounterVec
+}
+
+// New создает реестр с метриками процесса и Go runtime
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		purchases: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "purchases_total",
+			Help:      "Completed purchases by channel (buy or cart).",
+		}, []string{"channel"}),
+		transfers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfers_total",
+			Help:      "Completed coin transfers by kind.",
+		}, []string{"kind"}),
+		transfersFailed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfers_failed_total",
+			Help:      "Rejected coin transfers by reason.",
+		}, []string{"reason"}),
+		coinsMoved: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "coins_moved_total",
+			Help:      "Coins moved by operation.",
+		}, []string{"operation"}),
+		authFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_failures_total",
+			Help:      "Failed logins and rejected tokens by reason.",
+		}, []string{"reason"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.purchases,
+		m.transfers,
+		m.transfersFailed,
+		m.coinsMoved,
+		m.authFailures,
+	)
+
+	return m
+}
+
+// RegisterDB добавляет статистику пула соединений
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler отдает метрики в формате Prometheus.
+// Непустой token требуется в заголовке Authorization: Bearer <token>.
+func (m *Metrics) Handler(token string) http.Handler {
+	handler := promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+	if token == "" {
+		return handler
+	}
+
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// ObserveHTTP учитывает обработанный HTTP-запрос
+func (m *Metrics) ObserveHTTP(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+func (m *Metrics) PurchaseCompleted(channel string, coins int64) {
+	m.purchases.WithLabelValues(channel).Inc()
+	m.coinsMoved.WithLabelValues("purchase").Add(float64(coins))
+}
+
+func (m *Metrics) TransferCompleted(kind domain.TransactionKind, coins int64) {
+	m.transfers.WithLabelValues(string(kind)).Inc()
+	m.coinsMoved.WithLabelValues(string(kind)).Add(float64(coins))
+}
+
+func (m *Metrics) TransferFailed(reason string) {
+	m.transfersFailed.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) AuthFailed(reason string) {
+	m.authFailures.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,52 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/avito/internal/domain"
+)
+
+// FilePublisher дописывает события в файл в формате JSON Lines
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFilePublisher открывает файл на дозапись, создавая его при необходимости
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox file: %w", err)
+	}
+
+	return &FilePublisher{file: file}, nil
+}
+
+func (p *FilePublisher) Publish(_ context.Context, event *domain.OutboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+	// Событие считается опубликованным только после сброса на диск
+	if err := p.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync outbox file: %w", err)
+	}
+
+	return nil
+}
+
+// Close закрывает файл
+func (p *FilePublisher) Close() error {
+	return p.file.Close()
+}
@@ -0,0 +1,29 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+
+	"github.com/avito/internal/domain"
+)
+
+// MultiPublisher передает событие всем публикаторам. Если хотя бы один вернул ошибку,
+// событие будет отправлено повторно всем, поэтому получатели должны дедуплицировать по id.
+type MultiPublisher struct {
+	publishers []domain.Publisher
+}
+
+// NewMultiPublisher создает новый экземпляр MultiPublisher
+func NewMultiPublisher(publishers ...domain.Publisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+func (p *MultiPublisher) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,39 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/avito/internal/domain"
+)
+
+// StreamPublisher пишет события построчно в формате JSON
+type StreamPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewStdoutPublisher создает публикатор в стандартный вывод
+func NewStdoutPublisher() *StreamPublisher {
+	return &StreamPublisher{w: os.Stdout}
+}
+
+func (p *StreamPublisher) Publish(_ context.Context, event *domain.OutboxEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return nil
+}
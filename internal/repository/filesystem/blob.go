@@ -0,0 +1,88 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// BlobStore хранит файлы в локальном каталоге
+type BlobStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewBlobStore создает хранилище в каталоге dir, файлы доступны по префиксу baseURL
+func NewBlobStore(dir, baseURL string) (*BlobStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage dir: %w", err)
+	}
+
+	return &BlobStore{
+		dir:     dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}, nil
+}
+
+// Put записывает файл атомарно через временный файл
+func (s *BlobStore) Put(ctx context.Context, key string, r io.Reader) error {
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return fmt.Errorf("failed to create blob dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("failed to save blob: %w", err)
+	}
+
+	return nil
+}
+
+func (s *BlobStore) Delete(ctx context.Context, key string) error {
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(dst); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+
+	return nil
+}
+
+func (s *BlobStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// path защищает от выхода за пределы каталога хранилища
+func (s *BlobStore) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || clean != "/"+key {
+		return "", fmt.Errorf("invalid blob key: %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
@@ -0,0 +1,63 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestBlobStorePath(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewBlobStore(dir, "/static/")
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{name: "file", key: "image.png", want: filepath.Join(dir, "image.png")},
+		{name: "nested", key: "merch/1/thumb.jpg", want: filepath.Join(dir, "merch", "1", "thumb.jpg")},
+		{name: "empty", key: "", wantErr: true},
+		{name: "root", key: "/", wantErr: true},
+		{name: "parent", key: "../secret", wantErr: true},
+		{name: "nested parent", key: "merch/../../secret", wantErr: true},
+		{name: "parent inside store", key: "merch/../image.png", wantErr: true},
+		{name: "absolute", key: "/etc/passwd", wantErr: true},
+		{name: "dot segment", key: "./image.png", wantErr: true},
+		{name: "double slash", key: "merch//image.png", wantErr: true},
+		{name: "trailing slash", key: "merch/", wantErr: true},
+		{name: "dot dot only", key: "..", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.path(tt.key)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("path(%q) = %q, want error", tt.key, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("path(%q): %v", tt.key, err)
+			}
+			if got != tt.want {
+				t.Errorf("path(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlobStoreURL(t *testing.T) {
+	store, err := NewBlobStore(t.TempDir(), "/static/")
+	if err != nil {
+		t.Fatalf("NewBlobStore: %v", err)
+	}
+
+	if got, want := store.URL("merch/1.png"), "/static/merch/1.png"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+}
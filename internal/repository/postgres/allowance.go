@@ -0,0 +1,325 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+	"github.com/jmoiron/sqlx"
+)
+
+// expiringCoins считает для каждого пользователя монеты, полученные до границы $1.
+// Монеты тратятся в порядке поступления, поэтому на руках в первую очередь остаются
+// полученные после границы, а сгорает остаток баланса сверх них. Пользователи, созданные
+// после границы, ничего не теряют: стартовый баланс старых пользователей не отражен в переводах.
+// Возвраты за отмененные заказы проводятся переводами от казначейства, поэтому возврат
+// после границы тоже считается свежими монетами.
+const expiringCoins = `
+	SELECT u.id AS user_id,
+		   GREATEST(0, u.balance - COALESCE((
+			   SELECT SUM(t.amount) FROM transactions t
+			   WHERE t.to_user_id = u.id AND t.created_at >= $1
+		   ), 0)) AS amount
+	FROM users u
+	WHERE NOT u.is_system AND u.created_at < $1`
+
+// transferEventPayload строит payload события transfer.completed для строк,
+// вставленных в transactions пакетно, в том же формате, что и insertTransferEvent
+const transferEventPayload = `jsonb_build_object(
+	'transaction_id', id, 'from_user_id', from_user_id, 'to_user_id', to_user_id,
+	'amount', amount, 'description', description, 'kind', kind,
+	'reason_code', reason_code, 'created_at', created_at)`
+
+type AllowanceRepository struct {
+	*Repository
+}
+
+func NewAllowanceRepository(repo *Repository) *AllowanceRepository {
+	return &AllowanceRepository{Repository: repo}
+}
+
+func (r *AllowanceRepository) CreateSchedule(ctx context.Context, schedule *domain.GrantSchedule) error {
+	query := `
+		INSERT INTO grant_schedules (name, amount, day_of_month, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		schedule.Name,
+		schedule.Amount,
+		schedule.DayOfMonth,
+		schedule.Active,
+	).Scan(&schedule.ID, &schedule.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create grant schedule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AllowanceRepository) GetSchedules(ctx context.Context, activeOnly bool) ([]*domain.GrantSchedule, error) {
+	schedules := []*domain.GrantSchedule{}
+
+	query := `
+		SELECT id, name, amount, day_of_month, active, created_at
+		FROM grant_schedules
+		WHERE active OR NOT $1
+		ORDER BY id`
+
+	err := r.db.SelectContext(ctx, &schedules, query, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grant schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+func (r *AllowanceRepository) DeactivateSchedule(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE grant_schedules SET active = FALSE WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate grant schedule: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrGrantScheduleNotFound
+	}
+
+	return nil
+}
+
+func (r *AllowanceRepository) GetRuns(ctx context.Context, params pagination.Params) (*pagination.Page[*domain.AllowanceRun], error) {
+	cursor, err := decodeTimeCursor(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if params.IsFirst() {
+		total = new(int64)
+		if err := r.db.GetContext(ctx, total, `SELECT COUNT(*) FROM allowance_runs`); err != nil {
+			return nil, fmt.Errorf("failed to count allowance runs: %w", err)
+		}
+	}
+
+	var runs []*domain.AllowanceRun
+
+	query := `
+		SELECT id, kind, schedule_id, period, users_count, amount, created_at
+		FROM allowance_runs
+		WHERE $1::timestamptz IS NULL OR (created_at, id) < ($1, $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3`
+
+	createdAt, id := cursor.after()
+	err = r.db.SelectContext(ctx, &runs, query, createdAt, id, params.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allowance runs: %w", err)
+	}
+
+	page, err := pagination.NewPage(runs, params.Limit, func(last *domain.AllowanceRun) any {
+		return timeCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	})
+	if err != nil {
+		return nil, err
+	}
+	page.Total = total
+
+	return page, nil
+}
+
+func (r *AllowanceRepository) RunGrant(ctx context.Context, schedule *domain.GrantSchedule, period time.Time) (*domain.AllowanceRun, []domain.UserBalance, error) {
+	var (
+		run      *domain.AllowanceRun
+		balances []domain.UserBalance
+	)
+
+	err := r.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		var err error
+		run, err = startRun(ctx, tx, domain.AllowanceRunGrant, &schedule.ID, period)
+		if err != nil || run == nil {
+			return err
+		}
+
+		treasuryID, err := lockTreasury(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		err = tx.SelectContext(ctx, &balances, `
+			WITH credited AS (
+				UPDATE users
+				SET balance = balance + $2, updated_at = CURRENT_TIMESTAMP
+				WHERE NOT is_system
+				RETURNING id, balance
+			), granted AS (
+				INSERT INTO transactions (from_user_id, to_user_id, amount, description, kind)
+				SELECT $1, id, $2, $3, 'grant' FROM credited
+				RETURNING id, from_user_id, to_user_id, amount, description, kind, reason_code, created_at
+			), published AS (
+				INSERT INTO outbox (event_type, aggregate_type, aggregate_id, payload)
+				SELECT $4, 'transaction', id, `+transferEventPayload+` FROM granted
+			)
+			SELECT id AS user_id, balance FROM credited`,
+			treasuryID, schedule.Amount, schedule.Name, domain.OutboxEventTransferCompleted,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to grant coins: %w", err)
+		}
+
+		run.UsersCount = len(balances)
+		run.Amount = schedule.Amount * int64(len(balances))
+
+		return finishRun(ctx, tx, run, treasuryID, -run.Amount)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return run, balances, nil
+}
+
+func (r *AllowanceRepository) RunExpiry(ctx context.Context, period, cutoff time.Time) (*domain.AllowanceRun, error) {
+	var run *domain.AllowanceRun
+
+	err := r.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		var err error
+		run, err = startRun(ctx, tx, domain.AllowanceRunExpiry, nil, period)
+		if err != nil || run == nil {
+			return err
+		}
+
+		treasuryID, err := lockTreasury(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		// Блокируем пользователей со сгорающими монетами, чтобы одновременные покупки не увели
+		// баланс ниже сгорающей суммы. Остальных не трогаем: новые поступления и траты не могут
+		// сделать нулевую сгорающую сумму положительной.
+		_, err = tx.ExecContext(ctx, `
+			SELECT u.id FROM users u
+			JOIN (`+expiringCoins+`) e ON e.user_id = u.id
+			WHERE e.amount > 0
+			ORDER BY u.id
+			FOR UPDATE OF u`,
+			cutoff,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to lock users: %w", err)
+		}
+
+		description := fmt.Sprintf("Сгорание монет, полученных до %s", cutoff.Format(time.DateOnly))
+		err = tx.QueryRowxContext(ctx, `
+			WITH expiring AS (
+				SELECT user_id, amount FROM (`+expiringCoins+`) e
+				WHERE amount > 0
+			), debited AS (
+				UPDATE users u
+				SET balance = u.balance - e.amount, updated_at = CURRENT_TIMESTAMP
+				FROM expiring e
+				WHERE u.id = e.user_id
+				RETURNING u.id
+			), expired AS (
+				INSERT INTO transactions (from_user_id, to_user_id, amount, description, kind)
+				SELECT user_id, $2, amount, $3, 'expiry' FROM expiring
+				RETURNING id, from_user_id, to_user_id, amount, description, kind, reason_code, created_at
+			), published AS (
+				INSERT INTO outbox (event_type, aggregate_type, aggregate_id, payload)
+				SELECT $4, 'transaction', id, `+transferEventPayload+` FROM expired
+			)
+			SELECT COUNT(*), COALESCE(SUM(amount), 0) FROM expired`,
+			cutoff, treasuryID, description, domain.OutboxEventTransferCompleted,
+		).Scan(&run.UsersCount, &run.Amount)
+		if err != nil {
+			return fmt.Errorf("failed to expire coins: %w", err)
+		}
+
+		return finishRun(ctx, tx, run, treasuryID, run.Amount)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+func (r *AllowanceRepository) ExpiringAmount(ctx context.Context, userID int64, cutoff time.Time) (int64, error) {
+	var amount int64
+
+	query := `SELECT COALESCE(SUM(amount), 0) FROM (` + expiringCoins + `) e WHERE user_id = $2`
+
+	err := r.db.GetContext(ctx, &amount, query, cutoff, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expiring coins: %w", err)
+	}
+
+	return amount, nil
+}
+
+// startRun регистрирует запуск за период. Возвращает nil, если период уже проведен.
+func startRun(ctx context.Context, tx *sqlx.Tx, kind domain.AllowanceRunKind, scheduleID *int64, period time.Time) (*domain.AllowanceRun, error) {
+	run := &domain.AllowanceRun{Kind: kind, ScheduleID: scheduleID, Period: period}
+
+	err := tx.QueryRowxContext(ctx, `
+		INSERT INTO allowance_runs (kind, schedule_id, period)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (kind, COALESCE(schedule_id, 0), period) DO NOTHING
+		RETURNING id, created_at`,
+		kind, scheduleID, period,
+	).Scan(&run.ID, &run.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to start allowance run: %w", err)
+	}
+
+	return run, nil
+}
+
+// finishRun сохраняет итоги запуска и отражает их на балансе казначейства
+func finishRun(ctx context.Context, tx *sqlx.Tx, run *domain.AllowanceRun, treasuryID, treasuryChange int64) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE users
+		SET balance = balance + $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2`,
+		treasuryChange, treasuryID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update treasury balance: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE allowance_runs SET users_count = $1, amount = $2 WHERE id = $3`,
+		run.UsersCount, run.Amount, run.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish allowance run: %w", err)
+	}
+
+	return nil
+}
+
+func lockTreasury(ctx context.Context, tx *sqlx.Tx) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `
+		SELECT id FROM users
+		WHERE username = $1 AND is_system
+		FOR UPDATE`,
+		domain.TreasuryUsername,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock treasury: %w", err)
+	}
+
+	return id, nil
+}
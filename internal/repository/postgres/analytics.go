@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/avito/internal/domain"
+)
+
+// purchasedInRange - покупки неотмененных заказов в периоде [$1, $2)
+const purchasedInRange = `
+	FROM purchases p
+	JOIN orders o ON p.order_id = o.id
+	WHERE o.status <> 'cancelled'
+	  AND ($1::timestamptz IS NULL OR p.created_at >= $1)
+	  AND ($2::timestamptz IS NULL OR p.created_at < $2)`
+
+// transferredInRange - переводы между пользователями в периоде [$1, $2)
+const transferredInRange = `
+	FROM transactions t
+	WHERE t.kind = 'transfer'
+	  AND ($1::timestamptz IS NULL OR t.created_at >= $1)
+	  AND ($2::timestamptz IS NULL OR t.created_at < $2)`
+
+type AnalyticsRepository struct {
+	*Repository
+}
+
+func NewAnalyticsRepository(repo *Repository) *AnalyticsRepository {
+	return &AnalyticsRepository{Repository: repo}
+}
+
+func (r *AnalyticsRepository) TopSpenders(ctx context.Context, rng domain.AnalyticsRange) ([]*domain.UserTotal, error) {
+	totals := []*domain.UserTotal{}
+
+	query := `
+		SELECT u.id AS user_id, u.username, s.amount, s.count
+		FROM (
+			SELECT p.user_id, SUM(p.total_price) AS amount, COUNT(DISTINCT p.order_id) AS count` + purchasedInRange + `
+			GROUP BY p.user_id
+		) s
+		JOIN users u ON s.user_id = u.id
+		ORDER BY s.amount DESC, u.username
+		LIMIT $3`
+
+	err := r.db.SelectContext(ctx, &totals, query, rng.From, rng.To, rng.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top spenders: %w", err)
+	}
+
+	return totals, nil
+}
+
+func (r *AnalyticsRepository) TopReceivers(ctx context.Context, rng domain.AnalyticsRange) ([]*domain.UserTotal, error) {
+	totals := []*domain.UserTotal{}
+
+	query := `
+		SELECT u.id AS user_id, u.username, s.amount, s.count
+		FROM (
+			SELECT t.to_user_id AS user_id, SUM(t.amount) AS amount, COUNT(*) AS count` + transferredInRange + `
+			GROUP BY t.to_user_id
+		) s
+		JOIN users u ON s.user_id = u.id
+		ORDER BY s.amount DESC, u.username
+		LIMIT $3`
+
+	err := r.db.SelectContext(ctx, &totals, query, rng.From, rng.To, rng.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top receivers: %w", err)
+	}
+
+	return totals, nil
+}
+
+func (r *AnalyticsRepository) TopMerch(ctx context.Context, rng domain.AnalyticsRange) ([]*domain.MerchTotal, error) {
+	totals := []*domain.MerchTotal{}
+
+	query := `
+		SELECT m.id AS merch_id, m.name, s.quantity, s.buyers, s.revenue
+		FROM (
+			SELECT p.merch_id, SUM(p.quantity) AS quantity, COUNT(DISTINCT p.user_id) AS buyers,
+				   SUM(p.total_price) AS revenue` + purchasedInRange + `
+			GROUP BY p.merch_id
+		) s
+		JOIN merch m ON s.merch_id = m.id
+		ORDER BY s.quantity DESC, m.name
+		LIMIT $3`
+
+	err := r.db.SelectContext(ctx, &totals, query, rng.From, rng.To, rng.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top merch: %w", err)
+	}
+
+	return totals, nil
+}
+
+func (r *AnalyticsRepository) Economy(ctx context.Context, rng domain.AnalyticsRange) (*domain.EconomySummary, error) {
+	summary := &domain.EconomySummary{}
+
+	// Денежная масса - текущее состояние, объемы - за период.
+	// Выпущено столько, сколько казначейство ушло в минус, без возвратов за отмененные заказы:
+	// возврат не выпускает новые монеты, а отменяет трату, поэтому issued = circulation + spent.
+	query := `
+		SELECT
+			(SELECT COALESCE(SUM(balance), 0) FROM users WHERE NOT is_system) AS circulation,
+			(SELECT COALESCE(-SUM(balance), 0) FROM users WHERE is_system) -
+			(SELECT COALESCE(SUM(amount), 0) FROM transactions WHERE kind = 'refund') AS issued,
+			(SELECT COALESCE(SUM(o.total_price), 0) FROM orders o WHERE o.status <> 'cancelled') AS spent,
+			(SELECT COALESCE(SUM(t.amount), 0)` + transferredInRange + `) AS transfer_volume,
+			(SELECT COALESCE(SUM(p.total_price), 0)` + purchasedInRange + `) AS purchase_volume`
+
+	err := r.db.GetContext(ctx, summary, query, rng.From, rng.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get economy summary: %w", err)
+	}
+
+	return summary, nil
+}
+
+func (r *AnalyticsRepository) DailyVolumes(ctx context.Context, rng domain.AnalyticsRange) ([]*domain.DailyVolume, error) {
+	volumes := []*domain.DailyVolume{}
+
+	query := `
+		SELECT day, purchases_count, purchased_items, purchase_volume, transfers_count, transfer_volume
+		FROM analytics_daily_volumes
+		WHERE ($1::timestamptz IS NULL OR day >= ($1::timestamptz AT TIME ZONE 'UTC')::date)
+		  AND ($2::timestamptz IS NULL OR day < ($2::timestamptz AT TIME ZONE 'UTC')::date)
+		ORDER BY day`
+
+	err := r.db.SelectContext(ctx, &volumes, query, rng.From, rng.To)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily volumes: %w", err)
+	}
+
+	return volumes, nil
+}
+
+func (r *AnalyticsRepository) RefreshDailyVolumes(ctx context.Context) error {
+	// CONCURRENTLY не блокирует чтение витрины на время обновления
+	_, err := r.db.ExecContext(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY analytics_daily_volumes`)
+	if err != nil {
+		return fmt.Errorf("failed to refresh daily volumes: %w", err)
+	}
+
+	return nil
+}
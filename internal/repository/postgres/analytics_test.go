@@ -0,0 +1,101 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/avito/internal/domain"
+)
+
+func TestAnalyticsRepositoryRefreshDailyVolumes(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	transactions, carts, orders := NewTransactionRepository(repo), NewCartRepository(repo), NewOrderRepository(repo)
+	analytics := NewAnalyticsRepository(repo)
+
+	treasury, err := NewUserRepository(repo).GetByUsername(ctx, domain.TreasuryUsername)
+	if err != nil {
+		t.Fatalf("failed to get treasury: %v", err)
+	}
+	alice := createTestUser(t, repo, "alice", 0)
+	bob := createTestUser(t, repo, "bob", 0)
+
+	// Монеты выпускает казначейство, поэтому выпуск сходится с оборотом и тратами
+	for _, user := range []*domain.User{alice, bob} {
+		grant := &domain.Transaction{FromUserID: treasury.ID, ToUserID: user.ID, Amount: 1000, Kind: domain.TransactionKindGrant}
+		if err := transactions.TransferMoney(ctx, grant); err != nil {
+			t.Fatalf("TransferMoney grant: %v", err)
+		}
+	}
+
+	cup := createTestMerch(t, repo, "test-cup", 20)
+	hoody := createTestMerch(t, repo, "test-hoody", 300)
+	checkout := func(user *domain.User, merch *domain.Merch, quantity int) *domain.Order {
+		t.Helper()
+		if err := carts.AddItem(ctx, user.ID, merch.ID, nil, quantity); err != nil {
+			t.Fatalf("AddItem: %v", err)
+		}
+		order, err := orders.Checkout(ctx, user.ID, testDelivery())
+		if err != nil {
+			t.Fatalf("Checkout: %v", err)
+		}
+		return order
+	}
+	checkout(alice, cup, 3)
+	cancelled := checkout(bob, hoody, 1)
+	if _, _, err := orders.UpdateStatus(ctx, cancelled.ID, domain.OrderStatusCancelled); err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+
+	if err := transactions.TransferMoney(ctx, &domain.Transaction{FromUserID: alice.ID, ToUserID: bob.ID, Amount: 100}); err != nil {
+		t.Fatalf("TransferMoney: %v", err)
+	}
+
+	// До обновления витрина отражает состояние на момент миграции
+	volumes, err := analytics.DailyVolumes(ctx, domain.AnalyticsRange{})
+	if err != nil {
+		t.Fatalf("DailyVolumes: %v", err)
+	}
+	if len(volumes) != 0 {
+		t.Fatalf("volumes before refresh = %+v, want none", volumes)
+	}
+
+	if err := analytics.RefreshDailyVolumes(ctx); err != nil {
+		t.Fatalf("RefreshDailyVolumes: %v", err)
+	}
+	// Повторное обновление без новых данных тоже проходит
+	if err := analytics.RefreshDailyVolumes(ctx); err != nil {
+		t.Fatalf("second RefreshDailyVolumes: %v", err)
+	}
+
+	volumes, err = analytics.DailyVolumes(ctx, domain.AnalyticsRange{})
+	if err != nil {
+		t.Fatalf("DailyVolumes: %v", err)
+	}
+	if len(volumes) != 1 {
+		t.Fatalf("volumes after refresh = %+v, want one day", volumes)
+	}
+	// Отмененный заказ, начисления и возврат в объемы не входят
+	got := *volumes[0]
+	want := domain.DailyVolume{
+		Day:            got.Day,
+		PurchasesCount: 1,
+		PurchasedItems: 3,
+		PurchaseVolume: 60,
+		TransfersCount: 1,
+		TransferVolume: 100,
+	}
+	if got != want {
+		t.Errorf("daily volume = %+v, want %+v", got, want)
+	}
+
+	summary, err := analytics.Economy(ctx, domain.AnalyticsRange{})
+	if err != nil {
+		t.Fatalf("Economy: %v", err)
+	}
+	if summary.Circulation != 1940 || summary.Spent != 60 || summary.Issued != 2000 {
+		t.Errorf("economy = %+v, want circulation 1940, spent 60, issued 2000", summary)
+	}
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+	"github.com/jmoiron/sqlx"
+)
+
+const auditColumns = `id, actor_id, action, target_type, target_id, before, after,
+	request_id, ip, user_agent, created_at, prev_hash, hash`
+
+// auditChainLock - ключ advisory-блокировки, упорядочивающей запись цепочки
+const auditChainLock = 7_265_110_401
+
+type AuditRepository struct {
+	*Repository
+}
+
+func NewAuditRepository(repo *Repository) *AuditRepository {
+	return &AuditRepository{Repository: repo}
+}
+
+func (r *AuditRepository) Append(ctx context.Context, entry *domain.AuditEntry) error {
+	return r.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// Без блокировки две параллельные записи сослались бы на один и тот же предыдущий хеш
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLock); err != nil {
+			return fmt.Errorf("failed to lock audit chain: %w", err)
+		}
+
+		err := tx.GetContext(ctx, &entry.PrevHash, `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`)
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("failed to get last audit hash: %w", err)
+			}
+			entry.PrevHash = domain.AuditGenesisHash
+		}
+
+		entry.Hash, err = entry.ComputeHash()
+		if err != nil {
+			return fmt.Errorf("failed to hash audit entry: %w", err)
+		}
+
+		query := `
+			INSERT INTO audit_log (actor_id, action, target_type, target_id, before, after,
+				request_id, ip, user_agent, created_at, prev_hash, hash)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING id`
+
+		err = tx.QueryRowxContext(ctx, query,
+			entry.ActorID,
+			entry.Action,
+			entry.TargetType,
+			entry.TargetID,
+			nullableJSON(entry.Before),
+			nullableJSON(entry.After),
+			entry.RequestID,
+			entry.IP,
+			entry.UserAgent,
+			entry.CreatedAt,
+			entry.PrevHash,
+			entry.Hash,
+		).Scan(&entry.ID)
+		if err != nil {
+			return fmt.Errorf("failed to append audit entry: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (r *AuditRepository) List(ctx context.Context, filter domain.AuditFilter, params pagination.Params) (*pagination.Page[*domain.AuditEntry], error) {
+	cursor, err := decodeTimeCursor(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	var conditions []string
+	if filter.ActorID != nil {
+		conditions = append(conditions, "actor_id = "+arg(*filter.ActorID))
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = "+arg(filter.Action))
+	}
+	if filter.TargetType != "" {
+		conditions = append(conditions, "target_type = "+arg(filter.TargetType))
+	}
+	if filter.TargetID != "" {
+		conditions = append(conditions, "target_id = "+arg(filter.TargetID))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "created_at >= "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "created_at < "+arg(*filter.To))
+	}
+
+	var total *int64
+	if params.IsFirst() {
+		total = new(int64)
+		err := r.db.GetContext(ctx, total, `SELECT COUNT(*) FROM audit_log`+whereClause(conditions), args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count audit entries: %w", err)
+		}
+	}
+
+	if cursor != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < (%s, %s)", arg(cursor.CreatedAt), arg(cursor.ID)))
+	}
+
+	query := `SELECT ` + auditColumns + ` FROM audit_log` + whereClause(conditions) + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ` + arg(params.Limit+1)
+
+	var entries []*domain.AuditEntry
+	err = r.db.SelectContext(ctx, &entries, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit entries: %w", err)
+	}
+
+	page, err := pagination.NewPage(entries, params.Limit, func(last *domain.AuditEntry) any {
+		return timeCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	})
+	if err != nil {
+		return nil, err
+	}
+	page.Total = total
+
+	return page, nil
+}
+
+func (r *AuditRepository) Walk(ctx context.Context, fn func(entry *domain.AuditEntry) error) error {
+	rows, err := r.db.QueryxContext(ctx, `SELECT `+auditColumns+` FROM audit_log ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		entry := &domain.AuditEntry{}
+		if err := rows.StructScan(entry); err != nil {
+			return fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// nullableJSON передает пустой JSON как NULL, а не как пустую строку
+func nullableJSON(raw []byte) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/avito/internal/domain"
+)
+
+type CartRepository struct {
+	*Repository
+}
+
+func NewCartRepository(repo *Repository) *CartRepository {
+	return &CartRepository{Repository: repo}
+}
+
+func (r *CartRepository) AddItem(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) error {
+	query := `
+		INSERT INTO cart_items (user_id, merch_id, variant_id, quantity)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, merch_id, COALESCE(variant_id, 0)) DO UPDATE
+		SET quantity = cart_items.quantity + EXCLUDED.quantity,
+			updated_at = CURRENT_TIMESTAMP`
+
+	_, err := r.db.ExecContext(ctx, query, userID, merchID, variantID, quantity)
+	if err != nil {
+		return fmt.Errorf("failed to add cart item: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CartRepository) SetQuantity(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) error {
+	query := `
+		UPDATE cart_items
+		SET quantity = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $2 AND merch_id = $3 AND variant_id IS NOT DISTINCT FROM $4`
+
+	res, err := r.db.ExecContext(ctx, query, quantity, userID, merchID, variantID)
+	if err != nil {
+		return fmt.Errorf("failed to update cart item: %w", err)
+	}
+
+	return checkCartItemAffected(res.RowsAffected())
+}
+
+func (r *CartRepository) RemoveItem(ctx context.Context, userID, merchID int64, variantID *int64) error {
+	query := `
+		DELETE FROM cart_items
+		WHERE user_id = $1 AND merch_id = $2 AND variant_id IS NOT DISTINCT FROM $3`
+
+	res, err := r.db.ExecContext(ctx, query, userID, merchID, variantID)
+	if err != nil {
+		return fmt.Errorf("failed to remove cart item: %w", err)
+	}
+
+	return checkCartItemAffected(res.RowsAffected())
+}
+
+func (r *CartRepository) GetByUserID(ctx context.Context, userID int64) ([]*domain.CartItem, error) {
+	var items []*domain.CartItem
+
+	query := `
+		SELECT c.id, c.user_id, c.merch_id, c.variant_id, c.quantity, c.created_at, c.updated_at,
+			   m.name as merch_name, v.sku as variant_sku,
+			   COALESCE(v.price, m.price) as unit_price,
+			   COALESCE(v.price, m.price) * c.quantity as total_price
+		FROM cart_items c
+		JOIN merch m ON c.merch_id = m.id
+		LEFT JOIN merch_variants v ON c.variant_id = v.id
+		WHERE c.user_id = $1
+		ORDER BY c.id`
+
+	err := r.db.SelectContext(ctx, &items, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cart items: %w", err)
+	}
+
+	return items, nil
+}
+
+func checkCartItemAffected(affected int64, err error) error {
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrCartItemNotFound
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/internal/events"
+	"github.com/lib/pq"
+)
+
+const (
+	// eventsChannel - канал LISTEN/NOTIFY, общий для всех реплик API
+	eventsChannel = "merch_events"
+
+	// maxNotifyPayload - ограничение Postgres на размер payload в NOTIFY
+	maxNotifyPayload = 8000
+
+	listenerPingInterval = 90 * time.Second
+)
+
+// eventMessage - представление события в канале NOTIFY
+type eventMessage struct {
+	Type      domain.EventType `json:"type"`
+	UserID    int64            `json:"user_id"`
+	Data      json.RawMessage  `json:"data"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// EventBus рассылает события через NOTIFY и доставляет полученные через LISTEN
+// события локальным подписчикам, поэтому клиент получает событие
+// независимо от того, к какой реплике он подключен
+type EventBus struct {
+	*Repository
+	broker   *events.Broker
+	listener *pq.Listener
+	done     chan struct{}
+	logger   *slog.Logger
+}
+
+// NewEventBus подписывается на канал событий и запускает его чтение
+func NewEventBus(repo *Repository, dsn string, logger *slog.Logger) (*EventBus, error) {
+	logger = logger.With("component", "events_listener")
+
+	listener := pq.NewListener(dsn, 10*time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("listener connection error", "error", err)
+		}
+	})
+	if err := listener.Listen(eventsChannel); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to listen events channel: %w", err)
+	}
+
+	bus := &EventBus{
+		Repository: repo,
+		broker:     events.NewBroker(events.DefaultBufferSize),
+		listener:   listener,
+		done:       make(chan struct{}),
+		logger:     logger,
+	}
+	go bus.listen()
+
+	return bus, nil
+}
+
+func (b *EventBus) Publish(ctx context.Context, event *domain.Event) error {
+	payload, err := json.Marshal(eventMessage{
+		Type:      event.Type,
+		UserID:    event.UserID,
+		Data:      event.Data,
+		CreatedAt: event.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if len(payload) >= maxNotifyPayload {
+		return fmt.Errorf("event %s is too large for notify: %d bytes", event.Type, len(payload))
+	}
+
+	_, err = b.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, eventsChannel, string(payload))
+	if err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+
+	return nil
+}
+
+func (b *EventBus) Subscribe(userID int64) (<-chan *domain.Event, func()) {
+	return b.broker.Subscribe(userID)
+}
+
+// Disconnect завершает подписки клиентов этого процесса, не останавливая публикацию
+func (b *EventBus) Disconnect() {
+	b.broker.Close()
+}
+
+// Close останавливает чтение канала событий
+func (b *EventBus) Close() error {
+	b.broker.Close()
+	close(b.done)
+	return b.listener.Close()
+}
+
+func (b *EventBus) listen() {
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case n, ok := <-b.listener.Notify:
+			if !ok {
+				return
+			}
+			// nil приходит после переподключения: пропущенные за это время события не повторяются
+			if n == nil {
+				continue
+			}
+
+			var msg eventMessage
+			if err := json.Unmarshal([]byte(n.Extra), &msg); err != nil {
+				b.logger.Warn("invalid event payload", "error", err)
+				continue
+			}
+
+			b.broker.Dispatch(&domain.Event{
+				Type:      msg.Type,
+				UserID:    msg.UserID,
+				Data:      msg.Data,
+				CreatedAt: msg.CreatedAt,
+			})
+		case <-ticker.C:
+			go func() {
+				if err := b.listener.Ping(); err != nil {
+					b.logger.Warn("listener ping failed", "error", err)
+				}
+			}()
+		}
+	}
+}
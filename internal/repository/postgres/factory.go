@@ -1,26 +1,64 @@
 package postgres
 
-import "github.com/avito/internal/domain"
+import (
+	"log/slog"
+
+	"github.com/avito/internal/domain"
+)
 
 // Repositories содержит все репозитории
 type Repositories struct {
-	User        domain.UserRepository
-	Merch       domain.MerchRepository
-	Purchase    domain.PurchaseRepository
-	Transaction domain.TransactionRepository
+	User         domain.UserRepository
+	Merch        domain.MerchRepository
+	Purchase     domain.PurchaseRepository
+	Transaction  domain.TransactionRepository
+	Variant      domain.MerchVariantRepository
+	Cart         domain.CartRepository
+	Order        domain.OrderRepository
+	Snapshot     domain.Snapshotter
+	Wishlist     domain.WishlistRepository
+	Notification domain.NotificationRepository
+	Outbox       domain.OutboxRepository
+	Webhook      domain.WebhookRepository
+	Audit        domain.AuditRepository
+	Allowance    domain.AllowanceRepository
+	Kudos        domain.KudosRepository
+	Analytics    domain.AnalyticsRepository
+	Events       *EventBus
+	// DB - общее подключение всех репозиториев
+	DB *Repository
 }
 
 // NewRepositories создает новый экземпляр всех репозиториев
-func NewRepositories(dsn string) (*Repositories, error) {
+func NewRepositories(dsn string, logger *slog.Logger) (*Repositories, error) {
 	repo, err := New(dsn)
 	if err != nil {
 		return nil, err
 	}
 
+	events, err := NewEventBus(repo, dsn, logger)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Repositories{
-		User:        NewUserRepository(repo),
-		Merch:       NewMerchRepository(repo),
-		Purchase:    NewPurchaseRepository(repo),
-		Transaction: NewTransactionRepository(repo),
+		User:         NewUserRepository(repo),
+		Merch:        NewMerchRepository(repo),
+		Purchase:     NewPurchaseRepository(repo),
+		Transaction:  NewTransactionRepository(repo),
+		Variant:      NewMerchVariantRepository(repo),
+		Cart:         NewCartRepository(repo),
+		Order:        NewOrderRepository(repo),
+		Snapshot:     repo,
+		Wishlist:     NewWishlistRepository(repo),
+		Notification: NewNotificationRepository(repo),
+		Outbox:       NewOutboxRepository(repo),
+		Webhook:      NewWebhookRepository(repo),
+		Audit:        NewAuditRepository(repo),
+		Allowance:    NewAllowanceRepository(repo),
+		Kudos:        NewKudosRepository(repo),
+		Analytics:    NewAnalyticsRepository(repo),
+		Events:       events,
+		DB:           repo,
 	}, nil
 }
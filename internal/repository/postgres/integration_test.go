@@ -0,0 +1,152 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/avito/internal/domain"
+)
+
+// testRepository подключается к базе из TEST_POSTGRES_DSN, пересоздает схему public
+// и применяет все миграции. Тесты пакета выполняются последовательно и делят одну базу.
+func testRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	repo := emptyRepository(t)
+	applyMigrations(t, repo)
+
+	return repo
+}
+
+// emptyRepository подключается к тестовой базе и пересоздает пустую схему public
+func emptyRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	repo, err := New(dsn)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+
+	if _, err := repo.db.Exec(`DROP SCHEMA public CASCADE; CREATE SCHEMA public`); err != nil {
+		t.Fatalf("failed to reset schema: %v", err)
+	}
+
+	return repo
+}
+
+// applyMigrations выполняет секции Up миграций по порядку номеров
+func applyMigrations(t *testing.T, repo *Repository) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join("..", "..", "..", "migrations", "*.sql"))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("failed to find migrations: %v", err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file, err)
+		}
+		up, _, _ := strings.Cut(string(data), "-- +goose Down")
+		if _, err := repo.db.Exec(up); err != nil {
+			t.Fatalf("failed to apply %s: %v", filepath.Base(file), err)
+		}
+	}
+}
+
+func createTestUser(t *testing.T, repo *Repository, username string, balance int64) *domain.User {
+	t.Helper()
+
+	user := &domain.User{Username: username, Password: "hash", Balance: balance}
+	if err := NewUserRepository(repo).Create(context.Background(), user); err != nil {
+		t.Fatalf("failed to create user %s: %v", username, err)
+	}
+	return user
+}
+
+func createTestMerch(t *testing.T, repo *Repository, name string, price int64) *domain.Merch {
+	t.Helper()
+
+	merch := &domain.Merch{Name: name, Price: price}
+	if err := NewMerchRepository(repo).Create(context.Background(), merch); err != nil {
+		t.Fatalf("failed to create merch %s: %v", name, err)
+	}
+	return merch
+}
+
+func createTestVariant(t *testing.T, repo *Repository, merchID int64, sku string, stock int) *domain.MerchVariant {
+	t.Helper()
+
+	variant := &domain.MerchVariant{}
+	err := repo.db.Get(variant, `
+		INSERT INTO merch_variants (merch_id, sku, stock)
+		VALUES ($1, $2, $3)
+		RETURNING id, merch_id, sku, size, color, price, stock, created_at, updated_at`,
+		merchID, sku, stock,
+	)
+	if err != nil {
+		t.Fatalf("failed to create variant %s: %v", sku, err)
+	}
+	return variant
+}
+
+func variantStock(t *testing.T, repo *Repository, variantID int64) int {
+	t.Helper()
+
+	variant, err := NewMerchVariantRepository(repo).GetByID(context.Background(), variantID)
+	if err != nil {
+		t.Fatalf("failed to get variant %d: %v", variantID, err)
+	}
+	return variant.Stock
+}
+
+func userBalance(t *testing.T, repo *Repository, userID int64) int64 {
+	t.Helper()
+
+	user, err := NewUserRepository(repo).GetByID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("failed to get user %d: %v", userID, err)
+	}
+	return user.Balance
+}
+
+func countRows(t *testing.T, repo *Repository, query string, args ...any) int {
+	t.Helper()
+
+	var count int
+	if err := repo.db.Get(&count, query, args...); err != nil {
+		t.Fatalf("failed to count rows: %v", err)
+	}
+	return count
+}
+
+func testDelivery() domain.DeliveryDetails {
+	office := "Москва, Лесная 7"
+	return domain.DeliveryDetails{Method: domain.DeliveryMethodOffice, OfficeLocation: &office}
+}
+
+func equalInt64s(got, want []int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+type KudosRepository struct {
+	*Repository
+}
+
+func NewKudosRepository(repo *Repository) *KudosRepository {
+	return &KudosRepository{Repository: repo}
+}
+
+func (r *KudosRepository) Feed(ctx context.Context, category domain.KudosCategory, params pagination.Params) (*pagination.Page[*domain.Kudos], error) {
+	cursor, err := decodeTimeCursor(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if params.IsFirst() {
+		total = new(int64)
+		err := r.db.GetContext(ctx, total, `
+			SELECT COUNT(*) FROM transactions
+			WHERE is_public AND ($1 = '' OR category = $1)`,
+			category,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count kudos: %w", err)
+		}
+	}
+
+	var kudos []*domain.Kudos
+
+	query := `
+		SELECT t.id, t.from_user_id, fu.username AS from_username, t.to_user_id, tu.username AS to_username,
+			   t.amount, t.category, t.description, t.created_at
+		FROM transactions t
+		JOIN users fu ON t.from_user_id = fu.id
+		JOIN users tu ON t.to_user_id = tu.id
+		WHERE t.is_public
+		  AND ($1 = '' OR t.category = $1)
+		  AND ($2::timestamptz IS NULL OR (t.created_at, t.id) < ($2, $3))
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT $4`
+
+	createdAt, id := cursor.after()
+	err = r.db.SelectContext(ctx, &kudos, query, category, createdAt, id, params.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kudos feed: %w", err)
+	}
+
+	page, err := pagination.NewPage(kudos, params.Limit, func(last *domain.Kudos) any {
+		return timeCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	})
+	if err != nil {
+		return nil, err
+	}
+	page.Total = total
+
+	return page, nil
+}
+
+func (r *KudosRepository) Leaderboard(ctx context.Context, since *time.Time, category domain.KudosCategory, limit int) ([]*domain.LeaderboardEntry, error) {
+	entries := []*domain.LeaderboardEntry{}
+
+	// При равенстве благодарностей выше тот, кто получил больше монет
+	query := `
+		SELECT RANK() OVER (ORDER BY COUNT(*) DESC, SUM(t.amount) DESC) AS rank,
+			   u.id AS user_id, u.username, COUNT(*) AS kudos_count, SUM(t.amount) AS coins_received
+		FROM transactions t
+		JOIN users u ON t.to_user_id = u.id
+		WHERE t.is_public
+		  AND ($1 = '' OR t.category = $1)
+		  AND ($2::timestamptz IS NULL OR t.created_at >= $2)
+		GROUP BY u.id, u.username
+		ORDER BY rank, u.username
+		LIMIT $3`
+
+	err := r.db.SelectContext(ctx, &entries, query, category, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kudos leaderboard: %w", err)
+	}
+
+	return entries, nil
+}
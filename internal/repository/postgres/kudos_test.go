@@ -0,0 +1,200 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+func TestKudosRepositoryFeed(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	transactions, kudos := NewTransactionRepository(repo), NewKudosRepository(repo)
+
+	alice := createTestUser(t, repo, "alice", 1000)
+	bob := createTestUser(t, repo, "bob", 1000)
+
+	help, teamwork := domain.KudosHelp, domain.KudosTeamwork
+	transfers := []struct {
+		amount   int64
+		public   bool
+		category *domain.KudosCategory
+	}{
+		{10, true, &help}, {20, false, &help}, {30, true, &teamwork}, {40, true, nil}, {50, true, &help},
+	}
+	for _, tr := range transfers {
+		message := "спасибо"
+		err := transactions.Create(ctx, &domain.Transaction{
+			FromUserID:  alice.ID,
+			ToUserID:    bob.ID,
+			Amount:      tr.amount,
+			Description: &message,
+			IsPublic:    tr.public,
+			Category:    tr.category,
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		category domain.KudosCategory
+		want     []int64 // суммы в порядке выдачи
+	}{
+		// Приватные переводы в ленту не попадают
+		{name: "all", want: []int64{50, 40, 30, 10}},
+		{name: "category", category: domain.KudosHelp, want: []int64{50, 10}},
+		{name: "empty category", category: domain.KudosMentoring},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			params := pagination.Params{Limit: 3}
+			for pages := 0; ; pages++ {
+				if pages > len(transfers) {
+					t.Fatal("pagination does not terminate")
+				}
+
+				page, err := kudos.Feed(ctx, tt.category, params)
+				if err != nil {
+					t.Fatalf("Feed page %d: %v", pages, err)
+				}
+				if params.IsFirst() && (page.Total == nil || *page.Total != int64(len(tt.want))) {
+					t.Errorf("total = %v, want %d", page.Total, len(tt.want))
+				}
+				for _, item := range page.Items {
+					if item.FromUsername != "alice" || item.ToUsername != "bob" {
+						t.Errorf("kudos %d usernames = %s -> %s, want alice -> bob", item.ID, item.FromUsername, item.ToUsername)
+					}
+					got = append(got, item.Amount)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				params.Cursor = page.NextCursor
+			}
+
+			if !equalInt64s(got, tt.want) {
+				t.Errorf("feed amounts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKudosRepositoryLeaderboard(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	transactions, kudos := NewTransactionRepository(repo), NewKudosRepository(repo)
+
+	alice := createTestUser(t, repo, "alice", 1000)
+	bob := createTestUser(t, repo, "bob", 1000)
+	carol := createTestUser(t, repo, "carol", 1000)
+	dave := createTestUser(t, repo, "dave", 1000)
+
+	help := domain.KudosHelp
+	transfers := []struct {
+		to      *domain.User
+		amount  int64
+		public  bool
+		old     bool
+		helpful bool
+	}{
+		// bob и carol получили по две благодарности, у bob больше монет
+		{to: bob, amount: 50, public: true, helpful: true},
+		{to: bob, amount: 30, public: true},
+		{to: carol, amount: 20, public: true, helpful: true},
+		{to: carol, amount: 10, public: true, helpful: true},
+		// Приватный перевод не учитывается
+		{to: dave, amount: 500},
+		{to: dave, amount: 10, public: true},
+		// Благодарности до начала периода учитываются только в рейтинге за все время
+		{to: dave, amount: 10, public: true, old: true},
+		{to: dave, amount: 10, public: true, old: true},
+	}
+	for _, tr := range transfers {
+		transaction := &domain.Transaction{FromUserID: alice.ID, ToUserID: tr.to.ID, Amount: tr.amount, IsPublic: tr.public}
+		if tr.helpful {
+			transaction.Category = &help
+		}
+		if err := transactions.Create(ctx, transaction); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		if tr.old {
+			if _, err := repo.db.Exec(`UPDATE transactions SET created_at = '2020-01-01T00:00:00Z' WHERE id = $1`, transaction.ID); err != nil {
+				t.Fatalf("failed to backdate transaction: %v", err)
+			}
+		}
+	}
+
+	since := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		since    *time.Time
+		category domain.KudosCategory
+		limit    int
+		want     []domain.LeaderboardEntry
+	}{
+		{
+			name:  "period",
+			since: &since,
+			limit: 10,
+			want: []domain.LeaderboardEntry{
+				{Rank: 1, Username: "bob", KudosCount: 2, CoinsReceived: 80},
+				{Rank: 2, Username: "carol", KudosCount: 2, CoinsReceived: 30},
+				{Rank: 3, Username: "dave", KudosCount: 1, CoinsReceived: 10},
+			},
+		},
+		{
+			name:  "all time",
+			limit: 10,
+			want: []domain.LeaderboardEntry{
+				{Rank: 1, Username: "dave", KudosCount: 3, CoinsReceived: 30},
+				{Rank: 2, Username: "bob", KudosCount: 2, CoinsReceived: 80},
+				{Rank: 3, Username: "carol", KudosCount: 2, CoinsReceived: 30},
+			},
+		},
+		{
+			name:     "category",
+			category: domain.KudosHelp,
+			limit:    10,
+			want: []domain.LeaderboardEntry{
+				{Rank: 1, Username: "carol", KudosCount: 2, CoinsReceived: 30},
+				{Rank: 2, Username: "bob", KudosCount: 1, CoinsReceived: 50},
+			},
+		},
+		{
+			name:  "limit",
+			since: &since,
+			limit: 1,
+			want:  []domain.LeaderboardEntry{{Rank: 1, Username: "bob", KudosCount: 2, CoinsReceived: 80}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := kudos.Leaderboard(ctx, tt.since, tt.category, tt.limit)
+			if err != nil {
+				t.Fatalf("Leaderboard: %v", err)
+			}
+
+			if len(entries) != len(tt.want) {
+				t.Fatalf("entries = %d, want %d", len(entries), len(tt.want))
+			}
+			for i, entry := range entries {
+				got := *entry
+				got.UserID = 0
+				if got != tt.want[i] {
+					t.Errorf("entry %d = %+v, want %+v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -7,8 +7,62 @@ import (
 	"fmt"
 
 	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+	"github.com/lib/pq"
 )
 
+// merchColumns выбирает мерч вместе с категорией и тегами
+const merchColumns = `
+			m.id, m.name, m.description, m.price, m.created_at, m.updated_at,
+			m.image_key, m.thumbnail_key, c.name as category,
+			ARRAY(
+				SELECT t.name
+				FROM merch_tags mt
+				JOIN tags t ON mt.tag_id = t.id
+				WHERE mt.merch_id = m.id
+				ORDER BY t.name
+			) as tags`
+
+const merchFrom = `
+		FROM merch m
+		LEFT JOIN categories c ON m.category_id = c.id
+		LEFT JOIN LATERAL (
+			SELECT MIN(COALESCE(v.price, m.price)) AS min_price
+			FROM merch_variants v
+			WHERE v.merch_id = m.id
+		) vp ON TRUE`
+
+// merchPrice - цена "от": минимальная цена среди вариантов, для товара без вариантов его базовая цена.
+// По ней фильтрует и сортирует каталог.
+const merchPrice = "COALESCE(vp.min_price, m.price)"
+
+// merchRow нужен для сканирования тегов в массив Postgres
+type merchRow struct {
+	domain.Merch
+	Tags    pq.StringArray `db:"tags"`
+	SortKey string         `db:"sort_key"`
+}
+
+func (r *merchRow) toDomain() *domain.Merch {
+	merch := r.Merch
+	merch.Tags = []string(r.Tags)
+	return &merch
+}
+
+// merchCursor хранит ключ сортировки последнего элемента страницы каталога
+type merchCursor struct {
+	Sort domain.MerchSort `json:"s"`
+	Key  string           `json:"k"`
+	ID   int64            `json:"id"`
+}
+
+// merchOrder описывает ключ сортировки каталога для keyset-пагинации
+type merchOrder struct {
+	expr string
+	cast string
+	desc bool
+}
+
 type MerchRepository struct {
 	*Repository
 }
@@ -37,14 +91,12 @@ func (r *MerchRepository) Create(ctx context.Context, merch *domain.Merch) error
 }
 
 func (r *MerchRepository) GetByID(ctx context.Context, id int64) (*domain.Merch, error) {
-	merch := &domain.Merch{}
+	row := &merchRow{}
 
-	query := `
-		SELECT id, name, description, price, created_at, updated_at
-		FROM merch
-		WHERE id = $1`
+	query := `SELECT` + merchColumns + merchFrom + `
+		WHERE m.id = $1`
 
-	err := r.db.GetContext(ctx, merch, query, id)
+	err := r.db.GetContext(ctx, row, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("merch not found")
@@ -52,24 +104,133 @@ func (r *MerchRepository) GetByID(ctx context.Context, id int64) (*domain.Merch,
 		return nil, fmt.Errorf("failed to get merch: %w", err)
 	}
 
-	return merch, nil
+	return row.toDomain(), nil
 }
 
-func (r *MerchRepository) List(ctx context.Context, limit, offset int) ([]*domain.Merch, error) {
-	var items []*domain.Merch
+func (r *MerchRepository) List(ctx context.Context, filter domain.MerchFilter, params pagination.Params) (*pagination.Page[*domain.Merch], error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
 
-	query := `
-		SELECT id, name, description, price, created_at, updated_at
-		FROM merch
-		ORDER BY id
-		LIMIT $1 OFFSET $2`
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Category != "" {
+		conditions = append(conditions, "c.name = "+arg(filter.Category))
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1
+			FROM merch_tags mt
+			JOIN tags t ON mt.tag_id = t.id
+			WHERE mt.merch_id = m.id AND t.name = `+arg(filter.Tag)+`)`)
+	}
+	if filter.MinPrice != nil {
+		conditions = append(conditions, merchPrice+" >= "+arg(*filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		conditions = append(conditions, merchPrice+" <= "+arg(*filter.MaxPrice))
+	}
+
+	var rank string
+	if filter.Query != "" {
+		// Название индексируется без стемминга, описание на русском
+		q := arg(filter.Query)
+		tsQuery := fmt.Sprintf("(websearch_to_tsquery('simple', %[1]s) || websearch_to_tsquery('russian', %[1]s))", q)
+		conditions = append(conditions, "m.search_vector @@ "+tsQuery)
+		rank = "ts_rank(m.search_vector, " + tsQuery + ")"
+	}
+	if filter.InStock {
+		// Товары без вариантов считаются доступными всегда
+		conditions = append(conditions, `(
+			NOT EXISTS (SELECT 1 FROM merch_variants v WHERE v.merch_id = m.id)
+			OR EXISTS (SELECT 1 FROM merch_variants v WHERE v.merch_id = m.id AND v.stock > 0))`)
+	}
+
+	// Общее количество считаем один раз, на первой странице
+	var total *int64
+	if params.IsFirst() {
+		total = new(int64)
+		countQuery := `SELECT COUNT(*)` + merchFrom + whereClause(conditions)
+		if err := r.db.GetContext(ctx, total, countQuery, args...); err != nil {
+			return nil, fmt.Errorf("failed to count merch: %w", err)
+		}
+	}
+
+	order := merchOrderBy(filter.Sort, rank)
+
+	if !params.IsFirst() {
+		var cursor merchCursor
+		if err := pagination.DecodeCursor(params.Cursor, &cursor); err != nil {
+			return nil, err
+		}
+		if cursor.Sort != filter.Sort {
+			return nil, pagination.ErrInvalidCursor
+		}
 
-	err := r.db.SelectContext(ctx, &items, query, limit, offset)
+		op := ">"
+		if order.desc {
+			op = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, m.id) %s (%s::%s, %s)",
+			order.expr, op, arg(cursor.Key), order.cast, arg(cursor.ID)))
+	}
+
+	direction := ""
+	if order.desc {
+		direction = " DESC"
+	}
+
+	query := `SELECT` + merchColumns + `,
+			(` + order.expr + `)::text as sort_key` + merchFrom + whereClause(conditions) + `
+		ORDER BY ` + order.expr + direction + `, m.id` + direction + `
+		LIMIT ` + arg(params.Limit+1)
+
+	var rows []*merchRow
+	err := r.db.SelectContext(ctx, &rows, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list merch: %w", err)
 	}
 
-	return items, nil
+	items := make([]*domain.Merch, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, row.toDomain())
+	}
+
+	page, err := pagination.NewPage(items, params.Limit, func(last *domain.Merch) any {
+		return merchCursor{Sort: filter.Sort, Key: rows[params.Limit-1].SortKey, ID: last.ID}
+	})
+	if err != nil {
+		return nil, err
+	}
+	page.Total = total
+
+	return page, nil
+}
+
+func (r *MerchRepository) SetImage(ctx context.Context, merchID int64, imageKey, thumbnailKey *string) error {
+	query := `
+		UPDATE merch
+		SET image_key = $1, thumbnail_key = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3`
+
+	res, err := r.db.ExecContext(ctx, query, imageKey, thumbnailKey, merchID)
+	if err != nil {
+		return fmt.Errorf("failed to set merch image: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrMerchNotFound
+	}
+
+	return nil
 }
 
 func (r *MerchRepository) UpdateQuantity(ctx context.Context, merchID int64, quantity int) error {
@@ -77,3 +238,28 @@ func (r *MerchRepository) UpdateQuantity(ctx context.Context, merchID int64, qua
 	// "Предполагается, что в магазине бесконечный запас каждого вида мерча"
 	return nil
 }
+
+// merchOrderBy возвращает ключ сортировки, rank используется для поиска без явной сортировки
+func merchOrderBy(sort domain.MerchSort, rank string) merchOrder {
+	switch sort {
+	case domain.MerchSortPriceAsc:
+		return merchOrder{expr: merchPrice, cast: "bigint"}
+	case domain.MerchSortPriceDesc:
+		return merchOrder{expr: merchPrice, cast: "bigint", desc: true}
+	case domain.MerchSortName:
+		return merchOrder{expr: "m.name", cast: "text"}
+	case domain.MerchSortPopularity:
+		return merchOrder{expr: `(
+			SELECT COALESCE(SUM(p.quantity), 0)
+			FROM purchases p
+			WHERE p.merch_id = m.id
+		)`, cast: "bigint", desc: true}
+	case domain.MerchSortNewest:
+		return merchOrder{expr: "m.created_at", cast: "timestamptz", desc: true}
+	}
+
+	if rank != "" {
+		return merchOrder{expr: rank, cast: "real", desc: true}
+	}
+	return merchOrder{expr: "m.id", cast: "bigint"}
+}
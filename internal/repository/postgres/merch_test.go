@@ -0,0 +1,144 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+func TestMerchRepositoryListEffectivePrice(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	merch := NewMerchRepository(repo)
+
+	if _, err := repo.db.Exec(`DELETE FROM merch`); err != nil {
+		t.Fatalf("failed to clear seed merch: %v", err)
+	}
+
+	// Базовая цена худи выше фильтра, но вариант S дешевле
+	cup := createTestMerch(t, repo, "test-cup", 40)
+	hoody := createTestMerch(t, repo, "test-hoody", 300)
+	small := createTestVariant(t, repo, hoody.ID, "test-hoody-s", 1)
+	createTestVariant(t, repo, hoody.ID, "test-hoody-m", 1)
+	if _, err := repo.db.Exec(`UPDATE merch_variants SET price = 30 WHERE id = $1`, small.ID); err != nil {
+		t.Fatalf("failed to set variant price: %v", err)
+	}
+	pen := createTestMerch(t, repo, "test-pen", 35)
+
+	maxPrice := int64(35)
+	minPrice := int64(36)
+
+	tests := []struct {
+		name   string
+		filter domain.MerchFilter
+		want   []int64
+	}{
+		{name: "sort by price", filter: domain.MerchFilter{Sort: domain.MerchSortPriceAsc}, want: []int64{hoody.ID, pen.ID, cup.ID}},
+		{name: "sort by price desc", filter: domain.MerchFilter{Sort: domain.MerchSortPriceDesc}, want: []int64{cup.ID, pen.ID, hoody.ID}},
+		{name: "max price", filter: domain.MerchFilter{MaxPrice: &maxPrice, Sort: domain.MerchSortPriceAsc}, want: []int64{hoody.ID, pen.ID}},
+		{name: "min price", filter: domain.MerchFilter{MinPrice: &minPrice}, want: []int64{cup.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := merch.List(ctx, tt.filter, pagination.Params{Limit: 10})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+
+			got := make([]int64, 0, len(page.Items))
+			for _, item := range page.Items {
+				got = append(got, item.ID)
+			}
+			if !equalInt64s(got, tt.want) {
+				t.Errorf("List() ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerchRepositoryListKeysetPages(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	merch := NewMerchRepository(repo)
+
+	// Одинаковые цены проверяют, что на границе страниц товары не теряются и не повторяются
+	for i, price := range []int64{50, 20, 50, 50, 10, 20, 70} {
+		createTestMerch(t, repo, fmt.Sprintf("test-keyset-%d", i), price)
+	}
+
+	sorts := []domain.MerchSort{
+		domain.MerchSortDefault,
+		domain.MerchSortPriceAsc,
+		domain.MerchSortPriceDesc,
+		domain.MerchSortName,
+		domain.MerchSortNewest,
+	}
+
+	for _, sort := range sorts {
+		t.Run(string(sort), func(t *testing.T) {
+			filter := domain.MerchFilter{Sort: sort}
+
+			all, err := merch.List(ctx, filter, pagination.Params{Limit: pagination.MaxLimit})
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			want := make([]int64, 0, len(all.Items))
+			for _, item := range all.Items {
+				want = append(want, item.ID)
+			}
+
+			var got []int64
+			params := pagination.Params{Limit: 3}
+			for pages := 0; ; pages++ {
+				if pages > len(want) {
+					t.Fatal("pagination does not terminate")
+				}
+
+				page, err := merch.List(ctx, filter, params)
+				if err != nil {
+					t.Fatalf("List page %d: %v", pages, err)
+				}
+				if (page.Total != nil) != params.IsFirst() {
+					t.Errorf("page %d total = %v, want total only on the first page", pages, page.Total)
+				}
+				for _, item := range page.Items {
+					got = append(got, item.ID)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				params.Cursor = page.NextCursor
+			}
+
+			if !equalInt64s(got, want) {
+				t.Errorf("paged ids = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestMerchRepositoryListRejectsForeignCursor(t *testing.T) {
+	repo := testRepository(t)
+	merch := NewMerchRepository(repo)
+
+	page, err := merch.List(context.Background(), domain.MerchFilter{Sort: domain.MerchSortName}, pagination.Params{Limit: 1})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if page.NextCursor == "" {
+		t.Fatal("expected a next cursor for seeded merch")
+	}
+
+	_, err = merch.List(context.Background(), domain.MerchFilter{Sort: domain.MerchSortPriceAsc},
+		pagination.Params{Limit: 1, Cursor: page.NextCursor})
+	if !errors.Is(err, pagination.ErrInvalidCursor) {
+		t.Errorf("List() error = %v, want ErrInvalidCursor", err)
+	}
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/avito/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type MerchVariantRepository struct {
+	*Repository
+}
+
+func NewMerchVariantRepository(repo *Repository) *MerchVariantRepository {
+	return &MerchVariantRepository{Repository: repo}
+}
+
+func (r *MerchVariantRepository) GetByID(ctx context.Context, id int64) (*domain.MerchVariant, error) {
+	variant := &domain.MerchVariant{}
+
+	query := `
+		SELECT id, merch_id, sku, size, color, price, stock, created_at, updated_at
+		FROM merch_variants
+		WHERE id = $1`
+
+	err := r.db.GetContext(ctx, variant, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrVariantNotFound
+		}
+		return nil, fmt.Errorf("failed to get merch variant: %w", err)
+	}
+
+	return variant, nil
+}
+
+func (r *MerchVariantRepository) GetByMerchID(ctx context.Context, merchID int64) ([]*domain.MerchVariant, error) {
+	var variants []*domain.MerchVariant
+
+	query := `
+		SELECT id, merch_id, sku, size, color, price, stock, created_at, updated_at
+		FROM merch_variants
+		WHERE merch_id = $1
+		ORDER BY id`
+
+	err := r.db.SelectContext(ctx, &variants, query, merchID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get merch variants: %w", err)
+	}
+
+	return variants, nil
+}
+
+// reserveStock списывает остаток варианта внутри транзакции покупки
+func reserveStock(ctx context.Context, tx *sqlx.Tx, variantID int64, quantity int) error {
+	res, err := tx.ExecContext(ctx, `
+		UPDATE merch_variants
+		SET stock = stock - $1,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND stock >= $1`,
+		quantity, variantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to reserve stock: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrOutOfStock
+	}
+
+	return nil
+}
+
+func (r *MerchVariantRepository) SetStock(ctx context.Context, id int64, stock int) (int, error) {
+	var previous int
+
+	// Блокируем строку, чтобы вернуть остаток именно до этого изменения
+	err := r.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, &previous, `SELECT stock FROM merch_variants WHERE id = $1 FOR UPDATE`, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrVariantNotFound
+			}
+			return fmt.Errorf("failed to lock merch variant: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE merch_variants
+			SET stock = $1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2`,
+			stock, id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update merch variant stock: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return previous, nil
+}
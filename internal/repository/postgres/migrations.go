@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/avito/migrations"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// Ping проверяет доступность базы
+func (r *Repository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// MigrationVersion возвращает текущую версию схемы по таблице goose.
+// Откат записывается строкой с is_applied = false, поэтому версия - последняя
+// по порядку запись, которая не была позже откатана.
+func (r *Repository) MigrationVersion(ctx context.Context) (int64, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT version_id, is_applied FROM goose_db_version ORDER BY id DESC`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	defer rows.Close()
+
+	rolledBack := make(map[int64]bool)
+	for rows.Next() {
+		var (
+			version int64
+			applied bool
+		)
+		if err := rows.Scan(&version, &applied); err != nil {
+			return 0, fmt.Errorf("failed to scan migration version: %w", err)
+		}
+		if rolledBack[version] {
+			continue
+		}
+		if applied {
+			return version, nil
+		}
+		rolledBack[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to get migration version: %w", err)
+	}
+
+	return 0, nil
+}
+
+// Migrator применяет встроенные в бинарник миграции. Команды, меняющие схему, выполняются
+// под advisory lock, поэтому одновременно стартующие реплики не применяют миграции дважды.
+type Migrator struct {
+	provider *goose.Provider
+}
+
+// NewMigrator создает новый экземпляр Migrator
+func NewMigrator(repo *Repository) (*Migrator, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migration lock: %w", err)
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, repo.DB(), migrations.FS,
+		goose.WithSessionLocker(locker),
+		goose.WithDisableGlobalRegistry(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	return &Migrator{provider: provider}, nil
+}
+
+// Latest возвращает версию последней встроенной миграции
+func (m *Migrator) Latest() int64 {
+	sources := m.provider.ListSources()
+	if len(sources) == 0 {
+		return 0
+	}
+	return sources[len(sources)-1].Version
+}
+
+// Up применяет все еще не примененные миграции
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	return m.provider.Up(ctx)
+}
+
+// Down откатывает последнюю примененную миграцию
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	return m.provider.Down(ctx)
+}
+
+// To применяет или откатывает миграции до версии version
+func (m *Migrator) To(ctx context.Context, version int64) ([]*goose.MigrationResult, error) {
+	current, err := m.provider.GetDBVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	if version >= current {
+		return m.provider.UpTo(ctx, version)
+	}
+	return m.provider.DownTo(ctx, version)
+}
+
+// Status возвращает состояние всех миграций по возрастанию версии
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
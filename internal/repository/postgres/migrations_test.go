@@ -0,0 +1,148 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/avito/internal/domain"
+)
+
+func testMigrator(t *testing.T, repo *Repository) *Migrator {
+	t.Helper()
+
+	migrator, err := NewMigrator(repo)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	return migrator
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	repo := emptyRepository(t)
+	ctx := context.Background()
+	migrator := testMigrator(t, repo)
+
+	results, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if int64(len(results)) != migrator.Latest() {
+		t.Errorf("applied %d migrations, want %d", len(results), migrator.Latest())
+	}
+	if version, err := repo.MigrationVersion(ctx); err != nil || version != migrator.Latest() {
+		t.Fatalf("MigrationVersion() = %d, %v, want %d", version, err, migrator.Latest())
+	}
+
+	// Повторный запуск ничего не применяет
+	if results, err := migrator.Up(ctx); err != nil || len(results) != 0 {
+		t.Fatalf("second Up = %d results, %v, want none", len(results), err)
+	}
+
+	// Down откатывает ровно одну миграцию
+	result, err := migrator.Down(ctx)
+	if err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if result.Source.Version != migrator.Latest() {
+		t.Errorf("rolled back version %d, want %d", result.Source.Version, migrator.Latest())
+	}
+	if version, _ := repo.MigrationVersion(ctx); version != migrator.Latest()-1 {
+		t.Errorf("MigrationVersion() after Down = %d, want %d", version, migrator.Latest()-1)
+	}
+
+	// Все секции Down обратимы на пустой базе, а схема после полного отката собирается заново
+	if _, err := migrator.To(ctx, 0); err != nil {
+		t.Fatalf("To(0): %v", err)
+	}
+	if version, _ := repo.MigrationVersion(ctx); version != 0 {
+		t.Errorf("MigrationVersion() after To(0) = %d, want 0", version)
+	}
+	if got := countRows(t, repo, `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'public' AND table_name = 'users'`); got != 0 {
+		t.Errorf("users table exists after full rollback")
+	}
+
+	if _, err := migrator.Up(ctx); err != nil {
+		t.Fatalf("Up after rollback: %v", err)
+	}
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt.IsZero() {
+			t.Errorf("migration %d is not applied", status.Source.Version)
+		}
+	}
+}
+
+func TestMigratorConcurrentUp(t *testing.T) {
+	repo := emptyRepository(t)
+	ctx := context.Background()
+
+	// Реплики стартуют одновременно: advisory lock не дает применить миграцию дважды
+	const replicas = 3
+	applied := make([]int, replicas)
+	errs := make([]error, replicas)
+
+	var wg sync.WaitGroup
+	for i := 0; i < replicas; i++ {
+		migrator := testMigrator(t, repo)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results, err := migrator.Up(ctx)
+			applied[i], errs[i] = len(results), err
+		}(i)
+	}
+	wg.Wait()
+
+	total := 0
+	for i := range errs {
+		if errs[i] != nil {
+			t.Errorf("replica %d Up: %v", i, errs[i])
+		}
+		total += applied[i]
+	}
+	if latest := testMigrator(t, repo).Latest(); int64(total) != latest {
+		t.Errorf("migrations applied in total = %d, want %d", total, latest)
+	}
+}
+
+func TestMigratorRefusesIrreversibleRollback(t *testing.T) {
+	repo := emptyRepository(t)
+	ctx := context.Background()
+	migrator := testMigrator(t, repo)
+
+	if _, err := migrator.To(ctx, 14); err != nil {
+		t.Fatalf("To(14): %v", err)
+	}
+
+	// Начисление казначейства входит в историю балансов, поэтому 014 больше не откатывается.
+	// Строки вставляются напрямую: код репозиториев рассчитан на последнюю версию схемы.
+	_, err := repo.db.Exec(`
+		WITH alice AS (
+			INSERT INTO users (username, password_hash, balance) VALUES ('alice', 'hash', 100)
+			RETURNING id
+		)
+		INSERT INTO transactions (from_user_id, to_user_id, amount, kind, reason_code)
+		SELECT t.id, alice.id, 100, 'adjustment', 'bonus'
+		FROM alice, users t
+		WHERE t.username = $1 AND t.is_system`,
+		domain.TreasuryUsername,
+	)
+	if err != nil {
+		t.Fatalf("failed to insert adjustment: %v", err)
+	}
+
+	_, err = migrator.Down(ctx)
+	if err == nil || !strings.Contains(err.Error(), "irreversible") {
+		t.Fatalf("Down() error = %v, want irreversible migration error", err)
+	}
+	if version, _ := repo.MigrationVersion(ctx); version != 14 {
+		t.Errorf("MigrationVersion() after failed Down = %d, want 14", version)
+	}
+}
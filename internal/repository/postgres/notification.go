@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+type NotificationRepository struct {
+	*Repository
+}
+
+func NewNotificationRepository(repo *Repository) *NotificationRepository {
+	return &NotificationRepository{Repository: repo}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, type, message, payload)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		notification.UserID,
+		notification.Type,
+		notification.Message,
+		string(notification.Payload),
+	).Scan(&notification.ID, &notification.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationRepository) GetByUserID(ctx context.Context, userID int64, unreadOnly bool, params pagination.Params) (*pagination.Page[*domain.Notification], error) {
+	cursor, err := decodeTimeCursor(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if params.IsFirst() {
+		total = new(int64)
+		err := r.db.GetContext(ctx, total, `
+			SELECT COUNT(*) FROM notifications
+			WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)`,
+			userID, unreadOnly,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count notifications: %w", err)
+		}
+	}
+
+	var notifications []*domain.Notification
+
+	query := `
+		SELECT id, user_id, type, message, payload, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1
+		  AND (NOT $2 OR read_at IS NULL)
+		  AND ($3::timestamptz IS NULL OR (created_at, id) < ($3, $4))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $5`
+
+	createdAt, id := cursor.after()
+	err = r.db.SelectContext(ctx, &notifications, query, userID, unreadOnly, createdAt, id, params.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+
+	page, err := pagination.NewPage(notifications, params.Limit, func(last *domain.Notification) any {
+		return timeCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	})
+	if err != nil {
+		return nil, err
+	}
+	page.Total = total
+
+	return page, nil
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id int64) error {
+	// Повторная отметка не меняет время прочтения
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE id = $1 AND user_id = $2`
+
+	res, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification read: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	query := `
+		UPDATE notifications
+		SET read_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND read_at IS NULL`
+
+	res, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return affected, nil
+}
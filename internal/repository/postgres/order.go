@@ -0,0 +1,423 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/avito/internal/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+const orderColumns = `id, user_id, total_price, status, delivery_method, office_location, pickup_point, created_at, updated_at`
+
+type OrderRepository struct {
+	*Repository
+}
+
+func NewOrderRepository(repo *Repository) *OrderRepository {
+	return &OrderRepository{Repository: repo}
+}
+
+func (r *OrderRepository) Checkout(ctx context.Context, userID int64, delivery domain.DeliveryDetails) (*domain.Order, error) {
+	order := &domain.Order{
+		UserID:          userID,
+		DeliveryDetails: delivery,
+	}
+
+	err := r.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		var balance int64
+		err := tx.QueryRowContext(ctx, `
+			SELECT balance
+			FROM users
+			WHERE id = $1
+			FOR UPDATE`,
+			userID,
+		).Scan(&balance)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrUserNotFound
+			}
+			return fmt.Errorf("failed to get user balance: %w", err)
+		}
+
+		// Цены берутся на момент оформления, корзина блокируется до конца транзакции
+		var items []*domain.CartItem
+		err = tx.SelectContext(ctx, &items, `
+			SELECT c.id, c.user_id, c.merch_id, c.variant_id, c.quantity, c.created_at, c.updated_at,
+				   m.name as merch_name, v.sku as variant_sku,
+				   COALESCE(v.price, m.price) as unit_price,
+				   COALESCE(v.price, m.price) * c.quantity as total_price
+			FROM cart_items c
+			JOIN merch m ON c.merch_id = m.id
+			LEFT JOIN merch_variants v ON c.variant_id = v.id
+			WHERE c.user_id = $1
+			ORDER BY c.id
+			FOR UPDATE OF c`,
+			userID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get cart items: %w", err)
+		}
+
+		if len(items) == 0 {
+			return domain.ErrCartEmpty
+		}
+
+		for _, item := range items {
+			order.TotalPrice += item.TotalPrice
+		}
+
+		if balance < order.TotalPrice {
+			return fmt.Errorf("%w: available %d, required %d", domain.ErrInsufficientFunds, balance, order.TotalPrice)
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			UPDATE users
+			SET balance = balance - $1,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2
+			RETURNING balance`,
+			order.TotalPrice, userID,
+		).Scan(&order.BuyerBalance)
+		if err != nil {
+			return fmt.Errorf("failed to update user balance: %w", err)
+		}
+
+		for _, item := range items {
+			if item.VariantID == nil {
+				continue
+			}
+			if err := reserveStock(ctx, tx, *item.VariantID, item.Quantity); err != nil {
+				return err
+			}
+		}
+
+		if err := insertOrder(ctx, tx, order); err != nil {
+			return err
+		}
+
+		order.Purchases = make([]*domain.PurchaseResponse, 0, len(items))
+		for _, item := range items {
+			purchase := &domain.Purchase{
+				UserID:     userID,
+				MerchID:    item.MerchID,
+				VariantID:  item.VariantID,
+				Quantity:   item.Quantity,
+				UnitPrice:  item.UnitPrice,
+				TotalPrice: item.TotalPrice,
+				OrderID:    order.ID,
+			}
+			if err := insertPurchase(ctx, tx, purchase); err != nil {
+				return err
+			}
+			if err := insertPurchaseEvent(ctx, tx, purchase); err != nil {
+				return err
+			}
+			order.Purchases = append(order.Purchases, &domain.PurchaseResponse{
+				ID:          purchase.ID,
+				UserID:      purchase.UserID,
+				MerchID:     purchase.MerchID,
+				MerchName:   item.MerchName,
+				VariantID:   purchase.VariantID,
+				VariantSKU:  item.VariantSKU,
+				Quantity:    purchase.Quantity,
+				UnitPrice:   purchase.UnitPrice,
+				TotalPrice:  purchase.TotalPrice,
+				OrderID:     order.ID,
+				OrderStatus: order.Status,
+				CreatedAt:   purchase.CreatedAt,
+			})
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = $1`, userID)
+		if err != nil {
+			return fmt.Errorf("failed to clear cart: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (r *OrderRepository) GetByID(ctx context.Context, id int64) (*domain.Order, error) {
+	order := &domain.Order{}
+
+	query := `SELECT ` + orderColumns + `
+		FROM orders
+		WHERE id = $1`
+
+	err := r.db.GetContext(ctx, order, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrOrderNotFound
+		}
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	if err := r.attachPurchases(ctx, []*domain.Order{order}); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (r *OrderRepository) GetByUserID(ctx context.Context, userID int64) ([]*domain.Order, error) {
+	var orders []*domain.Order
+
+	query := `SELECT ` + orderColumns + `
+		FROM orders
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC`
+
+	err := r.db.SelectContext(ctx, &orders, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user orders: %w", err)
+	}
+
+	if err := r.attachPurchases(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *OrderRepository) List(ctx context.Context, status domain.OrderStatus, limit, offset int) ([]*domain.Order, error) {
+	var orders []*domain.Order
+
+	query := `SELECT ` + orderColumns + `
+		FROM orders
+		WHERE $1::text = '' OR status = $1
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3`
+
+	err := r.db.SelectContext(ctx, &orders, query, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	if err := r.attachPurchases(ctx, orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *OrderRepository) UpdateStatus(ctx context.Context, id int64, status domain.OrderStatus) (*domain.Order, []int64, error) {
+	order := &domain.Order{}
+	var restocked []int64
+
+	err := r.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		err := tx.GetContext(ctx, order, `SELECT `+orderColumns+`
+			FROM orders
+			WHERE id = $1
+			FOR UPDATE`, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrOrderNotFound
+			}
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+
+		if !order.Status.CanTransitionTo(status) {
+			return domain.ErrInvalidOrderStatus
+		}
+
+		err = tx.QueryRowxContext(ctx, `
+			UPDATE orders
+			SET status = $1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2
+			RETURNING status, updated_at`,
+			status, id,
+		).Scan(&order.Status, &order.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+
+		// Отмененный заказ не будет выдан, поэтому монеты возвращаются покупателю
+		// переводом от казначейства: возврат виден в истории переводов и в выписке
+		if status == domain.OrderStatusCancelled {
+			refund, err := refundOrder(ctx, tx, order)
+			if err != nil {
+				return err
+			}
+			order.BuyerBalance = refund.RecipientBalance
+
+			err = insertOutboxEvent(ctx, tx, domain.OutboxEventOrderCancelled, "order", order.ID, map[string]any{
+				"order_id":              order.ID,
+				"user_id":               order.UserID,
+				"refunded_amount":       order.TotalPrice,
+				"refund_transaction_id": refund.ID,
+				"cancelled_at":          order.UpdatedAt,
+			})
+			if err != nil {
+				return err
+			}
+
+			// Зарезервированный при оформлении товар возвращается на склад
+			err = tx.SelectContext(ctx, &restocked, `
+				WITH returned AS (
+					UPDATE merch_variants v
+					SET stock = v.stock + p.quantity,
+						updated_at = CURRENT_TIMESTAMP
+					FROM (
+						SELECT variant_id, SUM(quantity) AS quantity
+						FROM purchases
+						WHERE order_id = $1 AND variant_id IS NOT NULL
+						GROUP BY variant_id
+					) p
+					WHERE v.id = p.variant_id
+					RETURNING v.id, v.stock - p.quantity AS previous
+				)
+				SELECT id FROM returned WHERE previous = 0`,
+				id,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to return order stock: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.attachPurchases(ctx, []*domain.Order{order}); err != nil {
+		return nil, nil, err
+	}
+
+	return order, restocked, nil
+}
+
+func (r *OrderRepository) UpdateDelivery(ctx context.Context, id int64, delivery domain.DeliveryDetails) error {
+	return r.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		var status domain.OrderStatus
+		err := tx.QueryRowContext(ctx, `
+			SELECT status
+			FROM orders
+			WHERE id = $1
+			FOR UPDATE`,
+			id,
+		).Scan(&status)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrOrderNotFound
+			}
+			return fmt.Errorf("failed to get order: %w", err)
+		}
+
+		if status != domain.OrderStatusPlaced {
+			return domain.ErrInvalidOrderStatus
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE orders
+			SET delivery_method = $1,
+				office_location = $2,
+				pickup_point = $3,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $4`,
+			delivery.Method, delivery.OfficeLocation, delivery.PickupPoint, id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update order delivery: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// attachPurchases загружает покупки для переданных заказов одним запросом
+func (r *OrderRepository) attachPurchases(ctx context.Context, orders []*domain.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(orders))
+	byID := make(map[int64]*domain.Order, len(orders))
+	for _, order := range orders {
+		order.Purchases = []*domain.PurchaseResponse{}
+		ids = append(ids, order.ID)
+		byID[order.ID] = order
+	}
+
+	var purchases []*domain.PurchaseResponse
+
+	query := `
+		SELECT p.id, p.user_id, p.merch_id, p.variant_id, p.quantity, p.unit_price, p.total_price, p.order_id, p.created_at,
+			   m.name as merch_name, v.sku as variant_sku, o.status as order_status
+		FROM purchases p
+		JOIN merch m ON p.merch_id = m.id
+		JOIN orders o ON p.order_id = o.id
+		LEFT JOIN merch_variants v ON p.variant_id = v.id
+		WHERE p.order_id = ANY($1)
+		ORDER BY p.id`
+
+	err := r.db.SelectContext(ctx, &purchases, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get order purchases: %w", err)
+	}
+
+	for _, purchase := range purchases {
+		order := byID[purchase.OrderID]
+		order.Purchases = append(order.Purchases, purchase)
+	}
+
+	return nil
+}
+
+// insertOrder создает заказ в статусе placed через переданную транзакцию
+func insertOrder(ctx context.Context, q sqlx.QueryerContext, order *domain.Order) error {
+	if order.Method == "" {
+		order.Method = domain.DeliveryMethodOffice
+	}
+
+	query := `
+		INSERT INTO orders (user_id, total_price, delivery_method, office_location, pickup_point)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, status, created_at, updated_at`
+
+	err := q.QueryRowxContext(ctx, query,
+		order.UserID,
+		order.TotalPrice,
+		order.Method,
+		order.OfficeLocation,
+		order.PickupPoint,
+	).Scan(&order.ID, &order.Status, &order.CreatedAt, &order.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+
+	return nil
+}
+
+// refundOrder возвращает покупателю стоимость заказа переводом от казначейства
+func refundOrder(ctx context.Context, tx *sqlx.Tx, order *domain.Order) (*domain.Transaction, error) {
+	var treasuryID int64
+	err := tx.GetContext(ctx, &treasuryID, `SELECT id FROM users WHERE username = $1 AND is_system`, domain.TreasuryUsername)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get treasury account: %w", err)
+	}
+
+	description := fmt.Sprintf("Возврат за заказ №%d", order.ID)
+	refund := &domain.Transaction{
+		FromUserID:  treasuryID,
+		ToUserID:    order.UserID,
+		Amount:      order.TotalPrice,
+		Description: &description,
+		Kind:        domain.TransactionKindRefund,
+	}
+	if err := transferMoney(ctx, tx, refund); err != nil {
+		return nil, fmt.Errorf("failed to refund order: %w", err)
+	}
+
+	return refund, nil
+}
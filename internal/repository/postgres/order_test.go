@@ -0,0 +1,264 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/avito/internal/domain"
+)
+
+func TestOrderRepositoryCheckout(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	carts, orders := NewCartRepository(repo), NewOrderRepository(repo)
+
+	cup := createTestMerch(t, repo, "test-cup", 20)
+	hoody := createTestMerch(t, repo, "test-hoody", 300)
+
+	tests := []struct {
+		name        string
+		balance     int64
+		cart        map[int64]int
+		wantErr     error
+		wantBalance int64
+		wantTotal   int64
+	}{
+		{
+			name:        "debits total and clears cart",
+			balance:     1000,
+			cart:        map[int64]int{cup.ID: 2, hoody.ID: 1},
+			wantBalance: 660,
+			wantTotal:   340,
+		},
+		{
+			name:        "exact balance",
+			balance:     300,
+			cart:        map[int64]int{hoody.ID: 1},
+			wantBalance: 0,
+			wantTotal:   300,
+		},
+		{
+			name:        "insufficient funds changes nothing",
+			balance:     100,
+			cart:        map[int64]int{cup.ID: 1, hoody.ID: 1},
+			wantErr:     domain.ErrInsufficientFunds,
+			wantBalance: 100,
+		},
+		{
+			name:        "empty cart",
+			balance:     100,
+			wantErr:     domain.ErrCartEmpty,
+			wantBalance: 100,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := createTestUser(t, repo, "checkout-"+string(rune('a'+i)), tt.balance)
+			for merchID, quantity := range tt.cart {
+				if err := carts.AddItem(ctx, user.ID, merchID, nil, quantity); err != nil {
+					t.Fatalf("AddItem: %v", err)
+				}
+			}
+
+			order, err := orders.Checkout(ctx, user.ID, testDelivery())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Checkout() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := userBalance(t, repo, user.ID); got != tt.wantBalance {
+				t.Errorf("balance = %d, want %d", got, tt.wantBalance)
+			}
+
+			cartItems := countRows(t, repo, `SELECT COUNT(*) FROM cart_items WHERE user_id = $1`, user.ID)
+			userOrders := countRows(t, repo, `SELECT COUNT(*) FROM orders WHERE user_id = $1`, user.ID)
+			if tt.wantErr != nil {
+				if cartItems != len(tt.cart) || userOrders != 0 {
+					t.Errorf("cart items = %d, orders = %d after failed checkout, want %d and 0", cartItems, userOrders, len(tt.cart))
+				}
+				return
+			}
+
+			if order.TotalPrice != tt.wantTotal || len(order.Purchases) != len(tt.cart) {
+				t.Errorf("order total = %d with %d purchases, want %d with %d", order.TotalPrice, len(order.Purchases), tt.wantTotal, len(tt.cart))
+			}
+			if cartItems != 0 || userOrders != 1 {
+				t.Errorf("cart items = %d, orders = %d after checkout, want 0 and 1", cartItems, userOrders)
+			}
+		})
+	}
+}
+
+func TestOrderRepositoryCheckoutConcurrent(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	carts, orders := NewCartRepository(repo), NewOrderRepository(repo)
+
+	user := createTestUser(t, repo, "concurrent", 1000)
+	cup := createTestMerch(t, repo, "test-cup", 20)
+	if err := carts.AddItem(ctx, user.ID, cup.ID, nil, 5); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+
+	// Корзина блокируется на время оформления, поэтому ее содержимое списывается ровно один раз
+	const attempts = 5
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = orders.Checkout(ctx, user.ID, testDelivery())
+		}(i)
+	}
+	wg.Wait()
+
+	var succeeded int
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case !errors.Is(err, domain.ErrCartEmpty):
+			t.Errorf("Checkout() error = %v, want nil or ErrCartEmpty", err)
+		}
+	}
+
+	if succeeded != 1 {
+		t.Errorf("successful checkouts = %d, want 1", succeeded)
+	}
+	if got := userBalance(t, repo, user.ID); got != 900 {
+		t.Errorf("balance = %d, want 900", got)
+	}
+}
+
+func TestOrderRepositoryCancelRefunds(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	carts, orders := NewCartRepository(repo), NewOrderRepository(repo)
+
+	user := createTestUser(t, repo, "cancel", 1000)
+	cup := createTestMerch(t, repo, "test-cup", 20)
+	if err := carts.AddItem(ctx, user.ID, cup.ID, nil, 3); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	order, err := orders.Checkout(ctx, user.ID, testDelivery())
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	cancelled, _, err := orders.UpdateStatus(ctx, order.ID, domain.OrderStatusCancelled)
+	if err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if cancelled.Status != domain.OrderStatusCancelled {
+		t.Errorf("status = %q, want %q", cancelled.Status, domain.OrderStatusCancelled)
+	}
+	if got := userBalance(t, repo, user.ID); got != 1000 || cancelled.BuyerBalance != 1000 {
+		t.Errorf("balance after cancel = %d (returned %d), want 1000", got, cancelled.BuyerBalance)
+	}
+
+	// Возврат проводится переводом от казначейства и попадает в историю и outbox
+	var refund domain.Transaction
+	err = repo.db.Get(&refund, `
+		SELECT t.id, t.from_user_id, t.to_user_id, t.amount, t.description, t.kind
+		FROM transactions t
+		JOIN users u ON u.id = t.from_user_id
+		WHERE u.username = $1 AND t.to_user_id = $2`,
+		domain.TreasuryUsername, user.ID,
+	)
+	if err != nil {
+		t.Fatalf("failed to get refund transaction: %v", err)
+	}
+	if refund.Amount != order.TotalPrice || refund.Kind != domain.TransactionKindRefund {
+		t.Errorf("refund = %+v, want kind %q and amount %d", refund, domain.TransactionKindRefund, order.TotalPrice)
+	}
+	if got := userBalance(t, repo, refund.FromUserID); got != -order.TotalPrice {
+		t.Errorf("treasury balance = %d, want %d", got, -order.TotalPrice)
+	}
+	if got := countRows(t, repo, `SELECT COUNT(*) FROM outbox WHERE event_type = $1 AND aggregate_id = $2`,
+		domain.OutboxEventTransferCompleted, refund.ID); got != 1 {
+		t.Errorf("transfer.completed events for refund = %d, want 1", got)
+	}
+
+	// Повторная отмена не возвращает монеты второй раз
+	if _, _, err := orders.UpdateStatus(ctx, order.ID, domain.OrderStatusCancelled); !errors.Is(err, domain.ErrInvalidOrderStatus) {
+		t.Errorf("second cancel error = %v, want ErrInvalidOrderStatus", err)
+	}
+	if got := userBalance(t, repo, user.ID); got != 1000 {
+		t.Errorf("balance after second cancel = %d, want 1000", got)
+	}
+	if got := countRows(t, repo, `SELECT COUNT(*) FROM transactions WHERE to_user_id = $1`, user.ID); got != 1 {
+		t.Errorf("refund transactions = %d, want 1", got)
+	}
+	if got := countRows(t, repo, `SELECT COUNT(*) FROM outbox WHERE event_type = $1 AND aggregate_id = $2`,
+		domain.OutboxEventOrderCancelled, order.ID); got != 1 {
+		t.Errorf("order.cancelled events = %d, want 1", got)
+	}
+
+	// Покупки являются строками заказа и удаляются вместе с ним
+	if _, err := repo.db.Exec(`DELETE FROM orders WHERE id = $1`, order.ID); err != nil {
+		t.Fatalf("failed to delete order: %v", err)
+	}
+	if got := countRows(t, repo, `SELECT COUNT(*) FROM purchases WHERE user_id = $1`, user.ID); got != 0 {
+		t.Errorf("purchases after order delete = %d, want 0", got)
+	}
+}
+
+func TestOrderRepositoryCheckoutReservesStock(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	carts, orders := NewCartRepository(repo), NewOrderRepository(repo)
+
+	shirt := createTestMerch(t, repo, "test-shirt", 80)
+	size := createTestVariant(t, repo, shirt.ID, "test-shirt-m", 2)
+	first := createTestUser(t, repo, "first", 1000)
+	second := createTestUser(t, repo, "second", 1000)
+
+	// Первый покупатель забирает весь остаток
+	if err := carts.AddItem(ctx, first.ID, shirt.ID, &size.ID, 2); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	order, err := orders.Checkout(ctx, first.ID, testDelivery())
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if got := variantStock(t, repo, size.ID); got != 0 {
+		t.Fatalf("stock after checkout = %d, want 0", got)
+	}
+
+	// Второму не хватает товара: ни баланс, ни корзина не меняются
+	if err := carts.AddItem(ctx, second.ID, shirt.ID, &size.ID, 1); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if _, err := orders.Checkout(ctx, second.ID, testDelivery()); !errors.Is(err, domain.ErrOutOfStock) {
+		t.Fatalf("Checkout() error = %v, want ErrOutOfStock", err)
+	}
+	if got := userBalance(t, repo, second.ID); got != 1000 {
+		t.Errorf("balance after failed checkout = %d, want 1000", got)
+	}
+	if got := countRows(t, repo, `SELECT COUNT(*) FROM cart_items WHERE user_id = $1`, second.ID); got != 1 {
+		t.Errorf("cart items after failed checkout = %d, want 1", got)
+	}
+
+	// Отмена возвращает товар на склад, после чего второй покупатель может оформить заказ
+	_, restocked, err := orders.UpdateStatus(ctx, order.ID, domain.OrderStatusCancelled)
+	if err != nil {
+		t.Fatalf("UpdateStatus: %v", err)
+	}
+	if !equalInt64s(restocked, []int64{size.ID}) {
+		t.Errorf("restocked variants = %v, want [%d]", restocked, size.ID)
+	}
+	if got := variantStock(t, repo, size.ID); got != 2 {
+		t.Fatalf("stock after cancel = %d, want 2", got)
+	}
+	if _, err := orders.Checkout(ctx, second.ID, testDelivery()); err != nil {
+		t.Fatalf("Checkout after restock: %v", err)
+	}
+	if got := variantStock(t, repo, size.ID); got != 1 {
+		t.Errorf("stock after second checkout = %d, want 1", got)
+	}
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type OutboxRepository struct {
+	*Repository
+}
+
+func NewOutboxRepository(repo *Repository) *OutboxRepository {
+	return &OutboxRepository{Repository: repo}
+}
+
+func (r *OutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxEvent, error) {
+	var events []*domain.OutboxEvent
+
+	// SKIP LOCKED позволяет воркерам нескольких реплик разбирать очередь параллельно
+	query := `
+		UPDATE outbox
+		SET available_at = CURRENT_TIMESTAMP + $2 * INTERVAL '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM outbox
+			WHERE published_at IS NULL AND available_at <= CURRENT_TIMESTAMP
+			ORDER BY created_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_type, aggregate_type, aggregate_id, payload, attempts, created_at`
+
+	err := r.db.SelectContext(ctx, &events, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox
+		SET published_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL
+		WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event published: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id string, retryAt time.Time, cause string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = $2, available_at = $3
+		WHERE id = $1`,
+		id, cause, retryAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+
+	return nil
+}
+
+// insertOutboxEvent записывает событие в outbox. Вызывается внутри транзакции,
+// изменяющей данные, чтобы событие появилось тогда и только тогда, когда изменение зафиксировано.
+func insertOutboxEvent(ctx context.Context, tx *sqlx.Tx, eventType, aggregateType string, aggregateID int64, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox payload: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO outbox (event_type, aggregate_type, aggregate_id, payload)
+		VALUES ($1, $2, $3, $4)`,
+		eventType, aggregateType, aggregateID, string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write outbox event: %w", err)
+	}
+
+	return nil
+}
+
+// insertPurchaseEvent записывает событие о созданной покупке
+func insertPurchaseEvent(ctx context.Context, tx *sqlx.Tx, purchase *domain.Purchase) error {
+	return insertOutboxEvent(ctx, tx, domain.OutboxEventPurchaseCreated, "purchase", purchase.ID, map[string]any{
+		"purchase_id": purchase.ID,
+		"order_id":    purchase.OrderID,
+		"user_id":     purchase.UserID,
+		"merch_id":    purchase.MerchID,
+		"variant_id":  purchase.VariantID,
+		"quantity":    purchase.Quantity,
+		"unit_price":  purchase.UnitPrice,
+		"total_price": purchase.TotalPrice,
+		"created_at":  purchase.CreatedAt,
+	})
+}
@@ -0,0 +1,112 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+func TestTransferMoneyWritesOutboxEvent(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	transactions := NewTransactionRepository(repo)
+
+	alice := createTestUser(t, repo, "alice", 100)
+	bob := createTestUser(t, repo, "bob", 0)
+
+	transaction := &domain.Transaction{FromUserID: alice.ID, ToUserID: bob.ID, Amount: 30}
+	if err := transactions.TransferMoney(ctx, transaction); err != nil {
+		t.Fatalf("TransferMoney: %v", err)
+	}
+	if transaction.SenderBalance != 70 || transaction.RecipientBalance != 30 {
+		t.Errorf("balances after transfer = %d/%d, want 70/30", transaction.SenderBalance, transaction.RecipientBalance)
+	}
+
+	events, err := NewOutboxRepository(repo).Claim(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if len(events) != 1 || events[0].EventType != domain.OutboxEventTransferCompleted || events[0].AggregateID != transaction.ID {
+		t.Fatalf("claimed events = %+v, want transfer.completed for transaction %d", events, transaction.ID)
+	}
+
+	var payload struct {
+		Amount int64 `json:"amount"`
+	}
+	if err := json.Unmarshal(events[0].Payload, &payload); err != nil || payload.Amount != 30 {
+		t.Errorf("payload = %s, want amount 30 (%v)", events[0].Payload, err)
+	}
+
+	// Неудачный перевод не оставляет события
+	failed := &domain.Transaction{FromUserID: bob.ID, ToUserID: alice.ID, Amount: 1000}
+	if err := transactions.TransferMoney(ctx, failed); err == nil {
+		t.Fatal("TransferMoney without funds succeeded")
+	}
+	if got := countRows(t, repo, `SELECT COUNT(*) FROM outbox`); got != 1 {
+		t.Errorf("outbox events = %d, want 1", got)
+	}
+}
+
+func TestOutboxRepositoryClaim(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	outbox := NewOutboxRepository(repo)
+
+	for i := int64(1); i <= 3; i++ {
+		err := repo.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+			return insertOutboxEvent(ctx, tx, domain.OutboxEventPurchaseCreated, "purchase", i, map[string]any{"purchase_id": i})
+		})
+		if err != nil {
+			t.Fatalf("insertOutboxEvent: %v", err)
+		}
+	}
+
+	first, err := outbox.Claim(ctx, 2, time.Minute)
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if len(first) != 2 || first[0].AggregateID != 1 || first[1].AggregateID != 2 {
+		t.Fatalf("first claim = %+v, want events 1 and 2", first)
+	}
+
+	// Зарезервированные события не выдаются повторно до истечения аренды
+	second, err := outbox.Claim(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if len(second) != 1 || second[0].AggregateID != 3 {
+		t.Fatalf("second claim = %+v, want event 3", second)
+	}
+
+	if err := outbox.MarkPublished(ctx, first[0].ID); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+	// Ошибка с retryAt в прошлом делает событие доступным сразу, с увеличенным счетчиком попыток
+	if err := outbox.MarkFailed(ctx, first[1].ID, time.Now().Add(-time.Second), "connection refused"); err != nil {
+		t.Fatalf("MarkFailed: %v", err)
+	}
+	if err := outbox.MarkFailed(ctx, second[0].ID, time.Now().Add(time.Hour), "connection refused"); err != nil {
+		t.Fatalf("MarkFailed: %v", err)
+	}
+
+	retried, err := outbox.Claim(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	if len(retried) != 1 || retried[0].ID != first[1].ID || retried[0].Attempts != 1 {
+		t.Fatalf("retry claim = %+v, want event 2 with one attempt", retried)
+	}
+
+	if got := countRows(t, repo, `SELECT COUNT(*) FROM outbox WHERE published_at IS NOT NULL`); got != 1 {
+		t.Errorf("published events = %d, want 1", got)
+	}
+	if got := countRows(t, repo, `SELECT COUNT(*) FROM outbox WHERE last_error = 'connection refused'`); got != 2 {
+		t.Errorf("failed events = %d, want 2", got)
+	}
+}
@@ -2,10 +2,18 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/XSAM/otelsql"
+	"github.com/avito/pkg/pagination"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Repository представляет собой обертку над подключением к базе данных
@@ -15,11 +23,26 @@ type Repository struct {
 
 // New создает новый экземпляр Repository
 func New(dsn string) (*Repository, error) {
-	db, err := sqlx.Connect("postgres", dsn)
+	// Каждый запрос к базе становится дочерним спаном запроса, в рамках которого выполняется
+	sqlDB, err := otelsql.Open("postgres", dsn,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+			SpanFilter:           withinTrace,
+		}),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	db := sqlx.NewDb(sqlDB, "postgres")
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
 	// Устанавливаем максимальное количество открытых соединений
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(25)
@@ -27,6 +50,17 @@ func New(dsn string) (*Repository, error) {
 	return &Repository{db: db}, nil
 }
 
+// withinTrace отбрасывает запросы вне трассы, например периодический опрос outbox,
+// чтобы он не порождал по корневому спану каждую секунду
+func withinTrace(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+	return trace.SpanContextFromContext(ctx).IsValid()
+}
+
+// DB возвращает пул соединений для сбора статистики
+func (r *Repository) DB() *sql.DB {
+	return r.db.DB
+}
+
 // Close закрывает соединение с базой данных
 func (r *Repository) Close() error {
 	return r.db.Close()
@@ -59,3 +93,83 @@ func (r *Repository) Transaction(ctx context.Context, fn func(ctx context.Contex
 
 	return nil
 }
+
+// snapshotKey - ключ контекста, под которым лежит транзакция ReadSnapshot
+type snapshotKey struct{}
+
+// ReadSnapshot выполняет fn в read-only транзакции REPEATABLE READ: все чтения
+// с переданным в fn контекстом видят один и тот же снимок базы
+func (r *Repository) ReadSnapshot(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin snapshot: %w", err)
+	}
+	// Транзакция ничего не пишет, поэтому откат просто освобождает соединение
+	defer func() { _ = tx.Rollback() }()
+
+	return fn(context.WithValue(ctx, snapshotKey{}, tx))
+}
+
+// queryer выполняет запросы на подключении или в транзакции
+type queryer interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// snapshotTx возвращает транзакцию ReadSnapshot из контекста
+func snapshotTx(ctx context.Context) (*sqlx.Tx, bool) {
+	tx, ok := ctx.Value(snapshotKey{}).(*sqlx.Tx)
+	return tx, ok
+}
+
+// queryer возвращает транзакцию снимка, если запрос выполняется внутри ReadSnapshot
+func (r *Repository) queryer(ctx context.Context) queryer {
+	if tx, ok := snapshotTx(ctx); ok {
+		return tx
+	}
+	return r.db
+}
+
+// timeCursor хранит ключ (created_at, id) последней записи страницы истории
+type timeCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"id"`
+}
+
+// decodeTimeCursor возвращает nil для первой страницы
+func decodeTimeCursor(params pagination.Params) (*timeCursor, error) {
+	if params.IsFirst() {
+		return nil, nil
+	}
+
+	cursor := &timeCursor{}
+	if err := pagination.DecodeCursor(params.Cursor, cursor); err != nil {
+		return nil, err
+	}
+
+	return cursor, nil
+}
+
+// after возвращает параметры запроса для условия (created_at, id) < (после, id)
+func (c *timeCursor) after() (*time.Time, int64) {
+	if c == nil {
+		return nil, 0
+	}
+	return &c.CreatedAt, c.ID
+}
+
+// likeEscaper экранирует спецсимволы шаблона LIKE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// whereClause объединяет условия фильтрации через AND
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "\n\t\tWHERE " + strings.Join(conditions, "\n\t\t  AND ")
+}
@@ -5,8 +5,11 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+	"github.com/jmoiron/sqlx"
 )
 
 type PurchaseRepository struct {
@@ -18,15 +21,80 @@ func NewPurchaseRepository(repo *Repository) *PurchaseRepository {
 }
 
 func (r *PurchaseRepository) Create(ctx context.Context, purchase *domain.Purchase) error {
+	return insertPurchase(ctx, r.db, purchase)
+}
+
+func (r *PurchaseRepository) Buy(ctx context.Context, purchase *domain.Purchase) error {
+	return r.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		var balance int64
+		err := tx.QueryRowContext(ctx, `
+			SELECT balance
+			FROM users
+			WHERE id = $1
+			FOR UPDATE`,
+			purchase.UserID,
+		).Scan(&balance)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.ErrUserNotFound
+			}
+			return fmt.Errorf("failed to get user balance: %w", err)
+		}
+
+		if balance < purchase.TotalPrice {
+			return fmt.Errorf("%w: available %d, required %d", domain.ErrInsufficientFunds, balance, purchase.TotalPrice)
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			UPDATE users
+			SET balance = balance - $1,
+				updated_at = CURRENT_TIMESTAMP
+			WHERE id = $2
+			RETURNING balance`,
+			purchase.TotalPrice, purchase.UserID,
+		).Scan(&purchase.BuyerBalance)
+		if err != nil {
+			return fmt.Errorf("failed to update user balance: %w", err)
+		}
+
+		if purchase.VariantID != nil {
+			if err := reserveStock(ctx, tx, *purchase.VariantID, purchase.Quantity); err != nil {
+				return err
+			}
+		}
+
+		order := &domain.Order{
+			UserID:     purchase.UserID,
+			TotalPrice: purchase.TotalPrice,
+		}
+		if err := insertOrder(ctx, tx, order); err != nil {
+			return err
+		}
+		purchase.OrderID = order.ID
+
+		if err := insertPurchase(ctx, tx, purchase); err != nil {
+			return err
+		}
+
+		return insertPurchaseEvent(ctx, tx, purchase)
+	})
+}
+
+// insertPurchase создает запись о покупке через переданное подключение или транзакцию
+func insertPurchase(ctx context.Context, q sqlx.QueryerContext, purchase *domain.Purchase) error {
 	query := `
-		INSERT INTO purchases (user_id, merch_id, quantity)
-		VALUES ($1, $2, $3)
+		INSERT INTO purchases (user_id, merch_id, variant_id, quantity, unit_price, total_price, order_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id, created_at`
 
-	err := r.db.QueryRowxContext(ctx, query,
+	err := q.QueryRowxContext(ctx, query,
 		purchase.UserID,
 		purchase.MerchID,
+		purchase.VariantID,
 		purchase.Quantity,
+		purchase.UnitPrice,
+		purchase.TotalPrice,
+		purchase.OrderID,
 	).Scan(&purchase.ID, &purchase.CreatedAt)
 
 	if err != nil {
@@ -40,7 +108,7 @@ func (r *PurchaseRepository) GetByID(ctx context.Context, id int64) (*domain.Pur
 	purchase := &domain.Purchase{}
 
 	query := `
-		SELECT id, user_id, merch_id, quantity, created_at
+		SELECT id, user_id, merch_id, variant_id, quantity, unit_price, total_price, order_id, created_at
 		FROM purchases
 		WHERE id = $1`
 
@@ -55,21 +123,84 @@ func (r *PurchaseRepository) GetByID(ctx context.Context, id int64) (*domain.Pur
 	return purchase, nil
 }
 
-func (r *PurchaseRepository) GetByUserID(ctx context.Context, userID int64) ([]*domain.PurchaseResponse, error) {
+func (r *PurchaseRepository) GetByUserID(ctx context.Context, userID int64, params pagination.Params) (*pagination.Page[*domain.PurchaseResponse], error) {
+	cursor, err := decodeTimeCursor(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if params.IsFirst() {
+		total = new(int64)
+		err := r.db.GetContext(ctx, total, `SELECT COUNT(*) FROM purchases WHERE user_id = $1`, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count user purchases: %w", err)
+		}
+	}
+
 	var purchases []*domain.PurchaseResponse
 
 	query := `
-		SELECT p.id, p.user_id, p.merch_id, p.quantity, p.created_at,
-			   m.name as merch_name, m.price as merch_price
+		SELECT p.id, p.user_id, p.merch_id, p.variant_id, p.quantity, p.unit_price, p.total_price, p.order_id, p.created_at,
+			   m.name as merch_name, v.sku as variant_sku, o.status as order_status
 		FROM purchases p
 		JOIN merch m ON p.merch_id = m.id
+		JOIN orders o ON p.order_id = o.id
+		LEFT JOIN merch_variants v ON p.variant_id = v.id
 		WHERE p.user_id = $1
-		ORDER BY p.created_at DESC`
+		  AND ($2::timestamptz IS NULL OR (p.created_at, p.id) < ($2, $3))
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $4`
 
-	err := r.db.SelectContext(ctx, &purchases, query, userID)
+	createdAt, id := cursor.after()
+	err = r.db.SelectContext(ctx, &purchases, query, userID, createdAt, id, params.Limit+1)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user purchases: %w", err)
 	}
 
-	return purchases, nil
+	page, err := pagination.NewPage(purchases, params.Limit, func(last *domain.PurchaseResponse) any {
+		return timeCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	})
+	if err != nil {
+		return nil, err
+	}
+	page.Total = total
+
+	return page, nil
+}
+
+func (r *PurchaseRepository) StatementEntries(ctx context.Context, userID int64, from, to time.Time) (domain.StatementIterator, error) {
+	// Возвраты за отмененные заказы проводятся переводами от казначейства и попадают в выписку вместе с ними
+	query := `
+		SELECT p.created_at, 'purchase' as type, p.order_id as reference_id,
+			   -p.total_price as amount, NULL as counterparty,
+			   m.name || COALESCE(' (' || v.sku || ')', '') || ' x' || p.quantity as description
+		FROM purchases p
+		JOIN merch m ON p.merch_id = m.id
+		LEFT JOIN merch_variants v ON p.variant_id = v.id
+		WHERE p.user_id = $1 AND p.created_at >= $2 AND p.created_at < $3
+		ORDER BY p.created_at, p.id`
+
+	rows, err := r.openStatementRows(ctx, "statement_purchases", query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement purchases: %w", err)
+	}
+
+	return rows, nil
+}
+
+func (r *PurchaseRepository) NetChangeSince(ctx context.Context, userID int64, since time.Time) (int64, error) {
+	var change int64
+
+	query := `
+		SELECT -COALESCE(SUM(total_price), 0)
+		FROM purchases
+		WHERE user_id = $1 AND created_at >= $2`
+
+	err := r.queryer(ctx).GetContext(ctx, &change, query, userID, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get purchases balance change: %w", err)
+	}
+
+	return change, nil
 }
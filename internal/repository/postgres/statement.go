@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/avito/internal/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+// statementFetchSize - сколько строк выписки читается из курсора за один запрос
+const statementFetchSize = 500
+
+// statementRows реализует domain.StatementIterator поверх серверного курсора.
+// Курсор вместо открытого результата нужен, чтобы в одной транзакции снимка
+// одновременно читать несколько выборок: драйвер не умеет держать их открытыми на одном соединении.
+type statementRows struct {
+	ctx   context.Context
+	tx    *sqlx.Tx
+	name  string
+	owned bool // транзакция открыта самим курсором и закрывается вместе с ним
+
+	batch []*domain.StatementEntry
+	pos   int
+	done  bool
+	entry *domain.StatementEntry
+	err   error
+}
+
+// openStatementRows объявляет курсор name в транзакции снимка из контекста,
+// а вне ReadSnapshot - в собственной read-only транзакции
+func (r *Repository) openStatementRows(ctx context.Context, name, query string, args ...interface{}) (*statementRows, error) {
+	tx, ok := snapshotTx(ctx)
+	owned := !ok
+	if owned {
+		var err error
+		tx, err = r.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			return nil, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DECLARE `+name+` NO SCROLL CURSOR FOR `+query, args...); err != nil {
+		if owned {
+			_ = tx.Rollback()
+		}
+		return nil, err
+	}
+
+	return &statementRows{ctx: ctx, tx: tx, name: name, owned: owned}, nil
+}
+
+func (it *statementRows) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.pos == len(it.batch) {
+		if it.done {
+			return false
+		}
+
+		it.batch, it.pos = nil, 0
+		err := it.tx.SelectContext(it.ctx, &it.batch, fmt.Sprintf(`FETCH %d FROM %s`, statementFetchSize, it.name))
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.done = len(it.batch) < statementFetchSize
+		if len(it.batch) == 0 {
+			return false
+		}
+	}
+
+	it.entry = it.batch[it.pos]
+	it.pos++
+	return true
+}
+
+func (it *statementRows) Entry() *domain.StatementEntry {
+	return it.entry
+}
+
+func (it *statementRows) Err() error {
+	return it.err
+}
+
+func (it *statementRows) Close() error {
+	if it.owned {
+		// Откат закрывает и курсор
+		return it.tx.Rollback()
+	}
+
+	_, err := it.tx.ExecContext(it.ctx, `CLOSE `+it.name)
+	return err
+}
@@ -0,0 +1,129 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+func TestStatementEntriesInSnapshot(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	transactions, purchases := NewTransactionRepository(repo), NewPurchaseRepository(repo)
+	carts, orders := NewCartRepository(repo), NewOrderRepository(repo)
+
+	alice := createTestUser(t, repo, "alice", 1000)
+	bob := createTestUser(t, repo, "bob", 1000)
+	cup := createTestMerch(t, repo, "test-cup", 20)
+
+	from := time.Now().Add(-time.Minute)
+
+	// Записей больше размера пачки FETCH, чтобы оба курсора дочитывались несколькими запросами
+	const transfers = statementFetchSize + 10
+	for i := 0; i < transfers; i++ {
+		if err := transactions.Create(ctx, &domain.Transaction{FromUserID: bob.ID, ToUserID: alice.ID, Amount: 1}); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	if err := carts.AddItem(ctx, alice.ID, cup.ID, nil, 2); err != nil {
+		t.Fatalf("AddItem: %v", err)
+	}
+	if _, err := orders.Checkout(ctx, alice.ID, testDelivery()); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+
+	to := time.Now().Add(time.Minute)
+
+	err := repo.ReadSnapshot(ctx, func(ctx context.Context) error {
+		before, err := NewUserRepository(repo).GetByID(ctx, alice.ID)
+		if err != nil {
+			return err
+		}
+
+		// Снимок фиксируется первым запросом, изменение баланса после него внутри не видно
+		if err := NewTransactionRepository(repo).TransferMoney(context.Background(), &domain.Transaction{FromUserID: bob.ID, ToUserID: alice.ID, Amount: 5}); err != nil {
+			t.Fatalf("TransferMoney: %v", err)
+		}
+
+		after, err := NewUserRepository(repo).GetByID(ctx, alice.ID)
+		if err != nil {
+			return err
+		}
+		if after.Balance != before.Balance {
+			t.Errorf("balance inside snapshot changed from %d to %d", before.Balance, after.Balance)
+		}
+
+		change, err := transactions.NetChangeSince(ctx, alice.ID, from)
+		if err != nil {
+			return err
+		}
+		if change != transfers {
+			t.Errorf("transfers change = %d, want %d", change, transfers)
+		}
+
+		// Оба курсора открыты одновременно и читаются вперемешку
+		transferRows, err := transactions.StatementEntries(ctx, alice.ID, from, to)
+		if err != nil {
+			return err
+		}
+		defer transferRows.Close()
+
+		purchaseRows, err := purchases.StatementEntries(ctx, alice.ID, from, to)
+		if err != nil {
+			return err
+		}
+		defer purchaseRows.Close()
+
+		var gotTransfers, gotPurchases int
+		for hasTransfer, hasPurchase := transferRows.Next(), purchaseRows.Next(); hasTransfer || hasPurchase; {
+			if hasTransfer {
+				gotTransfers++
+				hasTransfer = transferRows.Next()
+			}
+			if hasPurchase {
+				if entry := purchaseRows.Entry(); entry.Amount != -40 {
+					t.Errorf("purchase amount = %d, want -40", entry.Amount)
+				}
+				gotPurchases++
+				hasPurchase = purchaseRows.Next()
+			}
+		}
+		if err := transferRows.Err(); err != nil {
+			return err
+		}
+		if err := purchaseRows.Err(); err != nil {
+			return err
+		}
+
+		if gotTransfers != transfers || gotPurchases != 1 {
+			t.Errorf("entries = %d transfers and %d purchases, want %d and 1", gotTransfers, gotPurchases, transfers)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+
+	// Вне снимка курсор открывается в собственной транзакции
+	rows, err := transactions.StatementEntries(ctx, alice.ID, from, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatalf("StatementEntries: %v", err)
+	}
+	var count int
+	for rows.Next() {
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("StatementEntries rows: %v", err)
+	}
+	if err := rows.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if count != transfers {
+		t.Errorf("entries outside snapshot = %d, want %d", count, transfers)
+	}
+}
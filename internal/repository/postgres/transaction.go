@@ -5,8 +5,10 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -19,16 +21,30 @@ func NewTransactionRepository(repo *Repository) *TransactionRepository {
 }
 
 func (r *TransactionRepository) Create(ctx context.Context, transaction *domain.Transaction) error {
+	return insertTransaction(ctx, r.db, transaction)
+}
+
+// insertTransaction создает запись о переводе через переданное подключение или транзакцию
+func insertTransaction(ctx context.Context, q sqlx.QueryerContext, transaction *domain.Transaction) error {
+	if transaction.Kind == "" {
+		transaction.Kind = domain.TransactionKindTransfer
+	}
+
 	query := `
-		INSERT INTO transactions (from_user_id, to_user_id, amount, description)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO transactions (from_user_id, to_user_id, amount, description, kind, reason_code, created_by, is_public, category)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at`
 
-	err := r.db.QueryRowxContext(ctx, query,
+	err := q.QueryRowxContext(ctx, query,
 		transaction.FromUserID,
 		transaction.ToUserID,
 		transaction.Amount,
 		transaction.Description,
+		transaction.Kind,
+		transaction.ReasonCode,
+		transaction.CreatedBy,
+		transaction.IsPublic,
+		transaction.Category,
 	).Scan(&transaction.ID, &transaction.CreatedAt)
 
 	if err != nil {
@@ -42,7 +58,7 @@ func (r *TransactionRepository) GetByID(ctx context.Context, id int64) (*domain.
 	transaction := &domain.Transaction{}
 
 	query := `
-		SELECT id, from_user_id, to_user_id, amount, description, created_at
+		SELECT id, from_user_id, to_user_id, amount, description, kind, reason_code, created_by, is_public, category, created_at
 		FROM transactions
 		WHERE id = $1`
 
@@ -57,63 +73,205 @@ func (r *TransactionRepository) GetByID(ctx context.Context, id int64) (*domain.
 	return transaction, nil
 }
 
-func (r *TransactionRepository) GetByUserID(ctx context.Context, userID int64) ([]*domain.Transaction, error) {
+func (r *TransactionRepository) GetByUserID(ctx context.Context, userID int64, filter domain.TransactionFilter, params pagination.Params) (*pagination.Page[*domain.Transaction], error) {
+	cursor, err := decodeTimeCursor(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	user := arg(userID)
+	var conditions []string
+
+	switch filter.Direction {
+	case domain.TransactionDirectionSent:
+		conditions = append(conditions, "t.from_user_id = "+user)
+	case domain.TransactionDirectionReceived:
+		conditions = append(conditions, "t.to_user_id = "+user)
+	default:
+		conditions = append(conditions, "(t.from_user_id = "+user+" OR t.to_user_id = "+user+")")
+	}
+
+	if filter.Counterparty != "" {
+		// Контрагент - вторая сторона перевода, а не сам пользователь
+		conditions = append(conditions, fmt.Sprintf(
+			"((t.from_user_id = %[1]s AND tu.username = %[2]s) OR (t.to_user_id = %[1]s AND fu.username = %[2]s))",
+			user, arg(filter.Counterparty)))
+	}
+	if filter.MinAmount != nil {
+		conditions = append(conditions, "t.amount >= "+arg(*filter.MinAmount))
+	}
+	if filter.MaxAmount != nil {
+		conditions = append(conditions, "t.amount <= "+arg(*filter.MaxAmount))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "t.created_at >= "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "t.created_at < "+arg(*filter.To))
+	}
+	if filter.Description != "" {
+		conditions = append(conditions, "t.description ILIKE '%' || "+arg(escapeLike(filter.Description))+" || '%'")
+	}
+
+	const from = `
+		FROM transactions t
+		JOIN users fu ON t.from_user_id = fu.id
+		JOIN users tu ON t.to_user_id = tu.id`
+
+	var total *int64
+	if params.IsFirst() {
+		total = new(int64)
+		err := r.db.GetContext(ctx, total, `SELECT COUNT(*)`+from+whereClause(conditions), args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count user transactions: %w", err)
+		}
+	}
+
+	if cursor != nil {
+		conditions = append(conditions, fmt.Sprintf("(t.created_at, t.id) < (%s, %s)", arg(cursor.CreatedAt), arg(cursor.ID)))
+	}
+
+	query := `
+		SELECT t.id, t.from_user_id, t.to_user_id, t.amount, t.description, t.kind, t.reason_code, t.created_by,
+			   t.is_public, t.category, t.created_at,
+			   fu.username as from_username, tu.username as to_username` + from + whereClause(conditions) + `
+		ORDER BY t.created_at DESC, t.id DESC
+		LIMIT ` + arg(params.Limit+1)
+
 	var transactions []*domain.Transaction
+	err = r.db.SelectContext(ctx, &transactions, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user transactions: %w", err)
+	}
+
+	page, err := pagination.NewPage(transactions, params.Limit, func(last *domain.Transaction) any {
+		return timeCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	})
+	if err != nil {
+		return nil, err
+	}
+	page.Total = total
+
+	return page, nil
+}
 
+func (r *TransactionRepository) StatementEntries(ctx context.Context, userID int64, from, to time.Time) (domain.StatementIterator, error) {
 	query := `
-		SELECT id, from_user_id, to_user_id, amount, description, created_at
+		SELECT t.created_at, t.id as reference_id, t.description,
+			   CASE WHEN t.kind <> 'transfer' THEN t.kind
+					WHEN t.to_user_id = $1 THEN 'transfer_in' ELSE 'transfer_out' END as type,
+			   CASE WHEN t.to_user_id = $1 THEN t.amount ELSE -t.amount END as amount,
+			   CASE WHEN t.to_user_id = $1 THEN fu.username ELSE tu.username END as counterparty
+		FROM transactions t
+		JOIN users fu ON t.from_user_id = fu.id
+		JOIN users tu ON t.to_user_id = tu.id
+		WHERE (t.from_user_id = $1 OR t.to_user_id = $1)
+		  AND t.created_at >= $2 AND t.created_at < $3
+		ORDER BY t.created_at, t.id`
+
+	rows, err := r.openStatementRows(ctx, "statement_transfers", query, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement transactions: %w", err)
+	}
+
+	return rows, nil
+}
+
+func (r *TransactionRepository) NetChangeSince(ctx context.Context, userID int64, since time.Time) (int64, error) {
+	var change int64
+
+	query := `
+		SELECT COALESCE(SUM(CASE WHEN to_user_id = $1 THEN amount ELSE -amount END), 0)
 		FROM transactions
-		WHERE from_user_id = $1 OR to_user_id = $1
-		ORDER BY created_at DESC`
+		WHERE (from_user_id = $1 OR to_user_id = $1)
+		  AND created_at >= $2`
 
-	err := r.db.SelectContext(ctx, &transactions, query, userID)
+	err := r.queryer(ctx).GetContext(ctx, &change, query, userID, since)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user transactions: %w", err)
+		return 0, fmt.Errorf("failed to get transactions balance change: %w", err)
 	}
 
-	return transactions, nil
+	return change, nil
 }
 
-func (r *TransactionRepository) TransferMoney(ctx context.Context, fromUserID, toUserID int64, amount int64) error {
+func (r *TransactionRepository) TransferMoney(ctx context.Context, transaction *domain.Transaction) error {
 	return r.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
-		var senderBalance int64
-		err := tx.QueryRowContext(ctx, `
-			SELECT balance 
-			FROM users 
-			WHERE id = $1 
-			FOR UPDATE`,
-			fromUserID,
-		).Scan(&senderBalance)
-		if err != nil {
-			return fmt.Errorf("failed to get sender balance: %w", err)
-		}
+		return transferMoney(ctx, tx, transaction)
+	})
+}
 
-		if senderBalance < amount {
-			return fmt.Errorf("insufficient funds: available %d, required %d", senderBalance, amount)
-		}
+// transferMoney проводит перевод в переданной транзакции: меняет балансы участников,
+// создает запись о переводе и событие outbox
+func transferMoney(ctx context.Context, tx *sqlx.Tx, transaction *domain.Transaction) error {
+	fromUserID, toUserID, amount := transaction.FromUserID, transaction.ToUserID, transaction.Amount
 
-		_, err = tx.ExecContext(ctx, `
-			UPDATE users 
-			SET balance = balance - $1, 
-				updated_at = CURRENT_TIMESTAMP
-			WHERE id = $2`,
-			amount, fromUserID,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to update sender balance: %w", err)
-		}
+	var senderBalance int64
+	var senderIsSystem bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT balance, is_system
+		FROM users 
+		WHERE id = $1 
+		FOR UPDATE`,
+		fromUserID,
+	).Scan(&senderBalance, &senderIsSystem)
+	if err != nil {
+		return fmt.Errorf("failed to get sender balance: %w", err)
+	}
 
-		_, err = tx.ExecContext(ctx, `
-			UPDATE users 
-			SET balance = balance + $1, 
-				updated_at = CURRENT_TIMESTAMP
-			WHERE id = $2`,
-			amount, toUserID,
-		)
-		if err != nil {
-			return fmt.Errorf("failed to update recipient balance: %w", err)
-		}
+	// Казначейство выпускает монеты, поэтому его баланс может уходить в минус
+	if !senderIsSystem && senderBalance < amount {
+		return fmt.Errorf("%w: available %d, required %d", domain.ErrInsufficientFunds, senderBalance, amount)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users 
+		SET balance = balance - $1, 
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING balance`,
+		amount, fromUserID,
+	).Scan(&transaction.SenderBalance)
+	if err != nil {
+		return fmt.Errorf("failed to update sender balance: %w", err)
+	}
+
+	// Строки участников заблокированы до конца транзакции, поэтому балансы отражают
+	// именно этот перевод, а не одновременные с ним
+	err = tx.QueryRowContext(ctx, `
+		UPDATE users 
+		SET balance = balance + $1, 
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING balance`,
+		amount, toUserID,
+	).Scan(&transaction.RecipientBalance)
+	if err != nil {
+		return fmt.Errorf("failed to update recipient balance: %w", err)
+	}
+
+	if err := insertTransaction(ctx, tx, transaction); err != nil {
+		return err
+	}
+
+	return insertTransferEvent(ctx, tx, transaction)
+}
 
-		return nil
+// insertTransferEvent кладет в outbox событие о проведенном переводе
+func insertTransferEvent(ctx context.Context, tx *sqlx.Tx, transaction *domain.Transaction) error {
+	return insertOutboxEvent(ctx, tx, domain.OutboxEventTransferCompleted, "transaction", transaction.ID, map[string]any{
+		"transaction_id": transaction.ID,
+		"from_user_id":   transaction.FromUserID,
+		"to_user_id":     transaction.ToUserID,
+		"amount":         transaction.Amount,
+		"description":    transaction.Description,
+		"kind":           transaction.Kind,
+		"reason_code":    transaction.ReasonCode,
+		"created_at":     transaction.CreatedAt,
 	})
 }
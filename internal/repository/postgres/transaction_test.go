@@ -0,0 +1,93 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+func TestTransactionRepositoryGetByUserIDPages(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	transactions := NewTransactionRepository(repo)
+
+	alice := createTestUser(t, repo, "alice", 1000)
+	bob := createTestUser(t, repo, "bob", 1000)
+	carol := createTestUser(t, repo, "carol", 1000)
+
+	transfers := []struct {
+		from, to *domain.User
+		amount   int64
+	}{
+		{alice, bob, 10}, {bob, alice, 20}, {alice, carol, 30}, {carol, alice, 40},
+		{alice, bob, 50}, {bob, carol, 60}, {alice, bob, 70},
+	}
+	for i, tr := range transfers {
+		description := fmt.Sprintf("transfer %d", i)
+		err := transactions.Create(ctx, &domain.Transaction{
+			FromUserID:  tr.from.ID,
+			ToUserID:    tr.to.ID,
+			Amount:      tr.amount,
+			Description: &description,
+		})
+		if err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	// Одинаковое время у части записей проверяет, что id разрешает равенство ключа
+	if _, err := repo.db.Exec(`UPDATE transactions SET created_at = '2024-01-01T00:00:00Z' WHERE amount IN (20, 30, 40)`); err != nil {
+		t.Fatalf("failed to align created_at: %v", err)
+	}
+
+	minAmount := int64(30)
+
+	tests := []struct {
+		name   string
+		filter domain.TransactionFilter
+		want   []int64 // суммы в порядке выдачи
+	}{
+		{name: "all", want: []int64{70, 50, 10, 40, 30, 20}},
+		{name: "sent", filter: domain.TransactionFilter{Direction: domain.TransactionDirectionSent}, want: []int64{70, 50, 10, 30}},
+		{name: "received", filter: domain.TransactionFilter{Direction: domain.TransactionDirectionReceived}, want: []int64{40, 20}},
+		{name: "counterparty", filter: domain.TransactionFilter{Counterparty: "bob"}, want: []int64{70, 50, 10, 20}},
+		{name: "min amount", filter: domain.TransactionFilter{MinAmount: &minAmount}, want: []int64{70, 50, 40, 30}},
+		{name: "description", filter: domain.TransactionFilter{Description: "transfer 4"}, want: []int64{50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []int64
+			params := pagination.Params{Limit: 2}
+			for pages := 0; ; pages++ {
+				if pages > len(transfers) {
+					t.Fatal("pagination does not terminate")
+				}
+
+				page, err := transactions.GetByUserID(ctx, alice.ID, tt.filter, params)
+				if err != nil {
+					t.Fatalf("GetByUserID page %d: %v", pages, err)
+				}
+				if params.IsFirst() && (page.Total == nil || *page.Total != int64(len(tt.want))) {
+					t.Errorf("total = %v, want %d", page.Total, len(tt.want))
+				}
+				for _, tr := range page.Items {
+					got = append(got, tr.Amount)
+				}
+				if page.NextCursor == "" {
+					break
+				}
+				params.Cursor = page.NextCursor
+			}
+
+			if !equalInt64s(got, tt.want) {
+				t.Errorf("amounts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -18,37 +18,73 @@ func NewUserRepository(repo *Repository) *UserRepository {
 	return &UserRepository{Repository: repo}
 }
 
+// Create создает пользователя с нулевым балансом и переводит ему user.Balance монет
+// из казначейства, чтобы каждая монета в обороте была учтена в истории переводов
 func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
-	query := `
-		INSERT INTO users (username, password_hash, balance)
-		VALUES ($1, $2, $3)
-		RETURNING id, created_at, updated_at`
+	return r.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		query := `
+			INSERT INTO users (username, password_hash, balance)
+			VALUES ($1, $2, 0)
+			RETURNING id, created_at, updated_at`
+
+		err := tx.QueryRowxContext(ctx, query,
+			user.Username,
+			user.Password,
+		).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
 
-	err := r.db.QueryRowxContext(ctx, query,
-		user.Username,
-		user.Password,
-		user.Balance,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+		if user.Balance <= 0 {
+			user.Balance = 0
+			return nil
+		}
 
-	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
-	}
+		var treasuryID int64
+		err = tx.QueryRowContext(ctx, `
+			UPDATE users
+			SET balance = balance - $1, updated_at = CURRENT_TIMESTAMP
+			WHERE username = $2 AND is_system
+			RETURNING id`,
+			user.Balance, domain.TreasuryUsername,
+		).Scan(&treasuryID)
+		if err != nil {
+			return fmt.Errorf("failed to debit treasury: %w", err)
+		}
 
-	return nil
+		_, err = tx.ExecContext(ctx, `UPDATE users SET balance = $1 WHERE id = $2`, user.Balance, user.ID)
+		if err != nil {
+			return fmt.Errorf("failed to credit signup grant: %w", err)
+		}
+
+		description := "Стартовый баланс"
+		grant := &domain.Transaction{
+			FromUserID:  treasuryID,
+			ToUserID:    user.ID,
+			Amount:      user.Balance,
+			Description: &description,
+			Kind:        domain.TransactionKindGrant,
+		}
+		if err := insertTransaction(ctx, tx, grant); err != nil {
+			return err
+		}
+
+		return insertTransferEvent(ctx, tx, grant)
+	})
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
 	user := &domain.User{}
 
 	query := `
-		SELECT id, username, password_hash, balance, created_at, updated_at
+		SELECT id, username, password_hash, balance, is_admin, is_system, created_at, updated_at
 		FROM users
 		WHERE id = $1`
 
-	err := r.db.GetContext(ctx, user, query, id)
+	err := r.queryer(ctx).GetContext(ctx, user, query, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -60,14 +96,14 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*d
 	user := &domain.User{}
 
 	query := `
-		SELECT id, username, password_hash, balance, created_at, updated_at
+		SELECT id, username, password_hash, balance, is_admin, is_system, created_at, updated_at
 		FROM users
 		WHERE username = $1`
 
 	err := r.db.GetContext(ctx, user, query, username)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return nil, domain.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -75,6 +111,26 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*d
 	return user, nil
 }
 
+func (r *UserRepository) SetAdmin(ctx context.Context, username string, isAdmin bool) (*domain.User, error) {
+	user := &domain.User{}
+
+	query := `
+		UPDATE users
+		SET is_admin = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE username = $2
+		RETURNING id, username, password_hash, balance, is_admin, created_at, updated_at`
+
+	err := r.db.GetContext(ctx, user, query, isAdmin, username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to update admin flag: %w", err)
+	}
+
+	return user, nil
+}
+
 func (r *UserRepository) UpdateBalance(ctx context.Context, userID int64, amount int64) error {
 	return r.Transaction(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
 		var newBalance int64
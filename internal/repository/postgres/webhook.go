@@ -0,0 +1,292 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+	"github.com/lib/pq"
+)
+
+const webhookDeliveryColumns = `d.id, d.subscription_id, d.event_id, o.event_type, d.status, d.attempts,
+	d.next_attempt_at, d.last_status_code, d.last_error, d.delivered_at, d.created_at, d.updated_at`
+
+type webhookSubscriptionRow struct {
+	domain.WebhookSubscription
+	EventTypes pq.StringArray `db:"event_types"`
+}
+
+func (r *webhookSubscriptionRow) toDomain() *domain.WebhookSubscription {
+	subscription := r.WebhookSubscription
+	subscription.EventTypes = []string(r.EventTypes)
+	return &subscription
+}
+
+// webhookDispatchRow - доставка вместе с подпиской и событием outbox
+type webhookDispatchRow struct {
+	domain.WebhookDelivery
+	URL            string          `db:"url"`
+	Secret         string          `db:"secret"`
+	AggregateType  string          `db:"aggregate_type"`
+	AggregateID    int64           `db:"aggregate_id"`
+	Payload        json.RawMessage `db:"payload"`
+	EventCreatedAt time.Time       `db:"event_created_at"`
+}
+
+type WebhookRepository struct {
+	*Repository
+}
+
+func NewWebhookRepository(repo *Repository) *WebhookRepository {
+	return &WebhookRepository{Repository: repo}
+}
+
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription *domain.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, event_types, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		subscription.URL,
+		subscription.Secret,
+		pq.StringArray(subscription.EventTypes),
+		subscription.Active,
+	).Scan(&subscription.ID, &subscription.CreatedAt, &subscription.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) GetSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	var rows []*webhookSubscriptionRow
+
+	query := `
+		SELECT id, url, secret, event_types, active, created_at, updated_at
+		FROM webhook_subscriptions
+		ORDER BY id`
+
+	err := r.db.SelectContext(ctx, &rows, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+
+	subscriptions := make([]*domain.WebhookSubscription, 0, len(rows))
+	for _, row := range rows {
+		subscriptions = append(subscriptions, row.toDomain())
+	}
+
+	return subscriptions, nil
+}
+
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id int64) (*domain.WebhookSubscription, error) {
+	row := &webhookSubscriptionRow{}
+
+	query := `
+		SELECT id, url, secret, event_types, active, created_at, updated_at
+		FROM webhook_subscriptions
+		WHERE id = $1`
+
+	err := r.db.GetContext(ctx, row, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	return row.toDomain(), nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, event *domain.OutboxEvent) error {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id)
+		SELECT id, $1
+		FROM webhook_subscriptions
+		WHERE active AND ($2 = ANY(event_types) OR $3 = ANY(event_types))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query, event.ID, event.EventType, domain.WebhookAllEvents)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, subscriptionID int64, status domain.WebhookDeliveryStatus, params pagination.Params) (*pagination.Page[*domain.WebhookDelivery], error) {
+	cursor, err := decodeTimeCursor(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var total *int64
+	if params.IsFirst() {
+		total = new(int64)
+		err := r.db.GetContext(ctx, total, `
+			SELECT COUNT(*) FROM webhook_deliveries
+			WHERE subscription_id = $1 AND ($2 = '' OR status = $2)`,
+			subscriptionID, status,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count webhook deliveries: %w", err)
+		}
+	}
+
+	var deliveries []*domain.WebhookDelivery
+
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries d
+		JOIN outbox o ON d.event_id = o.id
+		WHERE d.subscription_id = $1
+		  AND ($2 = '' OR d.status = $2)
+		  AND ($3::timestamptz IS NULL OR (d.created_at, d.id) < ($3, $4))
+		ORDER BY d.created_at DESC, d.id DESC
+		LIMIT $5`
+
+	createdAt, id := cursor.after()
+	err = r.db.SelectContext(ctx, &deliveries, query, subscriptionID, status, createdAt, id, params.Limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+
+	page, err := pagination.NewPage(deliveries, params.Limit, func(last *domain.WebhookDelivery) any {
+		return timeCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	})
+	if err != nil {
+		return nil, err
+	}
+	page.Total = total
+
+	return page, nil
+}
+
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDispatch, error) {
+	var rows []*webhookDispatchRow
+
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = CURRENT_TIMESTAMP + $2 * INTERVAL '1 millisecond'
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+				ORDER BY next_attempt_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT ` + webhookDeliveryColumns + `,
+			   s.url, s.secret, o.aggregate_type, o.aggregate_id, o.payload, o.created_at as event_created_at
+		FROM claimed d
+		JOIN webhook_subscriptions s ON d.subscription_id = s.id
+		JOIN outbox o ON d.event_id = o.id`
+
+	err := r.db.SelectContext(ctx, &rows, query, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	dispatches := make([]*domain.WebhookDispatch, 0, len(rows))
+	for _, row := range rows {
+		delivery := row.WebhookDelivery
+		dispatches = append(dispatches, &domain.WebhookDispatch{
+			Delivery: &delivery,
+			URL:      row.URL,
+			Secret:   row.Secret,
+			Event: &domain.OutboxEvent{
+				ID:            delivery.EventID,
+				EventType:     delivery.EventType,
+				AggregateType: row.AggregateType,
+				AggregateID:   row.AggregateID,
+				Payload:       row.Payload,
+				CreatedAt:     row.EventCreatedAt,
+			},
+		})
+	}
+
+	return dispatches, nil
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, id int64, statusCode int) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = attempts + 1, last_status_code = $2, last_error = NULL,
+			delivered_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`,
+		id, statusCode,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivered: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) MarkFailed(ctx context.Context, id int64, statusCode *int, cause string, retryAt *time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = CASE WHEN $4::timestamptz IS NULL THEN 'dead' ELSE 'pending' END,
+			attempts = attempts + 1, last_status_code = $2, last_error = $3,
+			next_attempt_at = COALESCE($4, next_attempt_at), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`,
+		id, statusCode, cause, retryAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook failed: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) Redeliver(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	delivery := &domain.WebhookDelivery{}
+
+	query := `
+		WITH updated AS (
+			UPDATE webhook_deliveries
+			SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+			RETURNING *
+		)
+		SELECT ` + webhookDeliveryColumns + `
+		FROM updated d
+		JOIN outbox o ON d.event_id = o.id`
+
+	err := r.db.GetContext(ctx, delivery, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to redeliver webhook: %w", err)
+	}
+
+	return delivery, nil
+}
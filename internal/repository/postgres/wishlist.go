@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/avito/internal/domain"
+	"github.com/lib/pq"
+)
+
+// wishlistSelect выбирает записи списка желаний вместе с актуальной ценой,
+// наличием и тем, хватает ли владельцу баланса на покупку
+const wishlistSelect = `
+	SELECT w.id, w.user_id, w.merch_id, m.name as merch_name, w.variant_id, v.sku as variant_sku,
+		   COALESCE(v.price, m.price) as price,
+		   CASE
+			   WHEN w.variant_id IS NOT NULL THEN v.stock > 0
+			   ELSE NOT EXISTS (SELECT 1 FROM merch_variants mv WHERE mv.merch_id = w.merch_id)
+				   OR EXISTS (SELECT 1 FROM merch_variants mv WHERE mv.merch_id = w.merch_id AND mv.stock > 0)
+		   END as in_stock,
+		   u.balance >= COALESCE(v.price, m.price) as affordable,
+		   w.created_at
+	FROM wishlist_items w
+	JOIN merch m ON w.merch_id = m.id
+	JOIN users u ON w.user_id = u.id
+	LEFT JOIN merch_variants v ON w.variant_id = v.id`
+
+type WishlistRepository struct {
+	*Repository
+}
+
+func NewWishlistRepository(repo *Repository) *WishlistRepository {
+	return &WishlistRepository{Repository: repo}
+}
+
+func (r *WishlistRepository) Add(ctx context.Context, userID, merchID int64, variantID *int64) error {
+	query := `
+		INSERT INTO wishlist_items (user_id, merch_id, variant_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, merch_id, COALESCE(variant_id, 0)) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query, userID, merchID, variantID)
+	if err != nil {
+		return fmt.Errorf("failed to add wishlist item: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WishlistRepository) Remove(ctx context.Context, userID, merchID int64, variantID *int64) error {
+	query := `
+		DELETE FROM wishlist_items
+		WHERE user_id = $1 AND merch_id = $2 AND variant_id IS NOT DISTINCT FROM $3`
+
+	res, err := r.db.ExecContext(ctx, query, userID, merchID, variantID)
+	if err != nil {
+		return fmt.Errorf("failed to remove wishlist item: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if affected == 0 {
+		return domain.ErrWishlistItemNotFound
+	}
+
+	return nil
+}
+
+func (r *WishlistRepository) GetByUserID(ctx context.Context, userID int64) ([]*domain.WishlistItem, error) {
+	var items []*domain.WishlistItem
+
+	query := wishlistSelect + `
+		WHERE w.user_id = $1
+		ORDER BY w.created_at DESC, w.id DESC`
+
+	err := r.db.SelectContext(ctx, &items, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *WishlistRepository) GetPricedBetween(ctx context.Context, userID, minExclusive, maxInclusive int64) ([]*domain.WishlistItem, error) {
+	var items []*domain.WishlistItem
+
+	query := wishlistSelect + `
+		WHERE w.user_id = $1
+		  AND COALESCE(v.price, m.price) > $2
+		  AND COALESCE(v.price, m.price) <= $3
+		ORDER BY w.id`
+
+	err := r.db.SelectContext(ctx, &items, query, userID, minExclusive, maxInclusive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wishlist items by price: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *WishlistRepository) GetRestocked(ctx context.Context, variantIDs []int64) ([]*domain.WishlistItem, error) {
+	var items []*domain.WishlistItem
+
+	// Запись без варианта ждет товар целиком: он появился, только если до поступления
+	// в наличии не было ни одного другого варианта
+	query := wishlistSelect + `
+		WHERE w.variant_id = ANY($1)
+		   OR (w.variant_id IS NULL
+			   AND w.merch_id IN (SELECT merch_id FROM merch_variants WHERE id = ANY($1))
+			   AND NOT EXISTS (
+				   SELECT 1
+				   FROM merch_variants mv
+				   WHERE mv.merch_id = w.merch_id AND mv.stock > 0 AND mv.id <> ALL($1)
+			   ))
+		ORDER BY w.id`
+
+	err := r.db.SelectContext(ctx, &items, query, pq.Array(variantIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restocked wishlist items: %w", err)
+	}
+
+	return items, nil
+}
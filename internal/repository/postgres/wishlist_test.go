@@ -0,0 +1,86 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"sort"
+	"testing"
+)
+
+func TestWishlistRepositoryGetRestocked(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	wishlist := NewWishlistRepository(repo)
+
+	shirt := createTestMerch(t, repo, "test-shirt", 80)
+	small := createTestVariant(t, repo, shirt.ID, "test-shirt-s", 0)
+	medium := createTestVariant(t, repo, shirt.ID, "test-shirt-m", 0)
+	large := createTestVariant(t, repo, shirt.ID, "test-shirt-l", 0)
+	cup := createTestMerch(t, repo, "test-cup", 20)
+
+	waitsSmall := createTestUser(t, repo, "waits-small", 0)
+	waitsMedium := createTestUser(t, repo, "waits-medium", 0)
+	waitsAny := createTestUser(t, repo, "waits-any", 0)
+	waitsCup := createTestUser(t, repo, "waits-cup", 0)
+
+	for _, item := range []struct {
+		userID, merchID int64
+		variantID       *int64
+	}{
+		{waitsSmall.ID, shirt.ID, &small.ID},
+		{waitsMedium.ID, shirt.ID, &medium.ID},
+		{waitsAny.ID, shirt.ID, nil},
+		{waitsCup.ID, cup.ID, nil},
+	} {
+		if err := wishlist.Add(ctx, item.userID, item.merchID, item.variantID); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	setStock := func(variantID int64, stock int) {
+		t.Helper()
+		if _, err := repo.db.Exec(`UPDATE merch_variants SET stock = $1 WHERE id = $2`, stock, variantID); err != nil {
+			t.Fatalf("failed to set stock: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		inStock   []int64 // варианты с остатком после поступления
+		restocked []int64
+		want      []int64
+	}{
+		// Товар без варианта появился вместе с первым поступившим вариантом
+		{name: "first variant", inStock: []int64{small.ID}, restocked: []int64{small.ID}, want: []int64{waitsSmall.ID, waitsAny.ID}},
+		{name: "several variants at once", inStock: []int64{small.ID, medium.ID}, restocked: []int64{small.ID, medium.ID}, want: []int64{waitsSmall.ID, waitsMedium.ID, waitsAny.ID}},
+		// Товар уже был в наличии в другом размере, ждавшие любой размер не уведомляются повторно
+		{name: "merch already in stock", inStock: []int64{large.ID, medium.ID}, restocked: []int64{medium.ID}, want: []int64{waitsMedium.ID}},
+		{name: "variant nobody waits for", inStock: []int64{large.ID}, restocked: []int64{large.ID}, want: []int64{waitsAny.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, variantID := range []int64{small.ID, medium.ID, large.ID} {
+				setStock(variantID, 0)
+			}
+			for _, variantID := range tt.inStock {
+				setStock(variantID, 3)
+			}
+
+			items, err := wishlist.GetRestocked(ctx, tt.restocked)
+			if err != nil {
+				t.Fatalf("GetRestocked: %v", err)
+			}
+
+			got := make([]int64, 0, len(items))
+			for _, item := range items {
+				got = append(got, item.UserID)
+			}
+			sort.Slice(got, func(i, j int) bool { return got[i] < got[j] })
+			if !equalInt64s(got, tt.want) {
+				t.Errorf("notified users = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
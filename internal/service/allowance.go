@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+type AllowanceService struct {
+	repo     domain.AllowanceRepository
+	wishlist domain.WishlistService
+	expiry   domain.ExpiryRule
+	logger   *slog.Logger
+}
+
+func NewAllowanceService(repo domain.AllowanceRepository, wishlist domain.WishlistService, expiry domain.ExpiryRule, logger *slog.Logger) *AllowanceService {
+	return &AllowanceService{
+		repo:     repo,
+		wishlist: wishlist,
+		expiry:   expiry,
+		logger:   logger,
+	}
+}
+
+func (s *AllowanceService) CreateSchedule(ctx context.Context, name string, amount int64, dayOfMonth int) (*domain.GrantSchedule, error) {
+	ctx, span := startSpan(ctx, "AllowanceService.CreateSchedule")
+	defer span.End()
+
+	name = strings.TrimSpace(name)
+	if name == "" || amount <= 0 || dayOfMonth < 1 || dayOfMonth > 28 {
+		return nil, domain.ErrInvalidGrantSchedule
+	}
+
+	schedule := &domain.GrantSchedule{
+		Name:       name,
+		Amount:     amount,
+		DayOfMonth: dayOfMonth,
+		Active:     true,
+	}
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (s *AllowanceService) ListSchedules(ctx context.Context) ([]*domain.GrantSchedule, error) {
+	ctx, span := startSpan(ctx, "AllowanceService.ListSchedules")
+	defer span.End()
+
+	return s.repo.GetSchedules(ctx, false)
+}
+
+func (s *AllowanceService) DeactivateSchedule(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "AllowanceService.DeactivateSchedule")
+	defer span.End()
+
+	return s.repo.DeactivateSchedule(ctx, id)
+}
+
+func (s *AllowanceService) Runs(ctx context.Context, params pagination.Params) (*pagination.Page[*domain.AllowanceRun], error) {
+	ctx, span := startSpan(ctx, "AllowanceService.Runs")
+	defer span.End()
+
+	return s.repo.GetRuns(ctx, params)
+}
+
+// RunDue проводит только последний наступивший период каждого расписания:
+// пропущенные за время простоя месяцы не догоняются, а периоды до создания расписания не начисляются.
+// Ошибка одного расписания не мешает остальным.
+func (s *AllowanceService) RunDue(ctx context.Context, now time.Time) error {
+	ctx, span := startSpan(ctx, "AllowanceService.RunDue")
+	defer span.End()
+
+	schedules, err := s.repo.GetSchedules(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, schedule := range schedules {
+		period := schedule.DueDate(now)
+		created := schedule.CreatedAt.UTC().Truncate(24 * time.Hour)
+		if period.Before(created) {
+			continue
+		}
+
+		run, balances, err := s.repo.RunGrant(ctx, schedule, period)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("grant schedule %d: %w", schedule.ID, err))
+			continue
+		}
+		if run != nil {
+			s.logger.InfoContext(ctx, "allowance granted",
+				"schedule_id", schedule.ID, "period", period.Format(time.DateOnly),
+				"users", run.UsersCount, "amount", run.Amount)
+		}
+
+		// Начисление уже проведено, поэтому ошибки уведомлений только логируются
+		for _, balance := range balances {
+			err := s.wishlist.NotifyAffordable(ctx, balance.UserID, balance.Balance-schedule.Amount, balance.Balance)
+			if err != nil {
+				s.logger.WarnContext(ctx, "failed to send wishlist notifications", "user_id", balance.UserID, "error", err)
+			}
+		}
+	}
+
+	if s.expiry.Enabled() {
+		period := s.expiry.DueDate(now)
+		run, err := s.repo.RunExpiry(ctx, period, s.expiry.Cutoff(period))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("coin expiry: %w", err))
+		} else if run != nil {
+			s.logger.InfoContext(ctx, "coins expired",
+				"period", period.Format(time.DateOnly),
+				"users", run.UsersCount, "amount", run.Amount)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *AllowanceService) Outlook(ctx context.Context, userID int64, now time.Time) (*domain.AllowanceOutlook, error) {
+	ctx, span := startSpan(ctx, "AllowanceService.Outlook")
+	defer span.End()
+
+	schedules, err := s.repo.GetSchedules(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	outlook := &domain.AllowanceOutlook{UpcomingGrants: make([]*domain.UpcomingGrant, 0, len(schedules))}
+	for _, schedule := range schedules {
+		outlook.UpcomingGrants = append(outlook.UpcomingGrants, &domain.UpcomingGrant{
+			Name:   schedule.Name,
+			Amount: schedule.Amount,
+			Date:   schedule.NextDate(now),
+		})
+	}
+
+	if s.expiry.Enabled() {
+		next := s.expiry.NextDate(now)
+		outlook.ExpiringAmount, err = s.repo.ExpiringAmount(ctx, userID, s.expiry.Cutoff(next))
+		if err != nil {
+			return nil, err
+		}
+		outlook.ExpiresAt = &next
+	}
+
+	return outlook, nil
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+
+	"github.com/avito/internal/domain"
+)
+
+// analyticsMaxLimit ограничивает размер топов
+const analyticsMaxLimit = 100
+
+type AnalyticsService struct {
+	repo domain.AnalyticsRepository
+}
+
+func NewAnalyticsService(repo domain.AnalyticsRepository) *AnalyticsService {
+	return &AnalyticsService{repo: repo}
+}
+
+func (s *AnalyticsService) TopSpenders(ctx context.Context, r domain.AnalyticsRange) ([]*domain.UserTotal, error) {
+	ctx, span := startSpan(ctx, "AnalyticsService.TopSpenders")
+	defer span.End()
+
+	if err := validateAnalyticsRange(r, true); err != nil {
+		return nil, err
+	}
+	return s.repo.TopSpenders(ctx, r)
+}
+
+func (s *AnalyticsService) TopReceivers(ctx context.Context, r domain.AnalyticsRange) ([]*domain.UserTotal, error) {
+	ctx, span := startSpan(ctx, "AnalyticsService.TopReceivers")
+	defer span.End()
+
+	if err := validateAnalyticsRange(r, true); err != nil {
+		return nil, err
+	}
+	return s.repo.TopReceivers(ctx, r)
+}
+
+func (s *AnalyticsService) TopMerch(ctx context.Context, r domain.AnalyticsRange) ([]*domain.MerchTotal, error) {
+	ctx, span := startSpan(ctx, "AnalyticsService.TopMerch")
+	defer span.End()
+
+	if err := validateAnalyticsRange(r, true); err != nil {
+		return nil, err
+	}
+	return s.repo.TopMerch(ctx, r)
+}
+
+func (s *AnalyticsService) Economy(ctx context.Context, r domain.AnalyticsRange) (*domain.EconomySummary, error) {
+	ctx, span := startSpan(ctx, "AnalyticsService.Economy")
+	defer span.End()
+
+	if err := validateAnalyticsRange(r, false); err != nil {
+		return nil, err
+	}
+
+	summary, err := s.repo.Economy(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Скорость обращения считаем относительно текущей денежной массы
+	if summary.Circulation > 0 {
+		summary.Velocity = float64(summary.TransferVolume+summary.PurchaseVolume) / float64(summary.Circulation)
+	}
+
+	return summary, nil
+}
+
+func (s *AnalyticsService) DailyVolumes(ctx context.Context, r domain.AnalyticsRange) ([]*domain.DailyVolume, error) {
+	ctx, span := startSpan(ctx, "AnalyticsService.DailyVolumes")
+	defer span.End()
+
+	if err := validateAnalyticsRange(r, false); err != nil {
+		return nil, err
+	}
+	return s.repo.DailyVolumes(ctx, r)
+}
+
+func (s *AnalyticsService) Refresh(ctx context.Context) error {
+	ctx, span := startSpan(ctx, "AnalyticsService.Refresh")
+	defer span.End()
+
+	return s.repo.RefreshDailyVolumes(ctx)
+}
+
+func validateAnalyticsRange(r domain.AnalyticsRange, withLimit bool) error {
+	if r.From != nil && r.To != nil && !r.From.Before(*r.To) {
+		return domain.ErrInvalidFilter
+	}
+	if withLimit && (r.Limit <= 0 || r.Limit > analyticsMaxLimit) {
+		return domain.ErrInvalidFilter
+	}
+	return nil
+}
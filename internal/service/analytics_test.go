@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+type fakeAnalyticsRepo struct {
+	domain.AnalyticsRepository
+	summary    domain.EconomySummary
+	calls      int
+	refreshErr error
+}
+
+func (r *fakeAnalyticsRepo) TopSpenders(context.Context, domain.AnalyticsRange) ([]*domain.UserTotal, error) {
+	r.calls++
+	return []*domain.UserTotal{}, nil
+}
+
+func (r *fakeAnalyticsRepo) Economy(context.Context, domain.AnalyticsRange) (*domain.EconomySummary, error) {
+	r.calls++
+	summary := r.summary
+	return &summary, nil
+}
+
+func (r *fakeAnalyticsRepo) RefreshDailyVolumes(context.Context) error {
+	r.calls++
+	return r.refreshErr
+}
+
+func TestAnalyticsServiceTopSpenders(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	tests := []struct {
+		name    string
+		rng     domain.AnalyticsRange
+		wantErr error
+	}{
+		{name: "open range", rng: domain.AnalyticsRange{Limit: 10}},
+		{name: "closed range", rng: domain.AnalyticsRange{From: &from, To: &to, Limit: analyticsMaxLimit}},
+		{name: "empty range", rng: domain.AnalyticsRange{From: &from, To: &from, Limit: 10}, wantErr: domain.ErrInvalidFilter},
+		{name: "reversed range", rng: domain.AnalyticsRange{From: &to, To: &from, Limit: 10}, wantErr: domain.ErrInvalidFilter},
+		{name: "zero limit", rng: domain.AnalyticsRange{}, wantErr: domain.ErrInvalidFilter},
+		{name: "limit too large", rng: domain.AnalyticsRange{Limit: analyticsMaxLimit + 1}, wantErr: domain.ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeAnalyticsRepo{}
+			s := NewAnalyticsService(repo)
+
+			_, err := s.TopSpenders(context.Background(), tt.rng)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("TopSpenders() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && repo.calls != 0 {
+				t.Errorf("repository calls = %d, want 0", repo.calls)
+			}
+		})
+	}
+}
+
+func TestAnalyticsServiceEconomyVelocity(t *testing.T) {
+	tests := []struct {
+		name    string
+		summary domain.EconomySummary
+		want    float64
+	}{
+		{
+			name:    "turnover",
+			summary: domain.EconomySummary{Circulation: 1000, TransferVolume: 1500, PurchaseVolume: 500},
+			want:    2,
+		},
+		// Без монет в обороте скорость не определена и остается нулевой
+		{
+			name:    "no circulation",
+			summary: domain.EconomySummary{TransferVolume: 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewAnalyticsService(&fakeAnalyticsRepo{summary: tt.summary})
+
+			// Лимит для сводки не нужен
+			summary, err := s.Economy(context.Background(), domain.AnalyticsRange{})
+			if err != nil {
+				t.Fatalf("Economy() error = %v", err)
+			}
+			if summary.Velocity != tt.want {
+				t.Errorf("velocity = %v, want %v", summary.Velocity, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyticsServiceRefresh(t *testing.T) {
+	refreshErr := errors.New("could not obtain lock")
+	repo := &fakeAnalyticsRepo{refreshErr: refreshErr}
+	s := NewAnalyticsService(repo)
+
+	if err := s.Refresh(context.Background()); !errors.Is(err, refreshErr) {
+		t.Errorf("Refresh() error = %v, want %v", err, refreshErr)
+	}
+	if repo.calls != 1 {
+		t.Errorf("refreshes = %d, want 1", repo.calls)
+	}
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+// errAuditChainBroken останавливает обход журнала на первой несходящейся записи
+var errAuditChainBroken = errors.New("audit chain broken")
+
+type AuditService struct {
+	auditRepo domain.AuditRepository
+}
+
+func NewAuditService(auditRepo domain.AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+func (s *AuditService) Record(ctx context.Context, action domain.AuditAction, targetType, targetID string, before, after any) error {
+	ctx, span := startSpan(ctx, "AuditService.Record")
+	defer span.End()
+
+	meta := domain.RequestMetaFrom(ctx)
+
+	entry := &domain.AuditEntry{
+		ActorID:    meta.ActorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		RequestID:  meta.RequestID,
+		IP:         meta.IP,
+		UserAgent:  meta.UserAgent,
+		// Postgres хранит микросекунды: округляем заранее, чтобы хеш сходился после чтения
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+
+	var err error
+	if entry.Before, err = marshalAuditState(before); err != nil {
+		return err
+	}
+	if entry.After, err = marshalAuditState(after); err != nil {
+		return err
+	}
+
+	return s.auditRepo.Append(ctx, entry)
+}
+
+func (s *AuditService) List(ctx context.Context, filter domain.AuditFilter, params pagination.Params) (*pagination.Page[*domain.AuditEntry], error) {
+	ctx, span := startSpan(ctx, "AuditService.List")
+	defer span.End()
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, domain.ErrInvalidFilter
+	}
+
+	return s.auditRepo.List(ctx, filter, params)
+}
+
+func (s *AuditService) Verify(ctx context.Context) (*domain.AuditVerification, error) {
+	ctx, span := startSpan(ctx, "AuditService.Verify")
+	defer span.End()
+
+	result := &domain.AuditVerification{Valid: true}
+	prevHash := domain.AuditGenesisHash
+
+	err := s.auditRepo.Walk(ctx, func(entry *domain.AuditEntry) error {
+		result.Checked++
+
+		hash, err := entry.ComputeHash()
+		if err != nil {
+			return fmt.Errorf("failed to hash audit entry %d: %w", entry.ID, err)
+		}
+
+		// Запись должна ссылаться на предыдущую и совпадать со своим хешем
+		if entry.PrevHash != prevHash || entry.Hash != hash {
+			result.Valid = false
+			result.BrokenAt = &entry.ID
+			return errAuditChainBroken
+		}
+
+		prevHash = entry.Hash
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAuditChainBroken) {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func marshalAuditState(state any) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit state: %w", err)
+	}
+
+	return data, nil
+}
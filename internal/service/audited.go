@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+
+	"github.com/avito/internal/domain"
+)
+
+// auditor записывает действия в журнал аудита. Действие к моменту записи уже выполнено,
+// поэтому ошибки журнала логируются и не возвращаются клиенту.
+type auditor struct {
+	audit    domain.AuditService
+	userRepo domain.UserRepository
+	logger   *slog.Logger
+}
+
+func (a *auditor) record(ctx context.Context, action domain.AuditAction, targetType, targetID string, before, after any) {
+	if err := a.audit.Record(ctx, action, targetType, targetID, before, after); err != nil {
+		a.logger.ErrorContext(ctx, "failed to record audit",
+			"action", action, "target_type", targetType, "target_id", targetID, "error", err)
+	}
+}
+
+// balanceChange возвращает снимки баланса до и после операции, изменившей его на delta.
+// Баланс после берется из результата операции: перечитанный баланс мог бы уже включать одновременные изменения.
+func balanceChange(after, delta int64) (map[string]any, map[string]any) {
+	return map[string]any{"balance": after - delta}, map[string]any{"balance": after}
+}
+
+func formatID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// auditedUserService фиксирует успешные и неудачные входы
+type auditedUserService struct {
+	domain.UserService
+	*auditor
+}
+
+func (s *auditedUserService) Auth(ctx context.Context, username, password string) (string, error) {
+	token, err := s.UserService.Auth(ctx, username, password)
+	s.recordLogin(ctx, username, err)
+	return token, err
+}
+
+func (s *auditedUserService) Login(ctx context.Context, username, password string) (string, error) {
+	token, err := s.UserService.Login(ctx, username, password)
+	s.recordLogin(ctx, username, err)
+	return token, err
+}
+
+func (s *auditedUserService) recordLogin(ctx context.Context, username string, err error) {
+	if err != nil {
+		// В журнал попадает только код причины: текст внутренних ошибок может содержать детали запросов к БД
+		reason := "invalid_credentials"
+		if !errors.Is(err, domain.ErrInvalidCredentials) {
+			s.logger.ErrorContext(ctx, "login failed", "username", username, "error", err)
+			reason = "internal_error"
+		}
+		s.record(ctx, domain.AuditUserLoginFailed, "user", username, nil, map[string]any{"reason": reason})
+		return
+	}
+
+	// До входа исполнитель неизвестен, поэтому берем его по имени
+	if user, err := s.userRepo.GetByUsername(ctx, username); err == nil {
+		ctx = domain.WithActor(ctx, user.ID)
+	}
+	s.record(ctx, domain.AuditUserLogin, "user", username, nil, nil)
+}
+
+// auditedTransactionService фиксирует переводы с балансом отправителя до и после
+type auditedTransactionService struct {
+	domain.TransactionService
+	*auditor
+}
+
+func (s *auditedTransactionService) Transfer(ctx context.Context, fromUserID, toUserID int64, amount int64, description string, kudos domain.KudosOptions) (*domain.Transaction, error) {
+	transaction, err := s.TransactionService.Transfer(ctx, fromUserID, toUserID, amount, description, kudos)
+	if err != nil {
+		return nil, err
+	}
+
+	before, after := balanceChange(transaction.SenderBalance, -amount)
+	after["to_user_id"] = toUserID
+	after["amount"] = amount
+	after["description"] = description
+	after["public"] = kudos.Public
+	after["category"] = kudos.Category
+	s.record(ctx, domain.AuditTransfer, "user", formatID(toUserID), before, after)
+	return transaction, nil
+}
+
+func (s *auditedTransactionService) Adjust(ctx context.Context, adminID int64, adjustment domain.BalanceAdjustment) (*domain.Transaction, error) {
+	transaction, err := s.TransactionService.Adjust(ctx, adminID, adjustment)
+	if err != nil {
+		return nil, err
+	}
+
+	before, after := balanceChange(transaction.RecipientBalance, adjustment.Amount)
+	if adjustment.Direction == domain.AdjustmentDebit {
+		before, after = balanceChange(transaction.SenderBalance, -adjustment.Amount)
+	}
+	after["transaction_id"] = transaction.ID
+	after["direction"] = adjustment.Direction
+	after["amount"] = adjustment.Amount
+	after["reason_code"] = adjustment.ReasonCode
+	after["comment"] = transaction.Description
+	s.record(ctx, domain.AuditBalanceAdjust, "user", formatID(adjustment.UserID), before, after)
+	return transaction, nil
+}
+
+// auditedMerchService фиксирует покупки и изменения каталога администраторами
+type auditedMerchService struct {
+	domain.MerchService
+	*auditor
+	variantRepo domain.MerchVariantRepository
+}
+
+func (s *auditedMerchService) Buy(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) (*domain.Purchase, error) {
+	purchase, err := s.MerchService.Buy(ctx, userID, merchID, variantID, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	before, after := balanceChange(purchase.BuyerBalance, -purchase.TotalPrice)
+	after["variant_id"] = variantID
+	after["quantity"] = quantity
+	s.record(ctx, domain.AuditPurchase, "merch", formatID(merchID), before, after)
+	return purchase, nil
+}
+
+func (s *auditedMerchService) UploadImage(ctx context.Context, merchID int64, r io.Reader) (*domain.Merch, error) {
+	before, _ := s.MerchService.GetByID(ctx, merchID)
+
+	merch, err := s.MerchService.UploadImage(ctx, merchID, r)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, domain.AuditMerchImageUpload, "merch", formatID(merchID), imageState(before), imageState(merch))
+	return merch, nil
+}
+
+func (s *auditedMerchService) DeleteImage(ctx context.Context, merchID int64) error {
+	before, _ := s.MerchService.GetByID(ctx, merchID)
+
+	if err := s.MerchService.DeleteImage(ctx, merchID); err != nil {
+		return err
+	}
+
+	s.record(ctx, domain.AuditMerchImageDelete, "merch", formatID(merchID), imageState(before), nil)
+	return nil
+}
+
+func (s *auditedMerchService) SetVariantStock(ctx context.Context, variantID int64, stock int) (*domain.MerchVariant, error) {
+	var before any
+	if variant, err := s.variantRepo.GetByID(ctx, variantID); err == nil {
+		before = map[string]any{"stock": variant.Stock}
+	}
+
+	variant, err := s.MerchService.SetVariantStock(ctx, variantID, stock)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, domain.AuditVariantStockUpdate, "merch_variant", formatID(variantID), before, map[string]any{"stock": variant.Stock})
+	return variant, nil
+}
+
+func imageState(merch *domain.Merch) any {
+	if merch == nil {
+		return nil
+	}
+	return map[string]any{"image_key": merch.ImageKey, "thumbnail_key": merch.ThumbnailKey}
+}
+
+// auditedCartService фиксирует оформление корзины
+type auditedCartService struct {
+	domain.CartService
+	*auditor
+}
+
+func (s *auditedCartService) Checkout(ctx context.Context, userID int64, delivery domain.DeliveryDetails) (*domain.Order, error) {
+	order, err := s.CartService.Checkout(ctx, userID, delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	before, after := balanceChange(order.BuyerBalance, -order.TotalPrice)
+	after["total_price"] = order.TotalPrice
+	s.record(ctx, domain.AuditCheckout, "order", formatID(order.ID), before, after)
+	return order, nil
+}
+
+// auditedOrderService фиксирует смену статуса заказа администратором
+type auditedOrderService struct {
+	domain.OrderService
+	*auditor
+	orderRepo domain.OrderRepository
+}
+
+func (s *auditedOrderService) UpdateStatus(ctx context.Context, orderID int64, status domain.OrderStatus) (*domain.Order, error) {
+	var before any
+	if order, err := s.orderRepo.GetByID(ctx, orderID); err == nil {
+		before = map[string]any{"status": order.Status}
+	}
+
+	order, err := s.OrderService.UpdateStatus(ctx, orderID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, domain.AuditOrderStatusUpdate, "order", formatID(orderID), before, map[string]any{"status": order.Status})
+
+	// Отмена возвращает монеты покупателю - фиксируем возврат отдельной записью
+	if order.Status == domain.OrderStatusCancelled {
+		balanceBefore, balanceAfter := balanceChange(order.BuyerBalance, order.TotalPrice)
+		balanceAfter["order_id"] = order.ID
+		balanceAfter["refunded_amount"] = order.TotalPrice
+		s.record(ctx, domain.AuditOrderCancel, "user", formatID(order.UserID), balanceBefore, balanceAfter)
+	}
+	return order, nil
+}
+
+// auditedWebhookService фиксирует создание и удаление подписок на вебхуки
+type auditedWebhookService struct {
+	domain.WebhookService
+	*auditor
+}
+
+func (s *auditedWebhookService) Create(ctx context.Context, url string, eventTypes []string, secret string) (*domain.WebhookSubscription, error) {
+	subscription, err := s.WebhookService.Create(ctx, url, eventTypes, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// Секрет в журнал не попадает
+	after := map[string]any{"url": subscription.URL, "event_types": subscription.EventTypes}
+	s.record(ctx, domain.AuditWebhookCreate, "webhook", formatID(subscription.ID), nil, after)
+	return subscription, nil
+}
+
+func (s *auditedWebhookService) Delete(ctx context.Context, id int64) error {
+	if err := s.WebhookService.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.record(ctx, domain.AuditWebhookDelete, "webhook", formatID(id), nil, nil)
+	return nil
+}
+
+// auditedAllowanceService фиксирует изменения расписаний начислений
+type auditedAllowanceService struct {
+	domain.AllowanceService
+	*auditor
+}
+
+func (s *auditedAllowanceService) CreateSchedule(ctx context.Context, name string, amount int64, dayOfMonth int) (*domain.GrantSchedule, error) {
+	schedule, err := s.AllowanceService.CreateSchedule(ctx, name, amount, dayOfMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	s.record(ctx, domain.AuditGrantScheduleCreate, "grant_schedule", formatID(schedule.ID), nil, schedule)
+	return schedule, nil
+}
+
+func (s *auditedAllowanceService) DeactivateSchedule(ctx context.Context, id int64) error {
+	if err := s.AllowanceService.DeactivateSchedule(ctx, id); err != nil {
+		return err
+	}
+
+	s.record(ctx, domain.AuditGrantScheduleDisable, "grant_schedule", formatID(id), nil, nil)
+	return nil
+}
+
+// withAudit оборачивает сервисы декораторами, пишущими журнал аудита
+func withAudit(services *domain.Services, audit domain.AuditService, repos *domain.Repositories, logger *slog.Logger) *domain.Services {
+	a := &auditor{audit: audit, userRepo: repos.User, logger: logger}
+
+	audited := *services
+	audited.User = &auditedUserService{UserService: services.User, auditor: a}
+	audited.Transaction = &auditedTransactionService{TransactionService: services.Transaction, auditor: a}
+	audited.Merch = &auditedMerchService{MerchService: services.Merch, auditor: a, variantRepo: repos.Variant}
+	audited.Cart = &auditedCartService{CartService: services.Cart, auditor: a}
+	audited.Order = &auditedOrderService{OrderService: services.Order, auditor: a, orderRepo: repos.Order}
+	audited.Webhook = &auditedWebhookService{WebhookService: services.Webhook, auditor: a}
+	audited.Allowance = &auditedAllowanceService{AllowanceService: services.Allowance, auditor: a}
+	audited.Audit = audit
+
+	return &audited
+}
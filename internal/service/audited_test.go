@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/avito/internal/domain"
+)
+
+type auditRecord struct {
+	action        domain.AuditAction
+	before, after any
+}
+
+type fakeAuditService struct {
+	domain.AuditService
+	records []auditRecord
+}
+
+func (s *fakeAuditService) Record(_ context.Context, action domain.AuditAction, _, _ string, before, after any) error {
+	s.records = append(s.records, auditRecord{action, before, after})
+	return nil
+}
+
+type loginUserService struct {
+	domain.UserService
+	err error
+}
+
+func (s *loginUserService) Login(context.Context, string, string) (string, error) {
+	return "token", s.err
+}
+
+func TestAuditedUserServiceLoginFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantReason string
+	}{
+		{name: "wrong password", err: domain.ErrInvalidCredentials, wantReason: "invalid_credentials"},
+		{name: "database error", err: errors.New(`pq: relation "users" does not exist`), wantReason: "internal_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &fakeAuditService{}
+			services := withAudit(&domain.Services{User: &loginUserService{err: tt.err}}, audit, &domain.Repositories{}, discardLogger)
+
+			if _, err := services.User.Login(context.Background(), "alice", "secret"); err != tt.err {
+				t.Fatalf("Login() error = %v, want %v", err, tt.err)
+			}
+
+			// Текст ошибки в журнал не попадает
+			want := []auditRecord{{action: domain.AuditUserLoginFailed, after: map[string]any{"reason": tt.wantReason}}}
+			if !reflect.DeepEqual(audit.records, want) {
+				t.Errorf("audit records = %+v, want %+v", audit.records, want)
+			}
+		})
+	}
+}
+
+// Сервисы ниже возвращают балансы после операции, как их возвращает репозиторий
+type transferService struct{ domain.TransactionService }
+
+func (transferService) Transfer(_ context.Context, fromUserID, toUserID, amount int64, _ string, _ domain.KudosOptions) (*domain.Transaction, error) {
+	return &domain.Transaction{FromUserID: fromUserID, ToUserID: toUserID, Amount: amount, SenderBalance: 700}, nil
+}
+
+type buyService struct{ domain.MerchService }
+
+func (buyService) Buy(_ context.Context, userID, merchID int64, _ *int64, quantity int) (*domain.Purchase, error) {
+	return &domain.Purchase{UserID: userID, MerchID: merchID, Quantity: quantity, TotalPrice: 80, BuyerBalance: 420}, nil
+}
+
+type checkoutService struct{ domain.CartService }
+
+func (checkoutService) Checkout(_ context.Context, userID int64, _ domain.DeliveryDetails) (*domain.Order, error) {
+	return &domain.Order{ID: 5, UserID: userID, TotalPrice: 300, BuyerBalance: 200}, nil
+}
+
+type cancelService struct{ domain.OrderService }
+
+func (cancelService) UpdateStatus(_ context.Context, orderID int64, status domain.OrderStatus) (*domain.Order, error) {
+	return &domain.Order{ID: orderID, UserID: 1, Status: status, TotalPrice: 300, BuyerBalance: 500}, nil
+}
+
+type statusOrderRepo struct {
+	domain.OrderRepository
+	status domain.OrderStatus
+}
+
+func (r *statusOrderRepo) GetByID(_ context.Context, id int64) (*domain.Order, error) {
+	return &domain.Order{ID: id, Status: r.status}, nil
+}
+
+func TestAuditedServicesBalanceSnapshots(t *testing.T) {
+	services := &domain.Services{
+		Transaction: transferService{},
+		Merch:       buyService{},
+		Cart:        checkoutService{},
+		Order:       cancelService{},
+	}
+	// Репозиторий пользователей возвращает устаревший баланс: журнал не должен его перечитывать
+	repos := &domain.Repositories{
+		User:  &fakeUserRepo{users: map[int64]*domain.User{1: {ID: 1, Balance: 1}}},
+		Order: &statusOrderRepo{status: domain.OrderStatusPlaced},
+	}
+
+	tests := []struct {
+		name          string
+		call          func(ctx context.Context, s *domain.Services) error
+		action        domain.AuditAction
+		before, after int64
+	}{
+		{
+			name: "transfer",
+			call: func(ctx context.Context, s *domain.Services) error {
+				_, err := s.Transaction.Transfer(ctx, 1, 2, 300, "спасибо", domain.KudosOptions{})
+				return err
+			},
+			action: domain.AuditTransfer, before: 1000, after: 700,
+		},
+		{
+			name: "buy",
+			call: func(ctx context.Context, s *domain.Services) error {
+				_, err := s.Merch.Buy(ctx, 1, 3, nil, 1)
+				return err
+			},
+			action: domain.AuditPurchase, before: 500, after: 420,
+		},
+		{
+			name: "checkout",
+			call: func(ctx context.Context, s *domain.Services) error {
+				_, err := s.Cart.Checkout(ctx, 1, domain.DeliveryDetails{})
+				return err
+			},
+			action: domain.AuditCheckout, before: 500, after: 200,
+		},
+		{
+			name: "cancel refund",
+			call: func(ctx context.Context, s *domain.Services) error {
+				_, err := s.Order.UpdateStatus(ctx, 5, domain.OrderStatusCancelled)
+				return err
+			},
+			action: domain.AuditOrderCancel, before: 200, after: 500,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audit := &fakeAuditService{}
+			if err := tt.call(context.Background(), withAudit(services, audit, repos, discardLogger)); err != nil {
+				t.Fatalf("call error = %v", err)
+			}
+
+			var record *auditRecord
+			for i := range audit.records {
+				if audit.records[i].action == tt.action {
+					record = &audit.records[i]
+				}
+			}
+			if record == nil {
+				t.Fatalf("no %s record in %+v", tt.action, audit.records)
+			}
+
+			before, _ := record.before.(map[string]any)
+			after, _ := record.after.(map[string]any)
+			if before["balance"] != tt.before || after["balance"] != tt.after {
+				t.Errorf("balance before/after = %v/%v, want %d/%d", before["balance"], after["balance"], tt.before, tt.after)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/avito/internal/domain"
+)
+
+type CartService struct {
+	cartRepo      domain.CartRepository
+	merchRepo     domain.MerchRepository
+	variantRepo   domain.MerchVariantRepository
+	orderRepo     domain.OrderRepository
+	userRepo      domain.UserRepository
+	notifications domain.NotificationService
+	events        domain.EventBus
+	logger        *slog.Logger
+}
+
+func NewCartService(
+	cartRepo domain.CartRepository,
+	merchRepo domain.MerchRepository,
+	variantRepo domain.MerchVariantRepository,
+	orderRepo domain.OrderRepository,
+	userRepo domain.UserRepository,
+	notifications domain.NotificationService,
+	events domain.EventBus,
+	logger *slog.Logger,
+) *CartService {
+	return &CartService{
+		cartRepo:      cartRepo,
+		merchRepo:     merchRepo,
+		variantRepo:   variantRepo,
+		orderRepo:     orderRepo,
+		userRepo:      userRepo,
+		notifications: notifications,
+		events:        events,
+		logger:        logger,
+	}
+}
+
+func (s *CartService) Get(ctx context.Context, userID int64) (*domain.Cart, error) {
+	ctx, span := startSpan(ctx, "CartService.Get")
+	defer span.End()
+
+	items, err := s.cartRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart := &domain.Cart{Items: items}
+	if cart.Items == nil {
+		cart.Items = []*domain.CartItem{}
+	}
+	for _, item := range items {
+		cart.TotalPrice += item.TotalPrice
+	}
+
+	return cart, nil
+}
+
+func (s *CartService) AddItem(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) error {
+	ctx, span := startSpan(ctx, "CartService.AddItem")
+	defer span.End()
+
+	if quantity <= 0 {
+		return domain.ErrInvalidQuantity
+	}
+
+	// Проверяем существование мерча и выбранного варианта
+	merch, err := s.merchRepo.GetByID(ctx, merchID)
+	if err != nil {
+		return domain.ErrMerchNotFound
+	}
+
+	if _, err := resolveVariant(ctx, s.variantRepo, merch, variantID); err != nil {
+		return err
+	}
+
+	return s.cartRepo.AddItem(ctx, userID, merchID, variantID, quantity)
+}
+
+func (s *CartService) UpdateItem(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) error {
+	ctx, span := startSpan(ctx, "CartService.UpdateItem")
+	defer span.End()
+
+	if quantity <= 0 {
+		return domain.ErrInvalidQuantity
+	}
+
+	return s.cartRepo.SetQuantity(ctx, userID, merchID, variantID, quantity)
+}
+
+func (s *CartService) RemoveItem(ctx context.Context, userID, merchID int64, variantID *int64) error {
+	ctx, span := startSpan(ctx, "CartService.RemoveItem")
+	defer span.End()
+
+	return s.cartRepo.RemoveItem(ctx, userID, merchID, variantID)
+}
+
+func (s *CartService) Checkout(ctx context.Context, userID int64, delivery domain.DeliveryDetails) (*domain.Order, error) {
+	ctx, span := startSpan(ctx, "CartService.Checkout")
+	defer span.End()
+
+	if err := delivery.Validate(); err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderRepo.Checkout(ctx, userID, delivery)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrCartEmpty):
+			return nil, domain.ErrCartEmpty
+		case errors.Is(err, domain.ErrInsufficientFunds):
+			return nil, domain.ErrInsufficientFunds
+		case errors.Is(err, domain.ErrOutOfStock):
+			return nil, domain.ErrOutOfStock
+		case errors.Is(err, domain.ErrUserNotFound):
+			return nil, domain.ErrUserNotFound
+		default:
+			return nil, domain.ErrTransactionFailed
+		}
+	}
+
+	message := fmt.Sprintf("Заказ №%d оформлен на %d монет", order.ID, order.TotalPrice)
+	payload := map[string]any{
+		"order_id":    order.ID,
+		"total_price": order.TotalPrice,
+	}
+	if err := s.notifications.Notify(ctx, userID, domain.NotificationPurchaseCompleted, message, payload); err != nil {
+		s.logger.WarnContext(ctx, "failed to notify buyer", "user_id", userID, "order_id", order.ID, "error", err)
+	}
+	publishBalance(ctx, s.logger, s.events, s.userRepo, userID)
+
+	return order, nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/avito/internal/domain"
+)
+
+// Фейки встраивают интерфейсы домена: неиспользуемые методы паникуют при вызове
+
+type fakeCartRepo struct {
+	domain.CartRepository
+	added []int64
+}
+
+func (r *fakeCartRepo) AddItem(_ context.Context, _, merchID int64, _ *int64, _ int) error {
+	r.added = append(r.added, merchID)
+	return nil
+}
+
+type fakeMerchRepo struct {
+	domain.MerchRepository
+	merch map[int64]*domain.Merch
+}
+
+func (r *fakeMerchRepo) GetByID(_ context.Context, id int64) (*domain.Merch, error) {
+	if merch, ok := r.merch[id]; ok {
+		return merch, nil
+	}
+	return nil, fmt.Errorf("merch %d not found", id)
+}
+
+type fakeVariantRepo struct {
+	domain.MerchVariantRepository
+	variants []*domain.MerchVariant
+}
+
+func (r *fakeVariantRepo) GetByID(_ context.Context, id int64) (*domain.MerchVariant, error) {
+	for _, variant := range r.variants {
+		if variant.ID == id {
+			return variant, nil
+		}
+	}
+	return nil, domain.ErrVariantNotFound
+}
+
+func (r *fakeVariantRepo) GetByMerchID(_ context.Context, merchID int64) ([]*domain.MerchVariant, error) {
+	var variants []*domain.MerchVariant
+	for _, variant := range r.variants {
+		if variant.MerchID == merchID {
+			variants = append(variants, variant)
+		}
+	}
+	return variants, nil
+}
+
+type fakeOrderRepo struct {
+	domain.OrderRepository
+	order *domain.Order
+	err   error
+}
+
+func (r *fakeOrderRepo) Checkout(context.Context, int64, domain.DeliveryDetails) (*domain.Order, error) {
+	return r.order, r.err
+}
+
+func TestCartServiceAddItem(t *testing.T) {
+	variant := func(id int64) *int64 { return &id }
+
+	tests := []struct {
+		name      string
+		merchID   int64
+		variantID *int64
+		quantity  int
+		wantErr   error
+	}{
+		{name: "adds item", merchID: 1, quantity: 2},
+		{name: "adds variant", merchID: 2, variantID: variant(10), quantity: 1},
+		{name: "zero quantity", merchID: 1, quantity: 0, wantErr: domain.ErrInvalidQuantity},
+		{name: "negative quantity", merchID: 1, quantity: -1, wantErr: domain.ErrInvalidQuantity},
+		{name: "unknown merch", merchID: 3, quantity: 1, wantErr: domain.ErrMerchNotFound},
+		{name: "variant required", merchID: 2, quantity: 1, wantErr: domain.ErrVariantRequired},
+		{name: "variant of other merch", merchID: 1, variantID: variant(10), quantity: 1, wantErr: domain.ErrVariantNotFound},
+		{name: "unknown variant", merchID: 2, variantID: variant(11), quantity: 1, wantErr: domain.ErrVariantNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			carts := &fakeCartRepo{}
+			merch := &fakeMerchRepo{merch: map[int64]*domain.Merch{
+				1: {ID: 1, Name: "cup", Price: 20},
+				2: {ID: 2, Name: "t-shirt", Price: 80},
+			}}
+			variants := &fakeVariantRepo{variants: []*domain.MerchVariant{{ID: 10, MerchID: 2, SKU: "t-shirt-m", Stock: 5}}}
+			s := NewCartService(carts, merch, variants, &fakeOrderRepo{}, &fakeUserRepo{}, &nopNotifications{}, nopEvents{}, discardLogger)
+
+			err := s.AddItem(context.Background(), 1, tt.merchID, tt.variantID, tt.quantity)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("AddItem() error = %v, want %v", err, tt.wantErr)
+			}
+			if wantAdded := tt.wantErr == nil; wantAdded != (len(carts.added) == 1) {
+				t.Errorf("cart items added = %d, want added: %v", len(carts.added), wantAdded)
+			}
+		})
+	}
+}
+
+func TestCartServiceCheckout(t *testing.T) {
+	office := "Москва, Лесная 7"
+	delivery := domain.DeliveryDetails{Method: domain.DeliveryMethodOffice, OfficeLocation: &office}
+
+	tests := []struct {
+		name     string
+		delivery *domain.DeliveryDetails
+		repoErr  error
+		wantErr  error
+	}{
+		{name: "success"},
+		{name: "invalid delivery", delivery: &domain.DeliveryDetails{Method: domain.DeliveryMethodPickup}, wantErr: domain.ErrInvalidDelivery},
+		{name: "empty cart", repoErr: domain.ErrCartEmpty, wantErr: domain.ErrCartEmpty},
+		{name: "insufficient funds", repoErr: fmt.Errorf("checkout: %w", domain.ErrInsufficientFunds), wantErr: domain.ErrInsufficientFunds},
+		{name: "out of stock", repoErr: fmt.Errorf("checkout: %w", domain.ErrOutOfStock), wantErr: domain.ErrOutOfStock},
+		{name: "unknown user", repoErr: domain.ErrUserNotFound, wantErr: domain.ErrUserNotFound},
+		{name: "database error", repoErr: errors.New("connection reset"), wantErr: domain.ErrTransactionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &fakeOrderRepo{order: &domain.Order{ID: 1, TotalPrice: 40}, err: tt.repoErr}
+			s := NewCartService(&fakeCartRepo{}, &fakeMerchRepo{}, &fakeVariantRepo{}, orders, &fakeUserRepo{}, &nopNotifications{}, nopEvents{}, discardLogger)
+
+			details := delivery
+			if tt.delivery != nil {
+				details = *tt.delivery
+			}
+
+			order, err := s.Checkout(context.Background(), 1, details)
+			if err != tt.wantErr {
+				t.Fatalf("Checkout() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && order.ID != 1 {
+				t.Errorf("Checkout() order = %+v, want order 1", order)
+			}
+		})
+	}
+}
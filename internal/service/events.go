@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+// publishEvent отправляет событие клиентам пользователя.
+// Операция к этому моменту уже выполнена, поэтому ошибки доставки только логируются.
+func publishEvent(ctx context.Context, logger *slog.Logger, bus domain.EventBus, userID int64, kind domain.EventType, data any) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to marshal event", "event", kind, "error", err)
+		return
+	}
+
+	event := &domain.Event{
+		Type:      kind,
+		UserID:    userID,
+		Data:      payload,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := bus.Publish(ctx, event); err != nil {
+		logger.WarnContext(ctx, "failed to publish event", "event", kind, "user_id", userID, "error", err)
+	}
+}
+
+// publishBalance отправляет пользователю актуальный баланс
+func publishBalance(ctx context.Context, logger *slog.Logger, bus domain.EventBus, userRepo domain.UserRepository, userID int64) {
+	user, err := userRepo.GetByID(ctx, userID)
+	if err != nil {
+		logger.WarnContext(ctx, "failed to load balance", "user_id", userID, "error", err)
+		return
+	}
+
+	publishEvent(ctx, logger, bus, userID, domain.EventBalanceChanged, map[string]any{
+		"balance": user.Balance,
+	})
+}
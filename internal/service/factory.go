@@ -5,12 +5,74 @@ import "github.com/avito/internal/domain"
 // NewServices создает новый экземпляр всех сервисов
 func NewServices(deps domain.Deps) *domain.Services {
 	userService := NewUserService(deps.Repos.User, deps.TokenSecret)
-	merchService := NewMerchService(deps.Repos.Merch, deps.Repos.Purchase, deps.Repos.User)
-	transactionService := NewTransactionService(deps.Repos.Transaction, deps.Repos.User)
+	notificationService := NewNotificationService(deps.Repos.Notification)
+	wishlistService := NewWishlistService(deps.Repos.Wishlist, deps.Repos.Merch, deps.Repos.Variant, notificationService, deps.Logger)
+	merchService := NewMerchService(
+		deps.Repos.Merch,
+		deps.Repos.Variant,
+		deps.Repos.Purchase,
+		deps.Repos.User,
+		deps.Blobs,
+		wishlistService,
+		notificationService,
+		deps.Events,
+		deps.MaxImageSize,
+		deps.Logger,
+	)
+	transactionService := NewTransactionService(
+		deps.Repos.Transaction,
+		deps.Repos.User,
+		wishlistService,
+		notificationService,
+		deps.Events,
+		deps.Logger,
+	)
+	cartService := NewCartService(
+		deps.Repos.Cart,
+		deps.Repos.Merch,
+		deps.Repos.Variant,
+		deps.Repos.Order,
+		deps.Repos.User,
+		notificationService,
+		deps.Events,
+		deps.Logger,
+	)
+	orderService := NewOrderService(
+		deps.Repos.Order,
+		deps.Repos.User,
+		notificationService,
+		wishlistService,
+		deps.Events,
+		deps.Logger,
+	)
+	statementService := NewStatementService(deps.Repos.Snapshot, deps.Repos.User, deps.Repos.Transaction, deps.Repos.Purchase)
+	webhookService := NewWebhookService(deps.Repos.Webhook)
+	auditService := NewAuditService(deps.Repos.Audit)
+	allowanceService := NewAllowanceService(deps.Repos.Allowance, wishlistService, deps.CoinExpiry, deps.Logger)
+	kudosService := NewKudosService(deps.Repos.Kudos)
+	analyticsService := NewAnalyticsService(deps.Repos.Analytics)
 
-	return &domain.Services{
-		User:        userService,
-		Merch:       merchService,
-		Transaction: transactionService,
+	services := &domain.Services{
+		User:         userService,
+		Merch:        merchService,
+		Transaction:  transactionService,
+		Cart:         cartService,
+		Order:        orderService,
+		Statement:    statementService,
+		Wishlist:     wishlistService,
+		Notification: notificationService,
+		Webhook:      webhookService,
+		Allowance:    allowanceService,
+		Kudos:        kudosService,
+		Analytics:    analyticsService,
+		Events:       deps.Events,
+		Metrics:      deps.Metrics,
 	}
+
+	services = withAudit(services, auditService, deps.Repos, deps.Logger)
+	if deps.Metrics != nil {
+		services = withMetrics(services, deps.Metrics)
+	}
+
+	return services
 }
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/avito/internal/domain"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	// thumbnailSize - максимальная сторона миниатюры в пикселях
+	thumbnailSize = 256
+	// maxImagePixels защищает от изображений, которые раздуваются при декодировании
+	maxImagePixels = 40_000_000
+)
+
+// imageExtensions содержит допустимые типы изображений и расширения файлов для них
+var imageExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/webp": "webp",
+}
+
+// processedImage содержит исходное изображение и сгенерированную миниатюру
+type processedImage struct {
+	ext          string
+	thumbnail    []byte
+	thumbnailExt string
+}
+
+// processImage проверяет тип и размеры изображения и строит миниатюру
+func processImage(data []byte) (*processedImage, error) {
+	ext, ok := imageExtensions[http.DetectContentType(data)]
+	if !ok {
+		return nil, domain.ErrInvalidImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width*cfg.Height > maxImagePixels {
+		return nil, domain.ErrInvalidImage
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, domain.ErrInvalidImage
+	}
+
+	thumb := resizeToFit(src, thumbnailSize)
+
+	// PNG сохраняем в PNG ради прозрачности, остальное сжимаем в JPEG
+	var buf bytes.Buffer
+	result := &processedImage{ext: ext}
+	if ext == "png" {
+		result.thumbnailExt = "png"
+		err = png.Encode(&buf, thumb)
+	} else {
+		result.thumbnailExt = "jpg"
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 85})
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.thumbnail = buf.Bytes()
+
+	return result, nil
+}
+
+// resizeToFit уменьшает изображение с сохранением пропорций, чтобы большая сторона не превышала size
+func resizeToFit(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= size && h <= size {
+		return src
+	}
+
+	if w >= h {
+		h = h * size / w
+		w = size
+	} else {
+		w = w * size / h
+		h = size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, max(w, 1), max(h, 1)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
+}
+
+// randomName возвращает случайное имя файла, чтобы новые изображения не попадали в кеш старых
+func randomName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
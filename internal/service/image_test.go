@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/avito/internal/domain"
+)
+
+func TestResizeToFit(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		size          int
+		wantW, wantH  int
+	}{
+		{name: "smaller than size", width: 100, height: 50, size: 256, wantW: 100, wantH: 50},
+		{name: "exactly size", width: 256, height: 256, size: 256, wantW: 256, wantH: 256},
+		{name: "landscape", width: 1024, height: 512, size: 256, wantW: 256, wantH: 128},
+		{name: "portrait", width: 300, height: 600, size: 256, wantW: 128, wantH: 256},
+		{name: "square", width: 1000, height: 1000, size: 256, wantW: 256, wantH: 256},
+		{name: "rounds down", width: 1000, height: 333, size: 256, wantW: 256, wantH: 85},
+		{name: "thin line keeps one pixel", width: 5000, height: 1, size: 256, wantW: 256, wantH: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+			got := resizeToFit(src, tt.size).Bounds()
+			if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+				t.Errorf("resizeToFit(%dx%d, %d) = %dx%d, want %dx%d",
+					tt.width, tt.height, tt.size, got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestProcessImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		wantErr       error
+		wantExt       string
+		wantThumbExt  string
+		wantThumbSize image.Point
+	}{
+		{
+			name:          "png",
+			data:          encodePNG(t, 512, 256),
+			wantExt:       "png",
+			wantThumbExt:  "png",
+			wantThumbSize: image.Pt(256, 128),
+		},
+		{
+			name:          "jpeg",
+			data:          encodeJPEG(t, 100, 400),
+			wantExt:       "jpg",
+			wantThumbExt:  "jpg",
+			wantThumbSize: image.Pt(64, 256),
+		},
+		{
+			name:          "small png is not upscaled",
+			data:          encodePNG(t, 10, 20),
+			wantExt:       "png",
+			wantThumbExt:  "png",
+			wantThumbSize: image.Pt(10, 20),
+		},
+		{name: "gif", data: encodeGIF(t, 10, 10), wantErr: domain.ErrInvalidImage},
+		{name: "text", data: []byte("not an image"), wantErr: domain.ErrInvalidImage},
+		{name: "empty", data: nil, wantErr: domain.ErrInvalidImage},
+		{name: "truncated png", data: encodePNG(t, 64, 64)[:40], wantErr: domain.ErrInvalidImage},
+		{name: "too many pixels", data: pngWithSize(t, 8000, 8000), wantErr: domain.ErrInvalidImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := processImage(tt.data)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("processImage() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("processImage(): %v", err)
+			}
+
+			if result.ext != tt.wantExt || result.thumbnailExt != tt.wantThumbExt {
+				t.Errorf("ext = %s, thumbnail ext = %s, want %s and %s",
+					result.ext, result.thumbnailExt, tt.wantExt, tt.wantThumbExt)
+			}
+
+			thumb, _, err := image.DecodeConfig(bytes.NewReader(result.thumbnail))
+			if err != nil {
+				t.Fatalf("failed to decode thumbnail: %v", err)
+			}
+			if got := image.Pt(thumb.Width, thumb.Height); got != tt.wantThumbSize {
+				t.Errorf("thumbnail size = %v, want %v", got, tt.wantThumbSize)
+			}
+		})
+	}
+}
+
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, 0, color.RGBA{R: 255, A: 255})
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(width, height)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(width, height), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeGIF(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gif.Encode(&buf, testImage(width, height), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngWithSize возвращает маленький PNG, в заголовке которого указаны размеры width x height
+func pngWithSize(t *testing.T, width, height uint32) []byte {
+	t.Helper()
+	data := encodePNG(t, 1, 1)
+
+	// Сигнатура 8 байт, затем длина и тип чанка IHDR, его данные начинаются с ширины и высоты
+	const ihdr = 8 + 4
+	binary.BigEndian.PutUint32(data[ihdr+4:], width)
+	binary.BigEndian.PutUint32(data[ihdr+8:], height)
+	binary.BigEndian.PutUint32(data[ihdr+4+13:], crc32.ChecksumIEEE(data[ihdr:ihdr+4+13]))
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width != int(width) || cfg.Height != int(height) {
+		t.Fatalf("failed to build %dx%d png header: %v", width, height, err)
+	}
+	return data
+}
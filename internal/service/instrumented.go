@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+
+	"github.com/avito/internal/domain"
+)
+
+// instrumentedUserService учитывает неудачные входы
+type instrumentedUserService struct {
+	domain.UserService
+	metrics domain.BusinessMetrics
+}
+
+func (s *instrumentedUserService) Auth(ctx context.Context, username, password string) (string, error) {
+	token, err := s.UserService.Auth(ctx, username, password)
+	if err != nil {
+		s.metrics.AuthFailed(failureReason(err))
+	}
+	return token, err
+}
+
+func (s *instrumentedUserService) Login(ctx context.Context, username, password string) (string, error) {
+	token, err := s.UserService.Login(ctx, username, password)
+	if err != nil {
+		s.metrics.AuthFailed(failureReason(err))
+	}
+	return token, err
+}
+
+// instrumentedTransactionService учитывает переводы, корректировки и причины отказов
+type instrumentedTransactionService struct {
+	domain.TransactionService
+	metrics domain.BusinessMetrics
+}
+
+func (s *instrumentedTransactionService) Transfer(ctx context.Context, fromUserID, toUserID int64, amount int64, description string, kudos domain.KudosOptions) (*domain.Transaction, error) {
+	transaction, err := s.TransactionService.Transfer(ctx, fromUserID, toUserID, amount, description, kudos)
+	if err != nil {
+		s.metrics.TransferFailed(failureReason(err))
+		return nil, err
+	}
+
+	s.metrics.TransferCompleted(domain.TransactionKindTransfer, amount)
+	return transaction, nil
+}
+
+func (s *instrumentedTransactionService) Adjust(ctx context.Context, adminID int64, adjustment domain.BalanceAdjustment) (*domain.Transaction, error) {
+	transaction, err := s.TransactionService.Adjust(ctx, adminID, adjustment)
+	if err != nil {
+		return nil, err
+	}
+
+	s.metrics.TransferCompleted(transaction.Kind, transaction.Amount)
+	return transaction, nil
+}
+
+// instrumentedMerchService учитывает покупки
+type instrumentedMerchService struct {
+	domain.MerchService
+	metrics domain.BusinessMetrics
+}
+
+func (s *instrumentedMerchService) Buy(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) (*domain.Purchase, error) {
+	purchase, err := s.MerchService.Buy(ctx, userID, merchID, variantID, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	s.metrics.PurchaseCompleted("buy", purchase.TotalPrice)
+	return purchase, nil
+}
+
+// instrumentedCartService учитывает оформленные корзины
+type instrumentedCartService struct {
+	domain.CartService
+	metrics domain.BusinessMetrics
+}
+
+func (s *instrumentedCartService) Checkout(ctx context.Context, userID int64, delivery domain.DeliveryDetails) (*domain.Order, error) {
+	order, err := s.CartService.Checkout(ctx, userID, delivery)
+	if err != nil {
+		return nil, err
+	}
+
+	s.metrics.PurchaseCompleted("cart", order.TotalPrice)
+	return order, nil
+}
+
+// instrumentedOrderService учитывает возвраты за отмененные заказы
+type instrumentedOrderService struct {
+	domain.OrderService
+	metrics domain.BusinessMetrics
+}
+
+func (s *instrumentedOrderService) UpdateStatus(ctx context.Context, orderID int64, status domain.OrderStatus) (*domain.Order, error) {
+	order, err := s.OrderService.UpdateStatus(ctx, orderID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	// Повторная отмена отклоняется, поэтому каждая успешная отмена - ровно один возврат
+	if status == domain.OrderStatusCancelled && order.TotalPrice > 0 {
+		s.metrics.TransferCompleted(domain.TransactionKindRefund, order.TotalPrice)
+	}
+	return order, nil
+}
+
+// failureReason возвращает код ошибки для метки метрики, не раскрывая текст внутренних ошибок
+func failureReason(err error) string {
+	switch err {
+	case domain.ErrInvalidCredentials:
+		return "invalid_credentials"
+	case domain.ErrUserNotFound:
+		return "user_not_found"
+	case domain.ErrInsufficientFunds:
+		return "insufficient_funds"
+	case domain.ErrInvalidAmount:
+		return "invalid_amount"
+	case domain.ErrInvalidKudos:
+		return "invalid_kudos"
+	case domain.ErrTransactionFailed:
+		return "transaction_failed"
+	default:
+		return "internal_error"
+	}
+}
+
+// withMetrics оборачивает сервисы декораторами, учитывающими бизнес-события
+func withMetrics(services *domain.Services, metrics domain.BusinessMetrics) *domain.Services {
+	instrumented := *services
+	instrumented.User = &instrumentedUserService{UserService: services.User, metrics: metrics}
+	instrumented.Transaction = &instrumentedTransactionService{TransactionService: services.Transaction, metrics: metrics}
+	instrumented.Merch = &instrumentedMerchService{MerchService: services.Merch, metrics: metrics}
+	instrumented.Cart = &instrumentedCartService{CartService: services.Cart, metrics: metrics}
+	instrumented.Order = &instrumentedOrderService{OrderService: services.Order, metrics: metrics}
+
+	return &instrumented
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+// leaderboardMaxLimit ограничивает размер рейтинга
+const leaderboardMaxLimit = 100
+
+type KudosService struct {
+	repo domain.KudosRepository
+}
+
+func NewKudosService(repo domain.KudosRepository) *KudosService {
+	return &KudosService{repo: repo}
+}
+
+func (s *KudosService) Feed(ctx context.Context, category domain.KudosCategory, params pagination.Params) (*pagination.Page[*domain.Kudos], error) {
+	ctx, span := startSpan(ctx, "KudosService.Feed")
+	defer span.End()
+
+	if category != "" && !category.IsValid() {
+		return nil, domain.ErrInvalidFilter
+	}
+
+	return s.repo.Feed(ctx, category, params)
+}
+
+func (s *KudosService) Leaderboard(ctx context.Context, period domain.LeaderboardPeriod, category domain.KudosCategory, limit int) ([]*domain.LeaderboardEntry, error) {
+	ctx, span := startSpan(ctx, "KudosService.Leaderboard")
+	defer span.End()
+
+	if !period.IsValid() || (category != "" && !category.IsValid()) {
+		return nil, domain.ErrInvalidFilter
+	}
+	if limit <= 0 || limit > leaderboardMaxLimit {
+		return nil, domain.ErrInvalidFilter
+	}
+
+	return s.repo.Leaderboard(ctx, period.Start(time.Now()), category, limit)
+}
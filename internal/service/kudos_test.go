@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+type fakeKudosRepo struct {
+	domain.KudosRepository
+	since *time.Time
+	calls int
+}
+
+func (r *fakeKudosRepo) Feed(context.Context, domain.KudosCategory, pagination.Params) (*pagination.Page[*domain.Kudos], error) {
+	r.calls++
+	return &pagination.Page[*domain.Kudos]{Items: []*domain.Kudos{}}, nil
+}
+
+func (r *fakeKudosRepo) Leaderboard(_ context.Context, since *time.Time, _ domain.KudosCategory, _ int) ([]*domain.LeaderboardEntry, error) {
+	r.calls++
+	r.since = since
+	return []*domain.LeaderboardEntry{}, nil
+}
+
+func TestKudosServiceFeed(t *testing.T) {
+	tests := []struct {
+		name     string
+		category domain.KudosCategory
+		wantErr  error
+	}{
+		{name: "no category"},
+		{name: "category", category: domain.KudosMentoring},
+		{name: "unknown category", category: "gratitude", wantErr: domain.ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeKudosRepo{}
+			s := NewKudosService(repo)
+
+			_, err := s.Feed(context.Background(), tt.category, pagination.Params{Limit: 10})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Feed() error = %v, want %v", err, tt.wantErr)
+			}
+			// Некорректный фильтр не доходит до базы
+			if tt.wantErr != nil && repo.calls != 0 {
+				t.Errorf("repository calls = %d, want 0", repo.calls)
+			}
+		})
+	}
+}
+
+func TestKudosServiceLeaderboard(t *testing.T) {
+	tests := []struct {
+		name      string
+		period    domain.LeaderboardPeriod
+		category  domain.KudosCategory
+		limit     int
+		wantErr   error
+		wantSince bool
+	}{
+		{name: "week", period: domain.LeaderboardWeek, limit: 10, wantSince: true},
+		{name: "all time", period: domain.LeaderboardAll, category: domain.KudosHelp, limit: leaderboardMaxLimit},
+		{name: "unknown period", period: "day", limit: 10, wantErr: domain.ErrInvalidFilter},
+		{name: "unknown category", period: domain.LeaderboardMonth, category: "gratitude", limit: 10, wantErr: domain.ErrInvalidFilter},
+		{name: "zero limit", period: domain.LeaderboardMonth, wantErr: domain.ErrInvalidFilter},
+		{name: "limit too large", period: domain.LeaderboardMonth, limit: leaderboardMaxLimit + 1, wantErr: domain.ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeKudosRepo{}
+			s := NewKudosService(repo)
+
+			_, err := s.Leaderboard(context.Background(), tt.period, tt.category, tt.limit)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Leaderboard() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if repo.calls != 0 {
+					t.Errorf("repository calls = %d, want 0", repo.calls)
+				}
+				return
+			}
+			if (repo.since != nil) != tt.wantSince {
+				t.Errorf("since = %v, want set: %t", repo.since, tt.wantSince)
+			}
+		})
+	}
+}
@@ -1,95 +1,317 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 
 	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
 )
 
 type MerchService struct {
-	merchRepo    domain.MerchRepository
-	purchaseRepo domain.PurchaseRepository
-	userRepo     domain.UserRepository
+	merchRepo     domain.MerchRepository
+	variantRepo   domain.MerchVariantRepository
+	purchaseRepo  domain.PurchaseRepository
+	userRepo      domain.UserRepository
+	blobs         domain.BlobStore
+	wishlist      domain.WishlistService
+	notifications domain.NotificationService
+	events        domain.EventBus
+	maxImageSize  int64
+	logger        *slog.Logger
 }
 
 func NewMerchService(
 	merchRepo domain.MerchRepository,
+	variantRepo domain.MerchVariantRepository,
 	purchaseRepo domain.PurchaseRepository,
 	userRepo domain.UserRepository,
+	blobs domain.BlobStore,
+	wishlist domain.WishlistService,
+	notifications domain.NotificationService,
+	events domain.EventBus,
+	maxImageSize int64,
+	logger *slog.Logger,
 ) *MerchService {
 	return &MerchService{
-		merchRepo:    merchRepo,
-		purchaseRepo: purchaseRepo,
-		userRepo:     userRepo,
+		merchRepo:     merchRepo,
+		variantRepo:   variantRepo,
+		purchaseRepo:  purchaseRepo,
+		userRepo:      userRepo,
+		blobs:         blobs,
+		wishlist:      wishlist,
+		notifications: notifications,
+		events:        events,
+		maxImageSize:  maxImageSize,
+		logger:        logger,
 	}
 }
 
-func (s *MerchService) List(ctx context.Context, page, pageSize int) ([]*domain.Merch, error) {
-	if page < 1 {
-		page = 1
+func (s *MerchService) List(ctx context.Context, filter domain.MerchFilter, params pagination.Params) (*pagination.Page[*domain.Merch], error) {
+	ctx, span := startSpan(ctx, "MerchService.List")
+	defer span.End()
+
+	if !filter.Sort.IsValid() {
+		return nil, domain.ErrInvalidFilter
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, domain.ErrInvalidFilter
+	}
+
+	page, err := s.merchRepo.List(ctx, filter, params)
+	if err != nil {
+		return nil, err
 	}
-	if pageSize < 1 {
-		pageSize = 10
+
+	for _, merch := range page.Items {
+		s.fillImageURLs(merch)
 	}
 
-	offset := (page - 1) * pageSize
-	return s.merchRepo.List(ctx, pageSize, offset)
+	return page, nil
 }
 
 func (s *MerchService) GetByID(ctx context.Context, id int64) (*domain.Merch, error) {
+	ctx, span := startSpan(ctx, "MerchService.GetByID")
+	defer span.End()
+
 	merch, err := s.merchRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, domain.ErrMerchNotFound
 	}
+
+	merch.Variants, err = s.variantRepo.GetByMerchID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.fillImageURLs(merch)
 	return merch, nil
 }
 
-func (s *MerchService) Buy(ctx context.Context, userID, merchID int64, quantity int) error {
+func (s *MerchService) Buy(ctx context.Context, userID, merchID int64, variantID *int64, quantity int) (*domain.Purchase, error) {
+	ctx, span := startSpan(ctx, "MerchService.Buy")
+	defer span.End()
+
 	if quantity <= 0 {
-		return domain.ErrInvalidQuantity
+		return nil, domain.ErrInvalidQuantity
 	}
 
 	// Получаем информацию о мерче
 	merch, err := s.merchRepo.GetByID(ctx, merchID)
 	if err != nil {
-		return domain.ErrMerchNotFound
+		return nil, domain.ErrMerchNotFound
+	}
+
+	variant, err := resolveVariant(ctx, s.variantRepo, merch, variantID)
+	if err != nil {
+		return nil, err
+	}
+
+	unitPrice := merch.Price
+	if variant != nil {
+		if variant.Stock < quantity {
+			return nil, domain.ErrOutOfStock
+		}
+		unitPrice = variant.PriceFor(merch)
 	}
 
 	// Проверяем баланс пользователя
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return domain.ErrUserNotFound
+		return nil, domain.ErrUserNotFound
 	}
 
-	totalCost := merch.Price * int64(quantity)
+	totalCost := unitPrice * int64(quantity)
 	if user.Balance < totalCost {
-		return domain.ErrInsufficientFunds
+		return nil, domain.ErrInsufficientFunds
 	}
 
-	// Создаем запись о покупке
+	// Фиксируем цену на момент покупки
 	purchase := &domain.Purchase{
-		UserID:   userID,
-		MerchID:  merchID,
-		Quantity: quantity,
+		UserID:     userID,
+		MerchID:    merchID,
+		VariantID:  variantID,
+		Quantity:   quantity,
+		UnitPrice:  unitPrice,
+		TotalPrice: totalCost,
+	}
+
+	// Списываем баланс и создаем покупку в одной транзакции
+	if err := s.purchaseRepo.Buy(ctx, purchase); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInsufficientFunds):
+			return nil, domain.ErrInsufficientFunds
+		case errors.Is(err, domain.ErrOutOfStock):
+			return nil, domain.ErrOutOfStock
+		case errors.Is(err, domain.ErrUserNotFound):
+			return nil, domain.ErrUserNotFound
+		default:
+			return nil, domain.ErrTransactionFailed
+		}
+	}
+
+	message := fmt.Sprintf("Покупка «%s» оформлена, заказ №%d", merch.Name, purchase.OrderID)
+	payload := map[string]any{
+		"order_id":    purchase.OrderID,
+		"purchase_id": purchase.ID,
+		"merch_id":    merchID,
+		"total_price": totalCost,
+	}
+	if err := s.notifications.Notify(ctx, userID, domain.NotificationPurchaseCompleted, message, payload); err != nil {
+		s.logger.WarnContext(ctx, "failed to notify buyer", "user_id", userID, "order_id", purchase.OrderID, "error", err)
+	}
+	publishBalance(ctx, s.logger, s.events, s.userRepo, userID)
+
+	return purchase, nil
+}
+
+func (s *MerchService) GetUserPurchases(ctx context.Context, userID int64, params pagination.Params) (*pagination.Page[*domain.PurchaseResponse], error) {
+	ctx, span := startSpan(ctx, "MerchService.GetUserPurchases")
+	defer span.End()
+
+	return s.purchaseRepo.GetByUserID(ctx, userID, params)
+}
+
+func (s *MerchService) UploadImage(ctx context.Context, merchID int64, r io.Reader) (*domain.Merch, error) {
+	ctx, span := startSpan(ctx, "MerchService.UploadImage")
+	defer span.End()
+
+	merch, err := s.merchRepo.GetByID(ctx, merchID)
+	if err != nil {
+		return nil, domain.ErrMerchNotFound
+	}
+
+	// Читаем на байт больше лимита, чтобы отличить файл ровно на границе от превышения
+	data, err := io.ReadAll(io.LimitReader(r, s.maxImageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if int64(len(data)) > s.maxImageSize {
+		return nil, domain.ErrImageTooLarge
+	}
+
+	img, err := processImage(data)
+	if err != nil {
+		return nil, err
 	}
 
-	// Обновляем баланс пользователя и создаем покупку в одной транзакции
-	err = s.userRepo.UpdateBalance(ctx, userID, -totalCost)
+	name, err := randomName()
 	if err != nil {
-		return fmt.Errorf("failed to update balance: %w", err)
+		return nil, fmt.Errorf("failed to generate image name: %w", err)
+	}
+	imageKey := fmt.Sprintf("merch/%d/%s.%s", merchID, name, img.ext)
+	thumbnailKey := fmt.Sprintf("merch/%d/%s_thumb.%s", merchID, name, img.thumbnailExt)
+
+	if err := s.blobs.Put(ctx, imageKey, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	if err := s.blobs.Put(ctx, thumbnailKey, bytes.NewReader(img.thumbnail)); err != nil {
+		_ = s.blobs.Delete(ctx, imageKey)
+		return nil, err
 	}
 
-	err = s.purchaseRepo.Create(ctx, purchase)
+	if err := s.merchRepo.SetImage(ctx, merchID, &imageKey, &thumbnailKey); err != nil {
+		_ = s.blobs.Delete(ctx, imageKey)
+		_ = s.blobs.Delete(ctx, thumbnailKey)
+		return nil, err
+	}
+
+	// Старые файлы удаляем после обновления записи, ошибки удаления не критичны
+	s.deleteImageFiles(ctx, merch)
+
+	merch.ImageKey, merch.ThumbnailKey = &imageKey, &thumbnailKey
+	s.fillImageURLs(merch)
+	return merch, nil
+}
+
+func (s *MerchService) DeleteImage(ctx context.Context, merchID int64) error {
+	ctx, span := startSpan(ctx, "MerchService.DeleteImage")
+	defer span.End()
+
+	merch, err := s.merchRepo.GetByID(ctx, merchID)
 	if err != nil {
-		// В случае ошибки возвращаем деньги
-		_ = s.userRepo.UpdateBalance(ctx, userID, totalCost)
-		return domain.ErrTransactionFailed
+		return domain.ErrMerchNotFound
+	}
+
+	if err := s.merchRepo.SetImage(ctx, merchID, nil, nil); err != nil {
+		return err
 	}
 
+	s.deleteImageFiles(ctx, merch)
 	return nil
 }
 
-func (s *MerchService) GetUserPurchases(ctx context.Context, userID int64) ([]*domain.PurchaseResponse, error) {
-	return s.purchaseRepo.GetByUserID(ctx, userID)
+func (s *MerchService) SetVariantStock(ctx context.Context, variantID int64, stock int) (*domain.MerchVariant, error) {
+	ctx, span := startSpan(ctx, "MerchService.SetVariantStock")
+	defer span.End()
+
+	if stock < 0 {
+		return nil, domain.ErrInvalidQuantity
+	}
+
+	previous, err := s.variantRepo.SetStock(ctx, variantID, stock)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ожидающих оповещаем только при переходе из «нет в наличии» в «есть»
+	if previous == 0 && stock > 0 {
+		if err := s.wishlist.NotifyRestocked(ctx, []int64{variantID}); err != nil {
+			s.logger.WarnContext(ctx, "failed to send restock notifications", "variant_id", variantID, "error", err)
+		}
+	}
+
+	return s.variantRepo.GetByID(ctx, variantID)
+}
+
+func (s *MerchService) deleteImageFiles(ctx context.Context, merch *domain.Merch) {
+	if merch.ImageKey != nil {
+		_ = s.blobs.Delete(ctx, *merch.ImageKey)
+	}
+	if merch.ThumbnailKey != nil {
+		_ = s.blobs.Delete(ctx, *merch.ThumbnailKey)
+	}
+}
+
+func (s *MerchService) fillImageURLs(merch *domain.Merch) {
+	if merch.ImageKey != nil {
+		merch.ImageURL = s.blobs.URL(*merch.ImageKey)
+	}
+	if merch.ThumbnailKey != nil {
+		merch.ThumbnailURL = s.blobs.URL(*merch.ThumbnailKey)
+	}
+}
+
+// resolveVariant проверяет выбранный вариант товара.
+// Для товаров без вариантов возвращает nil, для товаров с вариантами вариант обязателен.
+func resolveVariant(
+	ctx context.Context,
+	variantRepo domain.MerchVariantRepository,
+	merch *domain.Merch,
+	variantID *int64,
+) (*domain.MerchVariant, error) {
+	if variantID == nil {
+		variants, err := variantRepo.GetByMerchID(ctx, merch.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(variants) > 0 {
+			return nil, domain.ErrVariantRequired
+		}
+		return nil, nil
+	}
+
+	variant, err := variantRepo.GetByID(ctx, *variantID)
+	if err != nil {
+		return nil, err
+	}
+	if variant.MerchID != merch.ID {
+		return nil, domain.ErrVariantNotFound
+	}
+
+	return variant, nil
 }
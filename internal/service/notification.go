@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+type NotificationService struct {
+	notificationRepo domain.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo domain.NotificationRepository) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+	}
+}
+
+func (s *NotificationService) Notify(ctx context.Context, userID int64, kind domain.NotificationType, message string, payload map[string]any) error {
+	ctx, span := startSpan(ctx, "NotificationService.Notify")
+	defer span.End()
+
+	if payload == nil {
+		payload = map[string]any{}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification payload: %w", err)
+	}
+
+	return s.notificationRepo.Create(ctx, &domain.Notification{
+		UserID:  userID,
+		Type:    kind,
+		Message: message,
+		Payload: data,
+	})
+}
+
+func (s *NotificationService) List(ctx context.Context, userID int64, unreadOnly bool, params pagination.Params) (*domain.NotificationPage, error) {
+	ctx, span := startSpan(ctx, "NotificationService.List")
+	defer span.End()
+
+	page, err := s.notificationRepo.GetByUserID(ctx, userID, unreadOnly, params)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.NotificationPage{Page: page, UnreadCount: unread}, nil
+}
+
+func (s *NotificationService) UnreadCount(ctx context.Context, userID int64) (int64, error) {
+	ctx, span := startSpan(ctx, "NotificationService.UnreadCount")
+	defer span.End()
+
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID, id int64) error {
+	ctx, span := startSpan(ctx, "NotificationService.MarkRead")
+	defer span.End()
+
+	return s.notificationRepo.MarkRead(ctx, userID, id)
+}
+
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID int64) (int64, error) {
+	ctx, span := startSpan(ctx, "NotificationService.MarkAllRead")
+	defer span.End()
+
+	return s.notificationRepo.MarkAllRead(ctx, userID)
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/avito/internal/domain"
+)
+
+type OrderService struct {
+	orderRepo     domain.OrderRepository
+	userRepo      domain.UserRepository
+	notifications domain.NotificationService
+	wishlist      domain.WishlistService
+	events        domain.EventBus
+	logger        *slog.Logger
+}
+
+func NewOrderService(
+	orderRepo domain.OrderRepository,
+	userRepo domain.UserRepository,
+	notifications domain.NotificationService,
+	wishlist domain.WishlistService,
+	events domain.EventBus,
+	logger *slog.Logger,
+) *OrderService {
+	return &OrderService{
+		orderRepo:     orderRepo,
+		userRepo:      userRepo,
+		notifications: notifications,
+		wishlist:      wishlist,
+		events:        events,
+		logger:        logger,
+	}
+}
+
+func (s *OrderService) List(ctx context.Context, userID int64) ([]*domain.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.List")
+	defer span.End()
+
+	orders, err := s.orderRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if orders == nil {
+		orders = []*domain.Order{}
+	}
+	return orders, nil
+}
+
+func (s *OrderService) GetByID(ctx context.Context, userID, orderID int64) (*domain.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.GetByID")
+	defer span.End()
+
+	order, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Чужие заказы не раскрываем
+	if order.UserID != userID {
+		return nil, domain.ErrOrderNotFound
+	}
+
+	return order, nil
+}
+
+func (s *OrderService) UpdateDelivery(ctx context.Context, userID, orderID int64, delivery domain.DeliveryDetails) error {
+	ctx, span := startSpan(ctx, "OrderService.UpdateDelivery")
+	defer span.End()
+
+	if err := delivery.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := s.GetByID(ctx, userID, orderID); err != nil {
+		return err
+	}
+
+	return s.orderRepo.UpdateDelivery(ctx, orderID, delivery)
+}
+
+func (s *OrderService) ListAll(ctx context.Context, status domain.OrderStatus, page, pageSize int) ([]*domain.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.ListAll")
+	defer span.End()
+
+	if status != "" && !isKnownOrderStatus(status) {
+		return nil, domain.ErrInvalidOrderStatus
+	}
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 10
+	}
+
+	orders, err := s.orderRepo.List(ctx, status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	if orders == nil {
+		orders = []*domain.Order{}
+	}
+	return orders, nil
+}
+
+func (s *OrderService) UpdateStatus(ctx context.Context, orderID int64, status domain.OrderStatus) (*domain.Order, error) {
+	ctx, span := startSpan(ctx, "OrderService.UpdateStatus")
+	defer span.End()
+
+	if !isKnownOrderStatus(status) {
+		return nil, domain.ErrInvalidOrderStatus
+	}
+
+	order, restocked, err := s.orderRepo.UpdateStatus(ctx, orderID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	// Отмена возвращает товар на склад - ожидающих оповещаем о появлении в наличии
+	if err := s.wishlist.NotifyRestocked(ctx, restocked); err != nil {
+		s.logger.WarnContext(ctx, "failed to send restock notifications", "order_id", orderID, "error", err)
+	}
+
+	s.notifyStatus(ctx, order)
+
+	publishEvent(ctx, s.logger, s.events, order.UserID, domain.EventOrderStatusChanged, map[string]any{
+		"order_id": order.ID,
+		"status":   order.Status,
+	})
+	// Отмена возвращает монеты на баланс
+	if order.Status == domain.OrderStatusCancelled {
+		publishBalance(ctx, s.logger, s.events, s.userRepo, order.UserID)
+	}
+
+	return order, nil
+}
+
+// notifyStatus сообщает владельцу о принятии заказа в работу и об отправке
+func (s *OrderService) notifyStatus(ctx context.Context, order *domain.Order) {
+	var (
+		kind    domain.NotificationType
+		message string
+	)
+	switch order.Status {
+	case domain.OrderStatusPacked:
+		kind, message = domain.NotificationOrderAccepted, fmt.Sprintf("Заказ №%d принят и собирается", order.ID)
+	case domain.OrderStatusShipped:
+		kind, message = domain.NotificationOrderShipped, fmt.Sprintf("Заказ №%d отправлен", order.ID)
+	default:
+		return
+	}
+
+	payload := map[string]any{
+		"order_id": order.ID,
+		"status":   order.Status,
+	}
+	if err := s.notifications.Notify(ctx, order.UserID, kind, message, payload); err != nil {
+		s.logger.WarnContext(ctx, "failed to notify order owner", "user_id", order.UserID, "order_id", order.ID, "error", err)
+	}
+}
+
+func isKnownOrderStatus(status domain.OrderStatus) bool {
+	switch status {
+	case domain.OrderStatusPlaced,
+		domain.OrderStatusPacked,
+		domain.OrderStatusShipped,
+		domain.OrderStatusDelivered,
+		domain.OrderStatusCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+type StatementService struct {
+	snapshot        domain.Snapshotter
+	userRepo        domain.UserRepository
+	transactionRepo domain.TransactionRepository
+	purchaseRepo    domain.PurchaseRepository
+}
+
+func NewStatementService(
+	snapshot domain.Snapshotter,
+	userRepo domain.UserRepository,
+	transactionRepo domain.TransactionRepository,
+	purchaseRepo domain.PurchaseRepository,
+) *StatementService {
+	return &StatementService{
+		snapshot:        snapshot,
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		purchaseRepo:    purchaseRepo,
+	}
+}
+
+func (s *StatementService) Export(ctx context.Context, userID int64, from, to time.Time, w domain.StatementWriter) error {
+	ctx, span := startSpan(ctx, "StatementService.Export")
+	defer span.End()
+
+	// Баланс, входящий остаток и движения читаются на одном снимке,
+	// иначе параллельный перевод попадет в одни запросы и не попадет в другие
+	return s.snapshot.ReadSnapshot(ctx, func(ctx context.Context) error {
+		return s.export(ctx, userID, from, to, w)
+	})
+}
+
+func (s *StatementService) export(ctx context.Context, userID int64, from, to time.Time, w domain.StatementWriter) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return domain.ErrUserNotFound
+		}
+		return err
+	}
+
+	if from.IsZero() {
+		from = user.CreatedAt
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if !from.Before(to) {
+		return domain.ErrInvalidFilter
+	}
+
+	// Входящий остаток восстанавливаем от текущего баланса, вычитая все движения после from
+	transfersChange, err := s.transactionRepo.NetChangeSince(ctx, userID, from)
+	if err != nil {
+		return err
+	}
+	purchasesChange, err := s.purchaseRepo.NetChangeSince(ctx, userID, from)
+	if err != nil {
+		return err
+	}
+	balance := user.Balance - transfersChange - purchasesChange
+
+	transfers, err := s.transactionRepo.StatementEntries(ctx, userID, from, to)
+	if err != nil {
+		return err
+	}
+	defer transfers.Close()
+
+	purchases, err := s.purchaseRepo.StatementEntries(ctx, userID, from, to)
+	if err != nil {
+		return err
+	}
+	defer purchases.Close()
+
+	if err := w.Begin(from, to, balance); err != nil {
+		return err
+	}
+
+	// Сливаем два отсортированных по времени потока
+	hasTransfer, hasPurchase := transfers.Next(), purchases.Next()
+	for hasTransfer || hasPurchase {
+		var entry *domain.StatementEntry
+		if hasTransfer && (!hasPurchase || !purchases.Entry().CreatedAt.Before(transfers.Entry().CreatedAt)) {
+			entry = transfers.Entry()
+			hasTransfer = transfers.Next()
+		} else {
+			entry = purchases.Entry()
+			hasPurchase = purchases.Next()
+		}
+
+		balance += entry.Amount
+		entry.Balance = balance
+
+		if err := w.Entry(entry); err != nil {
+			return err
+		}
+	}
+
+	if err := transfers.Err(); err != nil {
+		return fmt.Errorf("failed to read statement transactions: %w", err)
+	}
+	if err := purchases.Err(); err != nil {
+		return fmt.Errorf("failed to read statement purchases: %w", err)
+	}
+
+	return w.End(balance)
+}
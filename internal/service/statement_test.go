@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+type snapshotCtxKey struct{}
+
+var errNoSnapshot = errors.New("read outside of snapshot")
+
+// fakeSnapshotter помечает контекст, чтобы фейковые репозитории проверяли чтение внутри снимка
+type fakeSnapshotter struct {
+	calls int
+}
+
+func (s *fakeSnapshotter) ReadSnapshot(ctx context.Context, fn func(ctx context.Context) error) error {
+	s.calls++
+	return fn(context.WithValue(ctx, snapshotCtxKey{}, true))
+}
+
+func requireSnapshot(ctx context.Context) error {
+	if ctx.Value(snapshotCtxKey{}) == nil {
+		return errNoSnapshot
+	}
+	return nil
+}
+
+type snapshotUserRepo struct {
+	fakeUserRepo
+}
+
+func (r *snapshotUserRepo) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	if err := requireSnapshot(ctx); err != nil {
+		return nil, err
+	}
+	return r.fakeUserRepo.GetByID(ctx, id)
+}
+
+type fakePurchaseRepo struct {
+	domain.PurchaseRepository
+	entries []*domain.StatementEntry
+	change  int64
+}
+
+func (r *fakePurchaseRepo) StatementEntries(ctx context.Context, _ int64, _, _ time.Time) (domain.StatementIterator, error) {
+	return newSliceIterator(ctx, r.entries)
+}
+
+func (r *fakePurchaseRepo) NetChangeSince(ctx context.Context, _ int64, _ time.Time) (int64, error) {
+	return r.change, requireSnapshot(ctx)
+}
+
+type sliceIterator struct {
+	entries []*domain.StatementEntry
+	pos     int
+}
+
+func newSliceIterator(ctx context.Context, entries []*domain.StatementEntry) (domain.StatementIterator, error) {
+	if err := requireSnapshot(ctx); err != nil {
+		return nil, err
+	}
+	return &sliceIterator{entries: entries}, nil
+}
+
+func (it *sliceIterator) Next() bool {
+	if it.pos == len(it.entries) {
+		return false
+	}
+	it.pos++
+	return true
+}
+
+func (it *sliceIterator) Entry() *domain.StatementEntry { return it.entries[it.pos-1] }
+func (it *sliceIterator) Err() error                    { return nil }
+func (it *sliceIterator) Close() error                  { return nil }
+
+type recordingWriter struct {
+	opening, closing int64
+	entries          []domain.StatementEntry
+}
+
+func (w *recordingWriter) Begin(_, _ time.Time, openingBalance int64) error {
+	w.opening = openingBalance
+	return nil
+}
+
+func (w *recordingWriter) Entry(entry *domain.StatementEntry) error {
+	w.entries = append(w.entries, *entry)
+	return nil
+}
+
+func (w *recordingWriter) End(closingBalance int64) error {
+	w.closing = closingBalance
+	return nil
+}
+
+func TestStatementServiceExport(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	at := func(minutes int) time.Time { return from.Add(time.Duration(minutes) * time.Minute) }
+
+	// Текущий баланс 700: после from пришло +100 переводами и -300 покупками, значит входящий остаток 900
+	users := &snapshotUserRepo{fakeUserRepo{users: map[int64]*domain.User{1: {ID: 1, Balance: 700}}}}
+	transactions := &fakeTransactionRepo{
+		change: 100,
+		entries: []*domain.StatementEntry{
+			{CreatedAt: at(1), Type: domain.StatementEntryTransferIn, ReferenceID: 1, Amount: 150},
+			{CreatedAt: at(3), Type: domain.StatementEntryTransferOut, ReferenceID: 2, Amount: -50},
+		},
+	}
+	purchases := &fakePurchaseRepo{
+		change: -300,
+		entries: []*domain.StatementEntry{
+			{CreatedAt: at(2), Type: domain.StatementEntryPurchase, ReferenceID: 1, Amount: -300},
+			{CreatedAt: at(3), Type: domain.StatementEntryPurchase, ReferenceID: 2, Amount: -20},
+		},
+	}
+	snapshot := &fakeSnapshotter{}
+	s := NewStatementService(snapshot, users, transactions, purchases)
+
+	w := &recordingWriter{}
+	if err := s.Export(context.Background(), 1, from, to, w); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if snapshot.calls != 1 {
+		t.Errorf("snapshots opened = %d, want 1", snapshot.calls)
+	}
+	if w.opening != 900 {
+		t.Errorf("opening balance = %d, want 900", w.opening)
+	}
+
+	// При равном времени перевод идет раньше покупки
+	want := []struct {
+		amount, balance int64
+	}{{150, 1050}, {-300, 750}, {-50, 700}, {-20, 680}}
+	if len(w.entries) != len(want) {
+		t.Fatalf("entries = %d, want %d", len(w.entries), len(want))
+	}
+	for i, entry := range w.entries {
+		if entry.Amount != want[i].amount || entry.Balance != want[i].balance {
+			t.Errorf("entry %d = (%d, %d), want (%d, %d)", i, entry.Amount, entry.Balance, want[i].amount, want[i].balance)
+		}
+	}
+	if w.closing != 680 {
+		t.Errorf("closing balance = %d, want 680", w.closing)
+	}
+}
+
+func TestStatementServiceExportErrors(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dbErr := errors.New("connection reset")
+
+	tests := []struct {
+		name     string
+		userID   int64
+		userErr  error
+		from, to time.Time
+		wantErr  error
+	}{
+		{name: "unknown user", userID: 2, wantErr: domain.ErrUserNotFound},
+		{name: "database error", userID: 1, userErr: dbErr, wantErr: dbErr},
+		{name: "empty period", userID: 1, from: from, to: from, wantErr: domain.ErrInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &snapshotUserRepo{fakeUserRepo{users: map[int64]*domain.User{1: {ID: 1}}, err: tt.userErr}}
+			s := NewStatementService(&fakeSnapshotter{}, users, &fakeTransactionRepo{}, &fakePurchaseRepo{})
+
+			err := s.Export(context.Background(), tt.userID, tt.from, tt.to, &recordingWriter{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Export() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
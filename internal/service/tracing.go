@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/avito/internal/service")
+
+// startSpan начинает спан метода сервиса. Запросы к базе внутри метода
+// становятся его дочерними спанами, поэтому в трассе видно, какой шаг медленный.
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name)
+}
@@ -2,44 +2,68 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
 
 	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
 )
 
 type TransactionService struct {
 	transactionRepo domain.TransactionRepository
 	userRepo        domain.UserRepository
+	wishlist        domain.WishlistService
+	notifications   domain.NotificationService
+	events          domain.EventBus
+	logger          *slog.Logger
 }
 
 func NewTransactionService(
 	transactionRepo domain.TransactionRepository,
 	userRepo domain.UserRepository,
+	wishlist domain.WishlistService,
+	notifications domain.NotificationService,
+	events domain.EventBus,
+	logger *slog.Logger,
 ) *TransactionService {
 	return &TransactionService{
 		transactionRepo: transactionRepo,
 		userRepo:        userRepo,
+		wishlist:        wishlist,
+		notifications:   notifications,
+		events:          events,
+		logger:          logger,
 	}
 }
 
-func (s *TransactionService) Transfer(ctx context.Context, fromUserID, toUserID int64, amount int64, description string) error {
+func (s *TransactionService) Transfer(ctx context.Context, fromUserID, toUserID int64, amount int64, description string, kudos domain.KudosOptions) (*domain.Transaction, error) {
+	ctx, span := startSpan(ctx, "TransactionService.Transfer")
+	defer span.End()
+
 	if amount <= 0 {
-		return domain.ErrInvalidAmount
+		return nil, domain.ErrInvalidAmount
+	}
+	if kudos.Category != "" && !kudos.Category.IsValid() {
+		return nil, domain.ErrInvalidKudos
 	}
 
 	// Проверяем существование пользователей
 	fromUser, err := s.userRepo.GetByID(ctx, fromUserID)
 	if err != nil {
-		return domain.ErrUserNotFound
+		return nil, domain.ErrUserNotFound
 	}
 
-	_, err = s.userRepo.GetByID(ctx, toUserID)
-	if err != nil {
-		return domain.ErrUserNotFound
+	// Системные счета не принимают переводы от пользователей
+	toUser, err := s.userRepo.GetByID(ctx, toUserID)
+	if err != nil || toUser.IsSystem {
+		return nil, domain.ErrUserNotFound
 	}
 
 	// Проверяем достаточность средств
 	if fromUser.Balance < amount {
-		return domain.ErrInsufficientFunds
+		return nil, domain.ErrInsufficientFunds
 	}
 
 	// Создаем транзакцию
@@ -48,30 +72,174 @@ func (s *TransactionService) Transfer(ctx context.Context, fromUserID, toUserID
 		ToUserID:    toUserID,
 		Amount:      amount,
 		Description: &description,
+		IsPublic:    kudos.Public,
+	}
+	if kudos.Category != "" {
+		transaction.Category = &kudos.Category
 	}
 
-	// Выполняем перевод денег и создаем запись о транзакции
-	err = s.transactionRepo.TransferMoney(ctx, fromUserID, toUserID, amount)
+	// Выполняем перевод денег и создаем запись о транзакции в одной транзакции БД
+	err = s.transactionRepo.TransferMoney(ctx, transaction)
 	if err != nil {
-		return domain.ErrTransactionFailed
+		// Баланс мог уменьшиться между проверкой и списанием
+		if errors.Is(err, domain.ErrInsufficientFunds) {
+			return nil, domain.ErrInsufficientFunds
+		}
+		return nil, domain.ErrTransactionFailed
 	}
 
-	err = s.transactionRepo.Create(ctx, transaction)
-	if err != nil {
-		// В случае ошибки пытаемся откатить перевод
-		_ = s.transactionRepo.TransferMoney(ctx, toUserID, fromUserID, amount)
-		return domain.ErrTransactionFailed
+	s.notifyRecipient(ctx, fromUser, transaction)
+	s.publishTransfer(ctx, fromUser, transaction)
+
+	return transaction, nil
+}
+
+func (s *TransactionService) Adjust(ctx context.Context, adminID int64, adjustment domain.BalanceAdjustment) (*domain.Transaction, error) {
+	ctx, span := startSpan(ctx, "TransactionService.Adjust")
+	defer span.End()
+
+	if adjustment.Amount <= 0 {
+		return nil, domain.ErrInvalidAmount
+	}
+	comment := strings.TrimSpace(adjustment.Comment)
+	if comment == "" || !adjustment.ReasonCode.IsValid() {
+		return nil, domain.ErrInvalidAdjustment
+	}
+
+	user, err := s.userRepo.GetByID(ctx, adjustment.UserID)
+	if err != nil || user.IsSystem {
+		return nil, domain.ErrUserNotFound
+	}
+
+	treasury, err := s.userRepo.GetByUsername(ctx, domain.TreasuryUsername)
+	if err != nil || !treasury.IsSystem {
+		return nil, fmt.Errorf("treasury account not found: %w", err)
+	}
+
+	reason := adjustment.ReasonCode
+	transaction := &domain.Transaction{
+		Amount:      adjustment.Amount,
+		Description: &comment,
+		Kind:        domain.TransactionKindAdjustment,
+		ReasonCode:  &reason,
+		CreatedBy:   &adminID,
+	}
+
+	// Контрагентом корректировки всегда выступает казначейство, поэтому сумма балансов не меняется
+	switch adjustment.Direction {
+	case domain.AdjustmentCredit:
+		transaction.FromUserID, transaction.ToUserID = treasury.ID, user.ID
+	case domain.AdjustmentDebit:
+		if user.Balance < adjustment.Amount {
+			return nil, domain.ErrInsufficientFunds
+		}
+		transaction.FromUserID, transaction.ToUserID = user.ID, treasury.ID
+	default:
+		return nil, domain.ErrInvalidAdjustment
+	}
+
+	if err := s.transactionRepo.TransferMoney(ctx, transaction); err != nil {
+		if errors.Is(err, domain.ErrInsufficientFunds) {
+			return nil, domain.ErrInsufficientFunds
+		}
+		return nil, domain.ErrTransactionFailed
+	}
+
+	transaction.FromUsername, transaction.ToUsername = treasury.Username, user.Username
+	if adjustment.Direction == domain.AdjustmentDebit {
+		transaction.FromUsername, transaction.ToUsername = user.Username, treasury.Username
+	}
+
+	s.notifyAdjustment(ctx, user.ID, adjustment.Direction, transaction)
+	if adjustment.Direction == domain.AdjustmentCredit {
+		s.notifyAffordable(ctx, transaction)
 	}
+	publishBalance(ctx, s.logger, s.events, s.userRepo, user.ID)
 
-	return nil
+	return transaction, nil
 }
 
-func (s *TransactionService) GetUserTransactions(ctx context.Context, userID int64) ([]*domain.Transaction, error) {
+// notifyRecipient сообщает получателю о поступлении монет и о ставших доступными товарах из списка желаний.
+// Перевод уже выполнен, поэтому ошибки только логируются
+func (s *TransactionService) notifyRecipient(ctx context.Context, sender *domain.User, transaction *domain.Transaction) {
+	userID := transaction.ToUserID
+
+	message := fmt.Sprintf("%s перевел вам %d монет", sender.Username, transaction.Amount)
+	payload := map[string]any{
+		"transaction_id": transaction.ID,
+		"from_user_id":   sender.ID,
+		"from_username":  sender.Username,
+		"amount":         transaction.Amount,
+	}
+	if err := s.notifications.Notify(ctx, userID, domain.NotificationCoinsReceived, message, payload); err != nil {
+		s.logger.WarnContext(ctx, "failed to notify transfer recipient", "user_id", userID, "transaction_id", transaction.ID, "error", err)
+	}
+
+	s.notifyAffordable(ctx, transaction)
+}
+
+// notifyAdjustment сообщает пользователю о начислении или списании администратором с причиной и комментарием
+func (s *TransactionService) notifyAdjustment(ctx context.Context, userID int64, direction domain.AdjustmentDirection, transaction *domain.Transaction) {
+	message := fmt.Sprintf("Вам начислено %d монет: %s", transaction.Amount, *transaction.Description)
+	if direction == domain.AdjustmentDebit {
+		message = fmt.Sprintf("С вашего баланса списано %d монет: %s", transaction.Amount, *transaction.Description)
+	}
+	payload := map[string]any{
+		"transaction_id": transaction.ID,
+		"direction":      direction,
+		"amount":         transaction.Amount,
+		"reason_code":    transaction.ReasonCode,
+		"comment":        transaction.Description,
+	}
+	if err := s.notifications.Notify(ctx, userID, domain.NotificationBalanceAdjusted, message, payload); err != nil {
+		s.logger.WarnContext(ctx, "failed to notify user about adjustment", "user_id", userID, "transaction_id", transaction.ID, "error", err)
+	}
+}
+
+// notifyAffordable оповещает получателя о товарах из списка желаний, на которые хватило зачисленных монет
+func (s *TransactionService) notifyAffordable(ctx context.Context, transaction *domain.Transaction) {
+	userID, balance := transaction.ToUserID, transaction.RecipientBalance
+	if err := s.wishlist.NotifyAffordable(ctx, userID, balance-transaction.Amount, balance); err != nil {
+		s.logger.WarnContext(ctx, "failed to send wishlist notifications", "user_id", userID, "error", err)
+	}
+}
+
+// publishTransfer сообщает подключенным клиентам обеих сторон о новых балансах,
+// а получателю дополнительно о самом переводе
+func (s *TransactionService) publishTransfer(ctx context.Context, sender *domain.User, transaction *domain.Transaction) {
+	publishEvent(ctx, s.logger, s.events, transaction.ToUserID, domain.EventTransferReceived, map[string]any{
+		"transaction_id": transaction.ID,
+		"from_user_id":   sender.ID,
+		"from_username":  sender.Username,
+		"amount":         transaction.Amount,
+		"description":    transaction.Description,
+	})
+
+	publishBalance(ctx, s.logger, s.events, s.userRepo, transaction.FromUserID)
+	publishBalance(ctx, s.logger, s.events, s.userRepo, transaction.ToUserID)
+}
+
+func (s *TransactionService) GetUserTransactions(ctx context.Context, userID int64, filter domain.TransactionFilter, params pagination.Params) (*pagination.Page[*domain.Transaction], error) {
+	ctx, span := startSpan(ctx, "TransactionService.GetUserTransactions")
+	defer span.End()
+
+	switch filter.Direction {
+	case domain.TransactionDirectionAll, domain.TransactionDirectionSent, domain.TransactionDirectionReceived:
+	default:
+		return nil, domain.ErrInvalidFilter
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil && *filter.MinAmount > *filter.MaxAmount {
+		return nil, domain.ErrInvalidFilter
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, domain.ErrInvalidFilter
+	}
+
 	// Проверяем существование пользователя
 	_, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, domain.ErrUserNotFound
 	}
 
-	return s.transactionRepo.GetByUserID(ctx, userID)
+	return s.transactionRepo.GetByUserID(ctx, userID, filter, params)
 }
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+type fakeUserRepo struct {
+	domain.UserRepository
+	users map[int64]*domain.User
+	err   error
+}
+
+func (r *fakeUserRepo) GetByID(_ context.Context, id int64) (*domain.User, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+func (r *fakeUserRepo) GetByUsername(_ context.Context, username string) (*domain.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, domain.ErrUserNotFound
+}
+
+type fakeTransactionRepo struct {
+	domain.TransactionRepository
+	filter domain.TransactionFilter
+	params pagination.Params
+	calls  int
+
+	// Данные выписки
+	entries []*domain.StatementEntry
+	change  int64
+
+	// Переводы
+	balances  map[int64]int64
+	transfers int
+}
+
+func (r *fakeTransactionRepo) TransferMoney(_ context.Context, transaction *domain.Transaction) error {
+	r.transfers++
+	r.balances[transaction.FromUserID] -= transaction.Amount
+	r.balances[transaction.ToUserID] += transaction.Amount
+	transaction.SenderBalance = r.balances[transaction.FromUserID]
+	transaction.RecipientBalance = r.balances[transaction.ToUserID]
+	return nil
+}
+
+func (r *fakeTransactionRepo) StatementEntries(ctx context.Context, _ int64, _, _ time.Time) (domain.StatementIterator, error) {
+	return newSliceIterator(ctx, r.entries)
+}
+
+func (r *fakeTransactionRepo) NetChangeSince(ctx context.Context, _ int64, _ time.Time) (int64, error) {
+	return r.change, requireSnapshot(ctx)
+}
+
+func (r *fakeTransactionRepo) GetByUserID(_ context.Context, _ int64, filter domain.TransactionFilter, params pagination.Params) (*pagination.Page[*domain.Transaction], error) {
+	r.calls++
+	r.filter, r.params = filter, params
+	return &pagination.Page[*domain.Transaction]{Items: []*domain.Transaction{}}, nil
+}
+
+func TestTransactionServiceGetUserTransactions(t *testing.T) {
+	amount := func(v int64) *int64 { return &v }
+	at := func(s string) *time.Time {
+		tm, _ := time.Parse(time.DateOnly, s)
+		return &tm
+	}
+
+	tests := []struct {
+		name    string
+		userID  int64
+		filter  domain.TransactionFilter
+		wantErr error
+	}{
+		{name: "no filter", userID: 1},
+		{name: "sent with amount range", userID: 1, filter: domain.TransactionFilter{
+			Direction: domain.TransactionDirectionSent, MinAmount: amount(10), MaxAmount: amount(10),
+		}},
+		{name: "unknown direction", userID: 1, filter: domain.TransactionFilter{Direction: "both"}, wantErr: domain.ErrInvalidFilter},
+		{name: "inverted amount range", userID: 1, filter: domain.TransactionFilter{MinAmount: amount(20), MaxAmount: amount(10)}, wantErr: domain.ErrInvalidFilter},
+		{name: "empty period", userID: 1, filter: domain.TransactionFilter{From: at("2024-02-01"), To: at("2024-02-01")}, wantErr: domain.ErrInvalidFilter},
+		{name: "unknown user", userID: 2, wantErr: domain.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions := &fakeTransactionRepo{}
+			users := &fakeUserRepo{users: map[int64]*domain.User{1: {ID: 1, Username: "alice"}}}
+			s := NewTransactionService(transactions, users, &fakeWishlistService{}, &nopNotifications{}, nopEvents{}, discardLogger)
+
+			params := pagination.Params{Limit: 5, Cursor: "next"}
+			_, err := s.GetUserTransactions(context.Background(), tt.userID, tt.filter, params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetUserTransactions() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				if transactions.calls != 0 {
+					t.Errorf("repository called %d times for invalid request", transactions.calls)
+				}
+				return
+			}
+			if transactions.calls != 1 || transactions.params != params || transactions.filter.Direction != tt.filter.Direction {
+				t.Errorf("repository called %d times with %+v, %+v", transactions.calls, transactions.filter, transactions.params)
+			}
+		})
+	}
+}
+
+func TestTransactionServiceTransferNotifiesAffordable(t *testing.T) {
+	users := &fakeUserRepo{users: map[int64]*domain.User{
+		1: {ID: 1, Username: "alice", Balance: 500},
+		2: {ID: 2, Username: "bob", Balance: 100},
+	}}
+	// Пока перевод проверялся, на счет получателя пришло еще 50 монет
+	transactions := &fakeTransactionRepo{balances: map[int64]int64{1: 500, 2: 150}}
+	wishlist := &fakeWishlistService{}
+	s := NewTransactionService(transactions, users, wishlist, &nopNotifications{}, nopEvents{}, discardLogger)
+
+	if _, err := s.Transfer(context.Background(), 1, 2, 200, "спасибо", domain.KudosOptions{}); err != nil {
+		t.Fatalf("Transfer() error = %v", err)
+	}
+
+	// Интервал строится от баланса, возвращенного переводом, а не от прочитанного до него
+	want := []affordableCall{{userID: 2, oldBalance: 150, newBalance: 350}}
+	if len(wishlist.affordable) != 1 || wishlist.affordable[0] != want[0] {
+		t.Errorf("NotifyAffordable calls = %+v, want %+v", wishlist.affordable, want)
+	}
+}
+
+func TestTransactionServiceAdjustNotifies(t *testing.T) {
+	tests := []struct {
+		name           string
+		direction      domain.AdjustmentDirection
+		wantAffordable []affordableCall
+	}{
+		{
+			name:           "credit",
+			direction:      domain.AdjustmentCredit,
+			wantAffordable: []affordableCall{{userID: 2, oldBalance: 500, newBalance: 700}},
+		},
+		{
+			name:      "debit",
+			direction: domain.AdjustmentDebit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserRepo{users: map[int64]*domain.User{
+				1: {ID: 1, Username: domain.TreasuryUsername, IsSystem: true},
+				2: {ID: 2, Username: "bob", Balance: 500},
+			}}
+			transactions := &fakeTransactionRepo{balances: map[int64]int64{1: 0, 2: 500}}
+			wishlist := &fakeWishlistService{}
+			notifications := &nopNotifications{}
+			s := NewTransactionService(transactions, users, wishlist, notifications, nopEvents{}, discardLogger)
+
+			_, err := s.Adjust(context.Background(), 3, domain.BalanceAdjustment{
+				UserID:     2,
+				Direction:  tt.direction,
+				Amount:     200,
+				ReasonCode: domain.AdjustmentReasonBonus,
+				Comment:    "премия за квартал",
+			})
+			if err != nil {
+				t.Fatalf("Adjust() error = %v", err)
+			}
+
+			// Пользователь получает уведомление о корректировке, а не о переводе от казначейства
+			if len(notifications.kinds) != 1 || notifications.kinds[0] != domain.NotificationBalanceAdjusted {
+				t.Errorf("notifications = %v, want [%s]", notifications.kinds, domain.NotificationBalanceAdjusted)
+			}
+			// Список желаний проверяется только после начисления
+			if len(wishlist.affordable) != len(tt.wantAffordable) ||
+				(len(tt.wantAffordable) > 0 && wishlist.affordable[0] != tt.wantAffordable[0]) {
+				t.Errorf("NotifyAffordable calls = %+v, want %+v", wishlist.affordable, tt.wantAffordable)
+			}
+		})
+	}
+}
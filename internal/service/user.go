@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,6 +11,9 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// signupGrant - стартовый баланс, который казначейство выдает новому пользователю
+const signupGrant = 1000
+
 type UserService struct {
 	repo        domain.UserRepository
 	tokenSecret string
@@ -23,6 +27,9 @@ func NewUserService(repo domain.UserRepository, tokenSecret string) *UserService
 }
 
 func (s *UserService) Register(ctx context.Context, username, password string) (*domain.User, error) {
+	ctx, span := startSpan(ctx, "UserService.Register")
+	defer span.End()
+
 	// Проверяем, не существует ли уже пользователь
 	existingUser, err := s.repo.GetByUsername(ctx, username)
 	if err == nil && existingUser != nil {
@@ -38,7 +45,7 @@ func (s *UserService) Register(ctx context.Context, username, password string) (
 	user := &domain.User{
 		Username: username,
 		Password: string(hashedPassword),
-		Balance:  1000, // Начальный баланс
+		Balance:  signupGrant,
 	}
 
 	if err := s.repo.Create(ctx, user); err != nil {
@@ -49,8 +56,11 @@ func (s *UserService) Register(ctx context.Context, username, password string) (
 }
 
 func (s *UserService) Login(ctx context.Context, username, password string) (string, error) {
+	ctx, span := startSpan(ctx, "UserService.Login")
+	defer span.End()
+
 	user, err := s.repo.GetByUsername(ctx, username)
-	if err != nil {
+	if err != nil || user.IsSystem {
 		return "", domain.ErrInvalidCredentials
 	}
 
@@ -63,6 +73,7 @@ func (s *UserService) Login(ctx context.Context, username, password string) (str
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id":  user.ID,
 		"username": user.Username,
+		"is_admin": user.IsAdmin,
 		"exp":      time.Now().Add(24 * time.Hour).Unix(),
 	})
 
@@ -75,14 +86,23 @@ func (s *UserService) Login(ctx context.Context, username, password string) (str
 }
 
 func (s *UserService) GetByID(ctx context.Context, id int64) (*domain.User, error) {
+	ctx, span := startSpan(ctx, "UserService.GetByID")
+	defer span.End()
+
 	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return nil, domain.ErrUserNotFound
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
 	}
 	return user, nil
 }
 
 func (s *UserService) GetBalance(ctx context.Context, userID int64) (int64, error) {
+	ctx, span := startSpan(ctx, "UserService.GetBalance")
+	defer span.End()
+
 	user, err := s.repo.GetByID(ctx, userID)
 	if err != nil {
 		return 0, domain.ErrUserNotFound
@@ -91,10 +111,16 @@ func (s *UserService) GetBalance(ctx context.Context, userID int64) (int64, erro
 }
 
 func (s *UserService) Auth(ctx context.Context, username, password string) (string, error) {
+	ctx, span := startSpan(ctx, "UserService.Auth")
+	defer span.End()
+
 	// Пробуем найти пользователя
 	existingUser, err := s.repo.GetByUsername(ctx, username)
 	if err == nil && existingUser != nil {
-		// Пользователь существует, проверяем пароль
+		// Пользователь существует, проверяем пароль. Под системными счетами войти нельзя.
+		if existingUser.IsSystem {
+			return "", domain.ErrInvalidCredentials
+		}
 		if err := bcrypt.CompareHashAndPassword([]byte(existingUser.Password), []byte(password)); err != nil {
 			return "", domain.ErrInvalidCredentials
 		}
@@ -108,7 +134,7 @@ func (s *UserService) Auth(ctx context.Context, username, password string) (stri
 		existingUser = &domain.User{
 			Username: username,
 			Password: string(hashedPassword),
-			Balance:  1000, // Начальный баланс
+			Balance:  signupGrant,
 		}
 
 		if err := s.repo.Create(ctx, existingUser); err != nil {
@@ -120,6 +146,7 @@ func (s *UserService) Auth(ctx context.Context, username, password string) (stri
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id":  existingUser.ID,
 		"username": existingUser.Username,
+		"is_admin": existingUser.IsAdmin,
 		"exp":      time.Now().Add(24 * time.Hour).Unix(),
 	})
 
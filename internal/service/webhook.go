@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/avito/internal/domain"
+	"github.com/avito/pkg/pagination"
+)
+
+// webhookEventTypes - события outbox, на которые можно подписаться
+var webhookEventTypes = map[string]bool{
+	domain.WebhookAllEvents:             true,
+	domain.OutboxEventTransferCompleted: true,
+	domain.OutboxEventPurchaseCreated:   true,
+	domain.OutboxEventOrderCancelled:    true,
+}
+
+type WebhookService struct {
+	webhookRepo domain.WebhookRepository
+}
+
+func NewWebhookService(webhookRepo domain.WebhookRepository) *WebhookService {
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+	}
+}
+
+func (s *WebhookService) Create(ctx context.Context, rawURL string, eventTypes []string, secret string) (*domain.WebhookSubscription, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Create")
+	defer span.End()
+
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, domain.ErrInvalidWebhook
+	}
+
+	if len(eventTypes) == 0 {
+		return nil, domain.ErrInvalidWebhook
+	}
+	for _, eventType := range eventTypes {
+		if !webhookEventTypes[eventType] {
+			return nil, domain.ErrInvalidWebhook
+		}
+	}
+
+	// Если секрет не задан, генерируем его; он показывается только в ответе на создание
+	if secret == "" {
+		secret, err = randomName()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+	}
+
+	subscription := &domain.WebhookSubscription{
+		URL:        target.String(),
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     true,
+	}
+	if err := s.webhookRepo.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	ctx, span := startSpan(ctx, "WebhookService.List")
+	defer span.End()
+
+	subscriptions, err := s.webhookRepo.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+
+	return subscriptions, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "WebhookService.Delete")
+	defer span.End()
+
+	return s.webhookRepo.DeleteSubscription(ctx, id)
+}
+
+func (s *WebhookService) Deliveries(ctx context.Context, subscriptionID int64, status domain.WebhookDeliveryStatus, params pagination.Params) (*pagination.Page[*domain.WebhookDelivery], error) {
+	ctx, span := startSpan(ctx, "WebhookService.Deliveries")
+	defer span.End()
+
+	switch status {
+	case "", domain.WebhookDeliveryPending, domain.WebhookDeliveryDelivered, domain.WebhookDeliveryDead:
+	default:
+		return nil, domain.ErrInvalidFilter
+	}
+
+	if _, err := s.webhookRepo.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	return s.webhookRepo.GetDeliveries(ctx, subscriptionID, status, params)
+}
+
+func (s *WebhookService) Redeliver(ctx context.Context, deliveryID int64) (*domain.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "WebhookService.Redeliver")
+	defer span.End()
+
+	return s.webhookRepo.Redeliver(ctx, deliveryID)
+}
+
+func (s *WebhookService) Publish(ctx context.Context, event *domain.OutboxEvent) error {
+	ctx, span := startSpan(ctx, "WebhookService.Publish")
+	defer span.End()
+
+	return s.webhookRepo.EnqueueDeliveries(ctx, event)
+}
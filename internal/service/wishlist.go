@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/avito/internal/domain"
+)
+
+type WishlistService struct {
+	wishlistRepo  domain.WishlistRepository
+	merchRepo     domain.MerchRepository
+	variantRepo   domain.MerchVariantRepository
+	notifications domain.NotificationService
+	logger        *slog.Logger
+}
+
+func NewWishlistService(
+	wishlistRepo domain.WishlistRepository,
+	merchRepo domain.MerchRepository,
+	variantRepo domain.MerchVariantRepository,
+	notifications domain.NotificationService,
+	logger *slog.Logger,
+) *WishlistService {
+	return &WishlistService{
+		wishlistRepo:  wishlistRepo,
+		merchRepo:     merchRepo,
+		variantRepo:   variantRepo,
+		notifications: notifications,
+		logger:        logger,
+	}
+}
+
+func (s *WishlistService) List(ctx context.Context, userID int64) ([]*domain.WishlistItem, error) {
+	ctx, span := startSpan(ctx, "WishlistService.List")
+	defer span.End()
+
+	items, err := s.wishlistRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*domain.WishlistItem{}
+	}
+
+	return items, nil
+}
+
+func (s *WishlistService) Add(ctx context.Context, userID, merchID int64, variantID *int64) error {
+	ctx, span := startSpan(ctx, "WishlistService.Add")
+	defer span.End()
+
+	merch, err := s.merchRepo.GetByID(ctx, merchID)
+	if err != nil {
+		return domain.ErrMerchNotFound
+	}
+
+	// В отличие от корзины вариант необязателен: можно ждать любой размер товара
+	if variantID != nil {
+		if _, err := resolveVariant(ctx, s.variantRepo, merch, variantID); err != nil {
+			return err
+		}
+	}
+
+	return s.wishlistRepo.Add(ctx, userID, merchID, variantID)
+}
+
+func (s *WishlistService) Remove(ctx context.Context, userID, merchID int64, variantID *int64) error {
+	ctx, span := startSpan(ctx, "WishlistService.Remove")
+	defer span.End()
+
+	return s.wishlistRepo.Remove(ctx, userID, merchID, variantID)
+}
+
+func (s *WishlistService) NotifyAffordable(ctx context.Context, userID, oldBalance, newBalance int64) error {
+	ctx, span := startSpan(ctx, "WishlistService.NotifyAffordable")
+	defer span.End()
+
+	if newBalance <= oldBalance {
+		return nil
+	}
+
+	// Уведомляем только о товарах, порог которых пересек именно этот перевод,
+	// поэтому повторные пополнения не присылают одно и то же уведомление
+	items, err := s.wishlistRepo.GetPricedBetween(ctx, userID, oldBalance, newBalance)
+	if err != nil {
+		return err
+	}
+
+	// Сбой одного уведомления не должен лишать остальных
+	for _, item := range items {
+		message := fmt.Sprintf("Теперь вам хватает монет на «%s» за %d", item.MerchName, item.Price)
+		if err := s.notifications.Notify(ctx, userID, domain.NotificationWishlistAffordable, message, wishlistPayload(item)); err != nil {
+			s.logger.WarnContext(ctx, "failed to send wishlist notification",
+				"wishlist_item_id", item.ID, "user_id", userID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *WishlistService) NotifyRestocked(ctx context.Context, variantIDs []int64) error {
+	ctx, span := startSpan(ctx, "WishlistService.NotifyRestocked")
+	defer span.End()
+
+	if len(variantIDs) == 0 {
+		return nil
+	}
+
+	items, err := s.wishlistRepo.GetRestocked(ctx, variantIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		message := fmt.Sprintf("«%s» снова в наличии", item.MerchName)
+		if item.VariantSKU != nil {
+			message = fmt.Sprintf("«%s» (%s) снова в наличии", item.MerchName, *item.VariantSKU)
+		}
+
+		if err := s.notifications.Notify(ctx, item.UserID, domain.NotificationWishlistRestocked, message, wishlistPayload(item)); err != nil {
+			s.logger.WarnContext(ctx, "failed to send wishlist notification",
+				"wishlist_item_id", item.ID, "user_id", item.UserID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func wishlistPayload(item *domain.WishlistItem) map[string]any {
+	payload := map[string]any{
+		"merch_id": item.MerchID,
+		"price":    item.Price,
+	}
+	if item.VariantID != nil {
+		payload["variant_id"] = *item.VariantID
+	}
+	return payload
+}
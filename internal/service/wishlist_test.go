@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/avito/internal/domain"
+)
+
+type fakeWishlistRepo struct {
+	domain.WishlistRepository
+	items []*domain.WishlistItem
+
+	pricedBetween [2]int64
+	restocked     []int64
+	calls         int
+}
+
+func (r *fakeWishlistRepo) GetPricedBetween(_ context.Context, _, minExclusive, maxInclusive int64) ([]*domain.WishlistItem, error) {
+	r.calls++
+	r.pricedBetween = [2]int64{minExclusive, maxInclusive}
+	return r.items, nil
+}
+
+func (r *fakeWishlistRepo) GetRestocked(_ context.Context, variantIDs []int64) ([]*domain.WishlistItem, error) {
+	r.calls++
+	r.restocked = variantIDs
+	return r.items, nil
+}
+
+// fakeNotifications не доставляет уведомления о товарах из failFor
+type fakeNotifications struct {
+	domain.NotificationService
+	failFor map[int64]bool
+	sent    []int64
+}
+
+func (n *fakeNotifications) Notify(_ context.Context, _ int64, _ domain.NotificationType, _ string, payload map[string]any) error {
+	merchID := payload["merch_id"].(int64)
+	if n.failFor[merchID] {
+		return errors.New("notification store is unavailable")
+	}
+	n.sent = append(n.sent, merchID)
+	return nil
+}
+
+// nopNotifications принимает любые уведомления и запоминает их типы
+type nopNotifications struct {
+	domain.NotificationService
+	kinds []domain.NotificationType
+}
+
+func (n *nopNotifications) Notify(_ context.Context, _ int64, kind domain.NotificationType, _ string, _ map[string]any) error {
+	n.kinds = append(n.kinds, kind)
+	return nil
+}
+
+// discardLogger отбрасывает записи логов
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// nopEvents отбрасывает события реального времени
+type nopEvents struct {
+	domain.EventBus
+}
+
+func (nopEvents) Publish(context.Context, *domain.Event) error {
+	return nil
+}
+
+type affordableCall struct {
+	userID, oldBalance, newBalance int64
+}
+
+// fakeWishlistService записывает вызовы уведомлений из других сервисов
+type fakeWishlistService struct {
+	domain.WishlistService
+	affordable []affordableCall
+	restocked  [][]int64
+}
+
+func (s *fakeWishlistService) NotifyAffordable(_ context.Context, userID, oldBalance, newBalance int64) error {
+	s.affordable = append(s.affordable, affordableCall{userID, oldBalance, newBalance})
+	return nil
+}
+
+func (s *fakeWishlistService) NotifyRestocked(_ context.Context, variantIDs []int64) error {
+	s.restocked = append(s.restocked, variantIDs)
+	return nil
+}
+
+func wishlistItems(merchIDs ...int64) []*domain.WishlistItem {
+	items := make([]*domain.WishlistItem, 0, len(merchIDs))
+	for _, id := range merchIDs {
+		items = append(items, &domain.WishlistItem{ID: id, UserID: 1, MerchID: id, MerchName: "merch", Price: 100})
+	}
+	return items
+}
+
+func TestWishlistServiceNotifyAffordable(t *testing.T) {
+	tests := []struct {
+		name       string
+		oldBalance int64
+		newBalance int64
+		failFor    map[int64]bool
+		wantQuery  bool
+		wantSent   []int64
+	}{
+		{name: "balance grew", oldBalance: 50, newBalance: 150, wantQuery: true, wantSent: []int64{1, 2, 3}},
+		{name: "balance unchanged", oldBalance: 150, newBalance: 150},
+		{name: "balance decreased", oldBalance: 150, newBalance: 50},
+		{name: "failed notification does not stop others", oldBalance: 50, newBalance: 150, failFor: map[int64]bool{2: true}, wantQuery: true, wantSent: []int64{1, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeWishlistRepo{items: wishlistItems(1, 2, 3)}
+			notifications := &fakeNotifications{failFor: tt.failFor}
+			s := NewWishlistService(repo, nil, nil, notifications, discardLogger)
+
+			if err := s.NotifyAffordable(context.Background(), 1, tt.oldBalance, tt.newBalance); err != nil {
+				t.Fatalf("NotifyAffordable() error = %v", err)
+			}
+
+			if (repo.calls == 1) != tt.wantQuery {
+				t.Fatalf("repository calls = %d, want query: %v", repo.calls, tt.wantQuery)
+			}
+			if tt.wantQuery && repo.pricedBetween != [2]int64{tt.oldBalance, tt.newBalance} {
+				t.Errorf("price interval = %v, want (%d, %d]", repo.pricedBetween, tt.oldBalance, tt.newBalance)
+			}
+			if !equalInt64s(notifications.sent, tt.wantSent) {
+				t.Errorf("notified merch = %v, want %v", notifications.sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestWishlistServiceNotifyRestocked(t *testing.T) {
+	tests := []struct {
+		name       string
+		variantIDs []int64
+		failFor    map[int64]bool
+		wantQuery  bool
+		wantSent   []int64
+	}{
+		{name: "restocked variants", variantIDs: []int64{10, 11}, wantQuery: true, wantSent: []int64{1, 2}},
+		{name: "nothing restocked"},
+		{name: "failed notification does not stop others", variantIDs: []int64{10}, failFor: map[int64]bool{1: true}, wantQuery: true, wantSent: []int64{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeWishlistRepo{items: wishlistItems(1, 2)}
+			notifications := &fakeNotifications{failFor: tt.failFor}
+			s := NewWishlistService(repo, nil, nil, notifications, discardLogger)
+
+			if err := s.NotifyRestocked(context.Background(), tt.variantIDs); err != nil {
+				t.Fatalf("NotifyRestocked() error = %v", err)
+			}
+
+			if (repo.calls == 1) != tt.wantQuery {
+				t.Fatalf("repository calls = %d, want query: %v", repo.calls, tt.wantQuery)
+			}
+			if tt.wantQuery && !equalInt64s(repo.restocked, tt.variantIDs) {
+				t.Errorf("restocked variants = %v, want %v", repo.restocked, tt.variantIDs)
+			}
+			if !equalInt64s(notifications.sent, tt.wantSent) {
+				t.Errorf("notified merch = %v, want %v", notifications.sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+type stockVariantRepo struct {
+	fakeVariantRepo
+}
+
+func (r *stockVariantRepo) SetStock(_ context.Context, id int64, stock int) (int, error) {
+	for _, variant := range r.variants {
+		if variant.ID == id {
+			previous := variant.Stock
+			variant.Stock = stock
+			return previous, nil
+		}
+	}
+	return 0, domain.ErrVariantNotFound
+}
+
+func TestMerchServiceSetVariantStockNotifiesRestocked(t *testing.T) {
+	tests := []struct {
+		name          string
+		previous      int
+		stock         int
+		wantErr       error
+		wantRestocked bool
+	}{
+		{name: "back in stock", previous: 0, stock: 5, wantRestocked: true},
+		{name: "stock increased", previous: 2, stock: 5},
+		{name: "sold out", previous: 2, stock: 0},
+		{name: "still out of stock", previous: 0, stock: 0},
+		{name: "negative stock", previous: 0, stock: -1, wantErr: domain.ErrInvalidQuantity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variants := &stockVariantRepo{fakeVariantRepo{variants: []*domain.MerchVariant{{ID: 10, MerchID: 1, Stock: tt.previous}}}}
+			wishlist := &fakeWishlistService{}
+			s := NewMerchService(&fakeMerchRepo{}, variants, nil, nil, nil, wishlist, &nopNotifications{}, nopEvents{}, 0, discardLogger)
+
+			_, err := s.SetVariantStock(context.Background(), 10, tt.stock)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SetVariantStock() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := len(wishlist.restocked) == 1; got != tt.wantRestocked {
+				t.Fatalf("restock notifications = %v, want sent: %v", wishlist.restocked, tt.wantRestocked)
+			}
+			if tt.wantRestocked && !equalInt64s(wishlist.restocked[0], []int64{10}) {
+				t.Errorf("restocked variants = %v, want [10]", wishlist.restocked[0])
+			}
+		})
+	}
+}
+
+type cancelOrderRepo struct {
+	domain.OrderRepository
+	restocked []int64
+}
+
+func (r *cancelOrderRepo) UpdateStatus(_ context.Context, id int64, status domain.OrderStatus) (*domain.Order, []int64, error) {
+	return &domain.Order{ID: id, Status: status}, r.restocked, nil
+}
+
+func TestOrderServiceCancelNotifiesRestocked(t *testing.T) {
+	wishlist := &fakeWishlistService{}
+	s := NewOrderService(&cancelOrderRepo{restocked: []int64{10, 12}}, &fakeUserRepo{}, &nopNotifications{}, wishlist, nopEvents{}, discardLogger)
+
+	if _, err := s.UpdateStatus(context.Background(), 1, domain.OrderStatusCancelled); err != nil {
+		t.Fatalf("UpdateStatus() error = %v", err)
+	}
+
+	if len(wishlist.restocked) != 1 || !equalInt64s(wishlist.restocked[0], []int64{10, 12}) {
+		t.Errorf("restock notifications = %v, want [[10 12]]", wishlist.restocked)
+	}
+}
+
+func equalInt64s(got, want []int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
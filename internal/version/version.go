@@ -0,0 +1,28 @@
+// Package version хранит сведения о сборке, которые задаются при компиляции:
+//
+//	go build -ldflags "-X github.com/avito/internal/version.Version=v1.2.0 \
+//	  -X github.com/avito/internal/version.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/avito/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package version
+
+import (
+	"runtime"
+
+	"github.com/avito/internal/domain"
+)
+
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info возвращает сведения о текущей сборке
+func Info() domain.BuildInfo {
+	return domain.BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
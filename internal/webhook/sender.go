@@ -0,0 +1,74 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+// SignatureHeader содержит HMAC-SHA256 тела запроса в виде "sha256=<hex>"
+const SignatureHeader = "X-Signature"
+
+// Sender отправляет доставки вебхуков HTTP-запросами
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender создает новый экземпляр Sender
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{client: &http.Client{Timeout: timeout}}
+}
+
+// Send отправляет событие подписчику. Любой ответ вне диапазона 2xx считается ошибкой,
+// при этом код ответа возвращается для журнала доставки.
+func (s *Sender) Send(ctx context.Context, dispatch *domain.WebhookDispatch) (int, error) {
+	body, err := json.Marshal(dispatch.Event)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal webhook body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dispatch.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(dispatch.Secret, body))
+	req.Header.Set("X-Event-ID", dispatch.Event.ID)
+	req.Header.Set("X-Event-Type", dispatch.Event.EventType)
+	req.Header.Set("X-Delivery-ID", strconv.FormatInt(dispatch.Delivery.ID, 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Sign вычисляет подпись тела запроса секретом подписки
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись, полученную в заголовке X-Signature
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
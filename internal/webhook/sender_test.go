@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+		body   string
+		want   string
+	}{
+		{
+			name:   "known vector",
+			secret: "key",
+			body:   "The quick brown fox jumps over the lazy dog",
+			want:   "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8",
+		},
+		{
+			name:   "empty secret and body",
+			secret: "",
+			body:   "",
+			want:   "sha256=b613679a0814d9ec772f95d778c35fc5ff1697c493715653c6c712144292c5ad",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const secret = "s3cr3t"
+	body := []byte(`{"event_type":"purchase.created","payload":{"purchase_id":1}}`)
+	signature := Sign(secret, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid", secret: secret, body: body, signature: signature, want: true},
+		{name: "wrong secret", secret: "other", body: body, signature: signature},
+		{name: "modified body", secret: secret, body: append([]byte(" "), body...), signature: signature},
+		{name: "missing prefix", secret: secret, body: body, signature: signature[len("sha256="):]},
+		{name: "uppercase hex", secret: secret, body: body, signature: "sha256=" + strings.ToUpper(signature[len("sha256="):])},
+		{name: "truncated", secret: secret, body: body, signature: signature[:len(signature)-1]},
+		{name: "empty", secret: secret, body: body, signature: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.body, tt.signature); got != tt.want {
+				t.Errorf("Verify() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
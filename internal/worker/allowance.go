@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+// AllowanceScheduler периодически проводит наступившие начисления и сгорание монет.
+// Повторные запуски за тот же период отсекаются записями о запусках в базе,
+// поэтому перезапуск приложения и несколько реплик не приводят к двойному начислению.
+type AllowanceScheduler struct {
+	service   domain.AllowanceService
+	interval  time.Duration
+	logger    *slog.Logger
+	heartbeat heartbeat
+}
+
+// NewAllowanceScheduler создает новый экземпляр AllowanceScheduler
+func NewAllowanceScheduler(service domain.AllowanceService, interval time.Duration, logger *slog.Logger) *AllowanceScheduler {
+	return &AllowanceScheduler{
+		service:  service,
+		interval: interval,
+		logger:   logger.With("worker", "allowance_scheduler"),
+	}
+}
+
+// Run проверяет расписания сразу при запуске и затем по таймеру до отмены контекста
+func (w *AllowanceScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.heartbeat.beat()
+		if err := w.service.RunDue(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			w.logger.ErrorContext(ctx, "failed to run allowance", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Health сообщает, не завис ли обработчик
+func (w *AllowanceScheduler) Health() error {
+	return w.heartbeat.check(2 * w.interval)
+}
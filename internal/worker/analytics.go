@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+// AnalyticsRefresher периодически обновляет витрины аналитики
+type AnalyticsRefresher struct {
+	service   domain.AnalyticsService
+	interval  time.Duration
+	logger    *slog.Logger
+	heartbeat heartbeat
+}
+
+// NewAnalyticsRefresher создает новый экземпляр AnalyticsRefresher
+func NewAnalyticsRefresher(service domain.AnalyticsService, interval time.Duration, logger *slog.Logger) *AnalyticsRefresher {
+	return &AnalyticsRefresher{
+		service:  service,
+		interval: interval,
+		logger:   logger.With("worker", "analytics_refresher"),
+	}
+}
+
+// Run обновляет витрины по таймеру до отмены контекста
+func (w *AnalyticsRefresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.heartbeat.beat()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := w.service.Refresh(ctx); err != nil && ctx.Err() == nil {
+			w.logger.ErrorContext(ctx, "failed to refresh analytics", "error", err)
+		}
+	}
+}
+
+// Health сообщает, не завис ли обработчик
+func (w *AnalyticsRefresher) Health() error {
+	return w.heartbeat.check(2 * w.interval)
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+// fakeAnalyticsService считает обновления витрин и возвращает err
+type fakeAnalyticsService struct {
+	domain.AnalyticsService
+	refreshes atomic.Int32
+	err       error
+}
+
+func (s *fakeAnalyticsService) Refresh(context.Context) error {
+	s.refreshes.Add(1)
+	return s.err
+}
+
+func TestAnalyticsRefresherRun(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "success"},
+		// Ошибка обновления не останавливает воркер
+		{name: "refresh error", err: errors.New("could not obtain lock on materialized view")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeAnalyticsService{err: tt.err}
+			w := NewAnalyticsRefresher(service, 10*time.Millisecond, discardLogger)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 55*time.Millisecond)
+			defer cancel()
+
+			done := make(chan struct{})
+			go func() {
+				w.Run(ctx)
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("Run did not return after context cancellation")
+			}
+
+			if got := service.refreshes.Load(); got < 2 {
+				t.Errorf("refreshes = %d, want at least 2", got)
+			}
+		})
+	}
+}
+
+func TestAnalyticsRefresherWaitsForFirstTick(t *testing.T) {
+	service := &fakeAnalyticsService{}
+	w := NewAnalyticsRefresher(service, time.Hour, discardLogger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	w.Run(ctx)
+
+	// Витрина заполняется миграцией, поэтому при старте обновление не нужно
+	if got := service.refreshes.Load(); got != 0 {
+		t.Errorf("refreshes = %d, want 0", got)
+	}
+}
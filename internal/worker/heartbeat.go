@@ -0,0 +1,29 @@
+package worker
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// heartbeat отмечает каждый проход цикла обработчика, чтобы проверка готовности
+// могла отличить работающий обработчик от зависшего или не запущенного
+type heartbeat struct {
+	last atomic.Int64
+}
+
+func (h *heartbeat) beat() {
+	h.last.Store(time.Now().UnixNano())
+}
+
+// check возвращает ошибку, если последний проход был раньше, чем maxAge назад
+func (h *heartbeat) check(maxAge time.Duration) error {
+	last := h.last.Load()
+	if last == 0 {
+		return fmt.Errorf("worker is not running")
+	}
+	if age := time.Since(time.Unix(0, last)); age > maxAge {
+		return fmt.Errorf("worker is stuck: last iteration %s ago", age.Round(time.Second))
+	}
+	return nil
+}
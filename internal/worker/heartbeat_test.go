@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHeartbeatCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		age     time.Duration
+		beat    bool
+		wantErr bool
+	}{
+		{name: "not running", wantErr: true},
+		{name: "fresh", beat: true, age: time.Second},
+		{name: "stuck", beat: true, age: time.Minute, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h heartbeat
+			if tt.beat {
+				h.last.Store(time.Now().Add(-tt.age).UnixNano())
+			}
+
+			if err := h.check(10 * time.Second); (err != nil) != tt.wantErr {
+				t.Errorf("check() error = %v, want error: %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAnalyticsRefresherHealth(t *testing.T) {
+	w := NewAnalyticsRefresher(&fakeAnalyticsService{}, 10*time.Millisecond, discardLogger)
+
+	// До запуска обработчик не готов
+	if err := w.Health(); err == nil {
+		t.Fatal("Health() before Run = nil, want error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for w.Health() != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("Health() while running = %v, want nil", w.Health())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Остановленный обработчик перестает отмечаться и через два интервала считается зависшим
+	cancel()
+	<-done
+	time.Sleep(30 * time.Millisecond)
+	if err := w.Health(); err == nil {
+		t.Error("Health() after stop = nil, want error")
+	}
+}
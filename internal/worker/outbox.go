@@ -0,0 +1,108 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+const (
+	outboxMaxBackoff = 10 * time.Minute
+	// outboxLease должен покрывать публикацию всей пачки, иначе события возьмет другой воркер
+	outboxLease = time.Minute
+)
+
+// OutboxRelay периодически забирает события outbox и отправляет их через Publisher.
+// Событие помечается опубликованным только после успешной отправки,
+// поэтому при сбоях возможны повторы с тем же id.
+type OutboxRelay struct {
+	repo      domain.OutboxRepository
+	publisher domain.Publisher
+	interval  time.Duration
+	batchSize int
+	logger    *slog.Logger
+	heartbeat heartbeat
+}
+
+// NewOutboxRelay создает новый экземпляр OutboxRelay
+func NewOutboxRelay(repo domain.OutboxRepository, publisher domain.Publisher, interval time.Duration, batchSize int, logger *slog.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		repo:      repo,
+		publisher: publisher,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger.With("worker", "outbox_relay"),
+	}
+}
+
+// Run обрабатывает очередь до отмены контекста
+func (w *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.heartbeat.beat()
+
+		// Пока пачки приходят полными, очередь разбирается без ожидания тика
+		for w.relayBatch(ctx) == w.batchSize {
+			w.heartbeat.beat()
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Health сообщает, не завис ли обработчик: пачка должна укладываться в срок аренды
+func (w *OutboxRelay) Health() error {
+	return w.heartbeat.check(w.interval + outboxLease)
+}
+
+// relayBatch отправляет одну пачку событий и возвращает ее размер
+func (w *OutboxRelay) relayBatch(ctx context.Context) int {
+	events, err := w.repo.Claim(ctx, w.batchSize, outboxLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.ErrorContext(ctx, "failed to claim events", "error", err)
+		}
+		return 0
+	}
+
+	for _, event := range events {
+		if err := w.publisher.Publish(ctx, event); err != nil {
+			retryAt := time.Now().Add(outboxBackoff(event.Attempts))
+			w.logger.WarnContext(ctx, "failed to publish event",
+				"event_id", event.ID, "attempts", event.Attempts+1, "retry_at", retryAt, "error", err)
+			if err := w.repo.MarkFailed(ctx, event.ID, retryAt, err.Error()); err != nil {
+				w.logger.ErrorContext(ctx, "failed to mark event failed", "event_id", event.ID, "error", err)
+			}
+			continue
+		}
+
+		if err := w.repo.MarkPublished(ctx, event.ID); err != nil {
+			w.logger.ErrorContext(ctx, "failed to mark event published", "event_id", event.ID, "error", err)
+		}
+	}
+
+	return len(events)
+}
+
+// outboxBackoff возвращает задержку перед следующей попыткой: 1с, 2с, 4с... но не больше outboxMaxBackoff
+func outboxBackoff(attempts int) time.Duration {
+	if attempts >= 10 {
+		return outboxMaxBackoff
+	}
+	backoff := time.Second << attempts
+	if backoff > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return backoff
+}
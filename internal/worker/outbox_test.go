@@ -0,0 +1,156 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+// discardLogger отбрасывает записи логов
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+type fakeOutboxRepo struct {
+	pending   []*domain.OutboxEvent
+	claimErr  error
+	published []string
+	failed    map[string]time.Time
+}
+
+func (r *fakeOutboxRepo) Claim(_ context.Context, limit int, _ time.Duration) ([]*domain.OutboxEvent, error) {
+	if r.claimErr != nil {
+		return nil, r.claimErr
+	}
+	if limit > len(r.pending) {
+		limit = len(r.pending)
+	}
+	events := r.pending[:limit]
+	r.pending = r.pending[limit:]
+	return events, nil
+}
+
+func (r *fakeOutboxRepo) MarkPublished(_ context.Context, id string) error {
+	r.published = append(r.published, id)
+	return nil
+}
+
+func (r *fakeOutboxRepo) MarkFailed(_ context.Context, id string, retryAt time.Time, _ string) error {
+	if r.failed == nil {
+		r.failed = make(map[string]time.Time)
+	}
+	r.failed[id] = retryAt
+	return nil
+}
+
+// fakePublisher не доставляет события из failFor
+type fakePublisher struct {
+	failFor map[string]bool
+	sent    []string
+}
+
+func (p *fakePublisher) Publish(_ context.Context, event *domain.OutboxEvent) error {
+	if p.failFor[event.ID] {
+		return errors.New("webhook responded with 503")
+	}
+	p.sent = append(p.sent, event.ID)
+	return nil
+}
+
+func TestOutboxRelayBatch(t *testing.T) {
+	repo := &fakeOutboxRepo{pending: []*domain.OutboxEvent{
+		{ID: "a"},
+		{ID: "b", Attempts: 3},
+		{ID: "c"},
+	}}
+	publisher := &fakePublisher{failFor: map[string]bool{"b": true}}
+	w := NewOutboxRelay(repo, publisher, time.Second, 10, discardLogger)
+
+	started := time.Now()
+	if got := w.relayBatch(context.Background()); got != 3 {
+		t.Fatalf("relayBatch() = %d, want 3", got)
+	}
+
+	// Ошибка одного события не мешает отправке остальных
+	if !equalStrings(publisher.sent, []string{"a", "c"}) {
+		t.Errorf("published = %v, want [a c]", publisher.sent)
+	}
+	if !equalStrings(repo.published, []string{"a", "c"}) {
+		t.Errorf("marked published = %v, want [a c]", repo.published)
+	}
+
+	retryAt, ok := repo.failed["b"]
+	if !ok || len(repo.failed) != 1 {
+		t.Fatalf("marked failed = %v, want only b", repo.failed)
+	}
+	// Четвертая попытка откладывается на 8 секунд
+	if delay := retryAt.Sub(started); delay < 8*time.Second || delay > 9*time.Second {
+		t.Errorf("retry delay = %v, want about 8s", delay)
+	}
+}
+
+func TestOutboxRelayClaimError(t *testing.T) {
+	repo := &fakeOutboxRepo{claimErr: errors.New("connection reset")}
+	publisher := &fakePublisher{}
+	w := NewOutboxRelay(repo, publisher, time.Second, 10, discardLogger)
+
+	if got := w.relayBatch(context.Background()); got != 0 {
+		t.Errorf("relayBatch() = %d, want 0", got)
+	}
+	if len(publisher.sent) != 0 {
+		t.Errorf("published = %v, want nothing", publisher.sent)
+	}
+}
+
+func TestOutboxRelayRunDrainsFullBatches(t *testing.T) {
+	repo := &fakeOutboxRepo{}
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		repo.pending = append(repo.pending, &domain.OutboxEvent{ID: id})
+	}
+	publisher := &fakePublisher{}
+	// Интервал больше таймаута: все события должны уйти без ожидания тика
+	w := NewOutboxRelay(repo, publisher, time.Hour, 2, discardLogger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	w.Run(ctx)
+
+	if !equalStrings(publisher.sent, []string{"a", "b", "c", "d", "e"}) {
+		t.Errorf("published = %v, want all five events", publisher.sent)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: time.Second},
+		{attempts: 1, want: 2 * time.Second},
+		{attempts: 5, want: 32 * time.Second},
+		{attempts: 9, want: 512 * time.Second},
+		{attempts: 10, want: outboxMaxBackoff},
+		{attempts: 100, want: outboxMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempts); got != tt.want {
+			t.Errorf("outboxBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func equalStrings(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
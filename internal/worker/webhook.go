@@ -0,0 +1,132 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/avito/internal/domain"
+)
+
+const (
+	webhookMaxBackoff = time.Hour
+	// webhookLease должен покрывать отправку всей пачки с учетом таймаута запросов
+	webhookLease = 5 * time.Minute
+)
+
+// WebhookDispatcher отправляет доставки вебхуков подписчикам.
+// После maxAttempts неудачных попыток доставка переходит в состояние dead.
+type WebhookDispatcher struct {
+	repo        domain.WebhookRepository
+	sender      domain.WebhookSender
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	logger      *slog.Logger
+	heartbeat   heartbeat
+}
+
+// NewWebhookDispatcher создает новый экземпляр WebhookDispatcher
+func NewWebhookDispatcher(
+	repo domain.WebhookRepository,
+	sender domain.WebhookSender,
+	interval time.Duration,
+	batchSize int,
+	maxAttempts int,
+	logger *slog.Logger,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:        repo,
+		sender:      sender,
+		interval:    interval,
+		batchSize:   batchSize,
+		maxAttempts: maxAttempts,
+		logger:      logger.With("worker", "webhook_dispatcher"),
+	}
+}
+
+// Run обрабатывает очередь доставок до отмены контекста
+func (w *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.heartbeat.beat()
+
+		for w.dispatchBatch(ctx) == w.batchSize {
+			w.heartbeat.beat()
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Health сообщает, не завис ли обработчик: пачка должна укладываться в срок аренды
+func (w *WebhookDispatcher) Health() error {
+	return w.heartbeat.check(w.interval + webhookLease)
+}
+
+// dispatchBatch отправляет одну пачку доставок и возвращает ее размер
+func (w *WebhookDispatcher) dispatchBatch(ctx context.Context) int {
+	dispatches, err := w.repo.ClaimDeliveries(ctx, w.batchSize, webhookLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.ErrorContext(ctx, "failed to claim deliveries", "error", err)
+		}
+		return 0
+	}
+
+	for _, dispatch := range dispatches {
+		w.dispatch(ctx, dispatch)
+	}
+
+	return len(dispatches)
+}
+
+func (w *WebhookDispatcher) dispatch(ctx context.Context, dispatch *domain.WebhookDispatch) {
+	delivery := dispatch.Delivery
+
+	statusCode, sendErr := w.sender.Send(ctx, dispatch)
+	if sendErr == nil {
+		if err := w.repo.MarkDelivered(ctx, delivery.ID, statusCode); err != nil {
+			w.logger.ErrorContext(ctx, "failed to mark delivery delivered", "delivery_id", delivery.ID, "error", err)
+		}
+		return
+	}
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	var retryAt *time.Time
+	if delivery.Attempts+1 < w.maxAttempts {
+		next := time.Now().Add(webhookBackoff(delivery.Attempts))
+		retryAt = &next
+	}
+
+	w.logger.WarnContext(ctx, "failed to deliver webhook",
+		"delivery_id", delivery.ID, "attempts", delivery.Attempts+1, "status", statusCode, "error", sendErr)
+	if err := w.repo.MarkFailed(ctx, delivery.ID, code, sendErr.Error(), retryAt); err != nil {
+		w.logger.ErrorContext(ctx, "failed to mark delivery failed", "delivery_id", delivery.ID, "error", err)
+	}
+}
+
+// webhookBackoff возвращает задержку перед следующей попыткой: 10с, 20с, 40с... но не больше webhookMaxBackoff
+func webhookBackoff(attempts int) time.Duration {
+	if attempts >= 10 {
+		return webhookMaxBackoff
+	}
+	backoff := 10 * time.Second << attempts
+	if backoff > webhookMaxBackoff {
+		return webhookMaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: 10 * time.Second},
+		{attempts: 1, want: 20 * time.Second},
+		{attempts: 2, want: 40 * time.Second},
+		{attempts: 5, want: 320 * time.Second},
+		{attempts: 8, want: 2560 * time.Second},
+		{attempts: 9, want: webhookMaxBackoff},
+		{attempts: 10, want: webhookMaxBackoff},
+		{attempts: 64, want: webhookMaxBackoff},
+	}
+
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+ALTER TABLE purchases
+    ADD COLUMN unit_price BIGINT,
+    ADD COLUMN total_price BIGINT;
+
+-- Заполняем цены для существующих покупок по текущей цене мерча
+UPDATE purchases p
+SET unit_price = m.price,
+    total_price = m.price * p.quantity
+FROM merch m
+WHERE p.merch_id = m.id;
+
+ALTER TABLE purchases
+    ALTER COLUMN unit_price SET NOT NULL,
+    ALTER COLUMN total_price SET NOT NULL,
+    ADD CONSTRAINT chk_purchases_unit_price CHECK (unit_price > 0),
+    ADD CONSTRAINT chk_purchases_total_price CHECK (total_price > 0);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE purchases
+    DROP CONSTRAINT chk_purchases_total_price,
+    DROP CONSTRAINT chk_purchases_unit_price,
+    DROP COLUMN total_price,
+    DROP COLUMN unit_price;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE cart_items (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    merch_id BIGINT NOT NULL REFERENCES merch(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, merch_id)
+);
+
+CREATE TABLE orders (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    total_price BIGINT NOT NULL CHECK (total_price > 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Покупки, оформленные через корзину, группируются по заказу
+ALTER TABLE purchases ADD COLUMN order_id BIGINT REFERENCES orders(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_orders_user_id ON orders(user_id);
+CREATE INDEX idx_purchases_order_id ON purchases(order_id);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE purchases DROP COLUMN order_id;
+DROP TABLE orders;
+DROP TABLE cart_items;
@@ -0,0 +1,53 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+ALTER TABLE users ADD COLUMN is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE orders
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'placed'
+        CHECK (status IN ('placed', 'packed', 'shipped', 'delivered', 'cancelled')),
+    ADD COLUMN delivery_method VARCHAR(16) NOT NULL DEFAULT 'office'
+        CHECK (delivery_method IN ('office', 'pickup')),
+    ADD COLUMN office_location TEXT,
+    ADD COLUMN pickup_point TEXT,
+    ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+-- Каждая старая покупка без заказа получает собственный выданный заказ
+ALTER TABLE orders ADD COLUMN legacy_purchase_id BIGINT;
+
+INSERT INTO orders (user_id, total_price, status, created_at, updated_at, legacy_purchase_id)
+SELECT user_id, total_price, 'delivered', created_at, created_at, id
+FROM purchases
+WHERE order_id IS NULL;
+
+UPDATE purchases p
+SET order_id = o.id
+FROM orders o
+WHERE o.legacy_purchase_id = p.id;
+
+ALTER TABLE orders DROP COLUMN legacy_purchase_id;
+
+-- Покупка теперь всегда строка заказа: обнулить ссылку нельзя, она удаляется вместе с заказом
+ALTER TABLE purchases ALTER COLUMN order_id SET NOT NULL;
+ALTER TABLE purchases
+    DROP CONSTRAINT purchases_order_id_fkey,
+    ADD CONSTRAINT purchases_order_id_fkey
+        FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_orders_status ON orders(status);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX idx_orders_status;
+ALTER TABLE purchases
+    DROP CONSTRAINT purchases_order_id_fkey,
+    ADD CONSTRAINT purchases_order_id_fkey
+        FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE SET NULL;
+ALTER TABLE purchases ALTER COLUMN order_id DROP NOT NULL;
+ALTER TABLE orders
+    DROP COLUMN updated_at,
+    DROP COLUMN pickup_point,
+    DROP COLUMN office_location,
+    DROP COLUMN delivery_method,
+    DROP COLUMN status;
+ALTER TABLE users DROP COLUMN is_admin;
@@ -0,0 +1,51 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE merch_variants (
+    id BIGSERIAL PRIMARY KEY,
+    merch_id BIGINT NOT NULL REFERENCES merch(id) ON DELETE CASCADE,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    size VARCHAR(16),
+    color VARCHAR(32),
+    price BIGINT CHECK (price > 0), -- NULL означает цену базового товара
+    stock INT NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_merch_variants_merch_id ON merch_variants(merch_id);
+
+ALTER TABLE purchases ADD COLUMN variant_id BIGINT REFERENCES merch_variants(id);
+
+-- В корзине один товар может лежать в нескольких вариантах
+ALTER TABLE cart_items
+    DROP CONSTRAINT cart_items_pkey,
+    ADD COLUMN id BIGSERIAL PRIMARY KEY,
+    ADD COLUMN variant_id BIGINT REFERENCES merch_variants(id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX idx_cart_items_user_merch_variant
+    ON cart_items(user_id, merch_id, COALESCE(variant_id, 0));
+
+-- Добавляем размеры для одежды
+INSERT INTO merch_variants (merch_id, sku, size, stock)
+SELECT m.id, m.name || '-' || lower(s.size), s.size, 100
+FROM merch m
+CROSS JOIN (VALUES ('S'), ('M'), ('L'), ('XL')) AS s(size)
+WHERE m.name IN ('t-shirt', 'hoody', 'pink-hoody');
+
+INSERT INTO merch_variants (merch_id, sku, size, stock)
+SELECT m.id, m.name || '-' || s.size, s.size, 100
+FROM merch m
+CROSS JOIN (VALUES ('36-40'), ('41-45')) AS s(size)
+WHERE m.name = 'socks';
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DELETE FROM cart_items WHERE variant_id IS NOT NULL;
+DROP INDEX idx_cart_items_user_merch_variant;
+ALTER TABLE cart_items
+    DROP COLUMN variant_id,
+    DROP COLUMN id,
+    ADD PRIMARY KEY (user_id, merch_id);
+ALTER TABLE purchases DROP COLUMN variant_id;
+DROP TABLE merch_variants;
@@ -0,0 +1,72 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+CREATE TABLE categories (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE tags (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE
+);
+
+CREATE TABLE merch_tags (
+    merch_id BIGINT NOT NULL REFERENCES merch(id) ON DELETE CASCADE,
+    tag_id BIGINT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (merch_id, tag_id)
+);
+
+ALTER TABLE merch
+    ADD COLUMN category_id BIGINT REFERENCES categories(id) ON DELETE SET NULL,
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', name), 'A') ||
+        setweight(to_tsvector('russian', COALESCE(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX idx_merch_category_id ON merch(category_id);
+CREATE INDEX idx_merch_price ON merch(price);
+CREATE INDEX idx_merch_search_vector ON merch USING GIN (search_vector);
+CREATE INDEX idx_merch_tags_tag_id ON merch_tags(tag_id);
+CREATE INDEX idx_purchases_merch_id ON purchases(merch_id);
+
+-- Заполняем категории и теги для базового мерча
+INSERT INTO categories (name) VALUES
+    ('clothing'),
+    ('accessories'),
+    ('stationery'),
+    ('gadgets');
+
+UPDATE merch m
+SET category_id = c.id
+FROM categories c
+WHERE (c.name = 'clothing' AND m.name IN ('t-shirt', 'hoody', 'pink-hoody', 'socks'))
+   OR (c.name = 'accessories' AND m.name IN ('cup', 'umbrella', 'wallet'))
+   OR (c.name = 'stationery' AND m.name IN ('book', 'pen'))
+   OR (c.name = 'gadgets' AND m.name IN ('powerbank'));
+
+INSERT INTO tags (name) VALUES
+    ('logo'),
+    ('gift'),
+    ('warm'),
+    ('office');
+
+INSERT INTO merch_tags (merch_id, tag_id)
+SELECT m.id, t.id
+FROM merch m
+JOIN tags t ON
+    (t.name = 'logo' AND m.name IN ('t-shirt', 'hoody', 'socks'))
+    OR (t.name = 'gift' AND m.name IN ('cup', 'book', 'wallet', 'pink-hoody', 'powerbank'))
+    OR (t.name = 'warm' AND m.name IN ('hoody', 'pink-hoody', 'socks'))
+    OR (t.name = 'office' AND m.name IN ('cup', 'pen', 'book'));
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX idx_purchases_merch_id;
+ALTER TABLE merch
+    DROP COLUMN search_vector,
+    DROP COLUMN category_id;
+DROP TABLE merch_tags;
+DROP TABLE tags;
+DROP TABLE categories;
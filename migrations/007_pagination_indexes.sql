@@ -0,0 +1,13 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Индексы под keyset-пагинацию истории по (created_at, id)
+CREATE INDEX idx_transactions_from_user_created ON transactions(from_user_id, created_at DESC, id DESC);
+CREATE INDEX idx_transactions_to_user_created ON transactions(to_user_id, created_at DESC, id DESC);
+CREATE INDEX idx_purchases_user_created ON purchases(user_id, created_at DESC, id DESC);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX idx_purchases_user_created;
+DROP INDEX idx_transactions_to_user_created;
+DROP INDEX idx_transactions_from_user_created;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Ключи файлов в хранилище, URL собираются приложением
+ALTER TABLE merch
+    ADD COLUMN image_key VARCHAR(255),
+    ADD COLUMN thumbnail_key VARCHAR(255);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE merch
+    DROP COLUMN thumbnail_key,
+    DROP COLUMN image_key;
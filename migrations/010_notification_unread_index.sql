@@ -0,0 +1,10 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Счетчик непрочитанных запрашивается на каждом обновлении интерфейса
+CREATE INDEX idx_notifications_user_unread ON notifications(user_id, created_at DESC, id DESC)
+    WHERE read_at IS NULL;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX idx_notifications_user_unread;
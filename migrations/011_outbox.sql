@@ -0,0 +1,23 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- События пишутся в одной транзакции с изменением данных и отправляются воркером.
+-- id передается получателям для дедупликации: доставка выполняется не менее одного раза.
+CREATE TABLE outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    aggregate_type VARCHAR(64) NOT NULL,
+    aggregate_id BIGINT NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    available_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_outbox_pending ON outbox(available_at, created_at) WHERE published_at IS NULL;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE outbox;
@@ -0,0 +1,41 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Каждая запись хранит хеш предыдущей, поэтому изменение или удаление
+-- любой строки обнаруживается проверкой цепочки
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_id BIGINT,
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(64) NOT NULL,
+    target_id VARCHAR(255) NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE
+);
+
+CREATE INDEX idx_audit_log_created ON audit_log(created_at DESC, id DESC);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, created_at DESC, id DESC);
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id);
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE audit_log;
+DROP FUNCTION audit_log_append_only();
@@ -0,0 +1,58 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Системные счета не принадлежат людям, не могут войти и могут уходить в минус
+ALTER TABLE users ADD COLUMN is_system BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD CONSTRAINT users_balance_non_negative CHECK (balance >= 0 OR is_system);
+
+-- Казначейство - контрагент начислений, списаний и возвратов. Его отрицательный баланс равен
+-- количеству выпущенных монет, поэтому уже выданные монеты учитываются как выпущенные им:
+-- текущие балансы плюс потраченное на все заказы. Возвраты за отмененные заказы
+-- ниже проводятся как переводы от казначейства, поэтому отмененные заказы тоже считаются.
+INSERT INTO users (username, password_hash, balance, is_system)
+SELECT 'treasury', '!', -(
+    (SELECT COALESCE(SUM(balance), 0) FROM users) +
+    (SELECT COALESCE(SUM(total_price), 0) FROM orders)
+), TRUE;
+
+ALTER TABLE transactions ADD COLUMN kind VARCHAR(16) NOT NULL DEFAULT 'transfer'
+    CHECK (kind IN ('transfer', 'grant', 'adjustment', 'refund'));
+ALTER TABLE transactions ADD COLUMN reason_code VARCHAR(32);
+ALTER TABLE transactions ADD COLUMN created_by BIGINT REFERENCES users(id);
+
+-- Монеты за уже отмененные заказы вернулись на балансы без записи о переводе:
+-- восстанавливаем возвраты задним числом, балансы при этом не меняются
+INSERT INTO transactions (from_user_id, to_user_id, amount, description, kind, created_at)
+SELECT t.id, o.user_id, o.total_price, 'Возврат за заказ №' || o.id, 'refund', o.updated_at
+FROM orders o
+CROSS JOIN (SELECT id FROM users WHERE username = 'treasury' AND is_system) t
+WHERE o.status = 'cancelled' AND o.total_price > 0
+ORDER BY o.updated_at, o.id;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+-- Откат возможен только до первого движения через казначейство, кроме перенесенных возвратов:
+-- начисления и корректировки входят в историю балансов, на них ссылаются журнал аудита и события outbox.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM transactions t
+        JOIN users u ON u.id IN (t.from_user_id, t.to_user_id)
+        WHERE u.is_system AND t.kind <> 'refund'
+    ) THEN
+        RAISE EXCEPTION 'migration 014 is irreversible: treasury transactions exist';
+    END IF;
+END;
+$$;
+-- +goose StatementEnd
+
+-- До этой миграции возвраты проводились без записи о переводе
+DELETE FROM transactions WHERE kind = 'refund';
+ALTER TABLE transactions DROP COLUMN created_by;
+ALTER TABLE transactions DROP COLUMN reason_code;
+ALTER TABLE transactions DROP COLUMN kind;
+DELETE FROM users WHERE is_system;
+ALTER TABLE users DROP CONSTRAINT users_balance_non_negative;
+ALTER TABLE users DROP COLUMN is_system;
@@ -0,0 +1,52 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Расписания регулярных начислений: amount монет каждому пользователю в day_of_month числа месяца.
+-- Расписания не удаляются, а отключаются, чтобы история запусков оставалась связанной.
+CREATE TABLE grant_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    day_of_month INTEGER NOT NULL CHECK (day_of_month BETWEEN 1 AND 28),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Запуски начислений и сгорания. Запись создается в одной транзакции с движением монет,
+-- уникальность по периоду не дает провести его повторно после перезапуска.
+CREATE TABLE allowance_runs (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('grant', 'expiry')),
+    schedule_id BIGINT REFERENCES grant_schedules(id),
+    period DATE NOT NULL,
+    users_count INTEGER NOT NULL DEFAULT 0,
+    amount BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_allowance_runs_period ON allowance_runs(kind, COALESCE(schedule_id, 0), period);
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_kind_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_kind_check
+    CHECK (kind IN ('transfer', 'grant', 'adjustment', 'refund', 'expiry'));
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+
+-- Сгорание нельзя выразить видами переводов до этой миграции, поэтому после первого
+-- сгорания откат невозможен
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM transactions WHERE kind = 'expiry') THEN
+        RAISE EXCEPTION 'migration 015 is irreversible: expiry transactions exist';
+    END IF;
+END;
+$$;
+-- +goose StatementEnd
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_kind_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_kind_check
+    CHECK (kind IN ('transfer', 'grant', 'adjustment', 'refund'));
+DROP TABLE allowance_runs;
+DROP TABLE grant_schedules;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Благодарности: отправитель может опубликовать перевод в общей ленте и указать категорию
+ALTER TABLE transactions ADD COLUMN is_public BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE transactions ADD COLUMN category VARCHAR(16)
+    CHECK (category IN ('teamwork', 'help', 'mentoring'));
+
+CREATE INDEX idx_transactions_public ON transactions(created_at DESC, id DESC) WHERE is_public;
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX idx_transactions_public;
+ALTER TABLE transactions DROP COLUMN category;
+ALTER TABLE transactions DROP COLUMN is_public;
@@ -0,0 +1,44 @@
+-- +goose Up
+-- SQL in section 'Up' is executed when this migration is applied
+
+-- Дневные объемы покупок и переводов по UTC. Обновляется фоновым воркером,
+-- поэтому данные за текущий день отстают на интервал обновления.
+-- Отмененные заказы не учитываются, начисления и корректировки не считаются переводами.
+CREATE MATERIALIZED VIEW analytics_daily_volumes AS
+WITH purchased AS (
+    SELECT (p.created_at AT TIME ZONE 'UTC')::date AS day,
+           COUNT(*) AS purchases_count,
+           SUM(p.quantity) AS purchased_items,
+           SUM(p.total_price) AS purchase_volume
+    FROM purchases p
+    JOIN orders o ON p.order_id = o.id
+    WHERE o.status <> 'cancelled'
+    GROUP BY 1
+), transferred AS (
+    SELECT (created_at AT TIME ZONE 'UTC')::date AS day,
+           COUNT(*) AS transfers_count,
+           SUM(amount) AS transfer_volume
+    FROM transactions
+    WHERE kind = 'transfer'
+    GROUP BY 1
+)
+SELECT COALESCE(p.day, t.day) AS day,
+       COALESCE(p.purchases_count, 0) AS purchases_count,
+       COALESCE(p.purchased_items, 0) AS purchased_items,
+       COALESCE(p.purchase_volume, 0) AS purchase_volume,
+       COALESCE(t.transfers_count, 0) AS transfers_count,
+       COALESCE(t.transfer_volume, 0) AS transfer_volume
+FROM purchased p
+FULL JOIN transferred t ON p.day = t.day;
+
+-- Уникальный индекс нужен для REFRESH MATERIALIZED VIEW CONCURRENTLY
+CREATE UNIQUE INDEX idx_analytics_daily_volumes_day ON analytics_daily_volumes(day);
+
+CREATE INDEX idx_purchases_created_at ON purchases(created_at);
+CREATE INDEX idx_transactions_created_at ON transactions(created_at);
+
+-- +goose Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP INDEX idx_transactions_created_at;
+DROP INDEX idx_purchases_created_at;
+DROP MATERIALIZED VIEW analytics_daily_volumes;
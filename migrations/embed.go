@@ -0,0 +1,10 @@
+// Package migrations встраивает SQL-миграции в бинарник, чтобы их можно было
+// применить без исходников и внешнего goose.
+package migrations
+
+import "embed"
+
+// FS содержит файлы миграций NNN_name.sql в формате goose
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,105 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+const (
+	// DefaultLimit используется, если размер страницы не указан
+	DefaultLimit = 20
+	// MaxLimit ограничивает размер страницы сверху
+	MaxLimit = 100
+)
+
+var (
+	// ErrInvalidLimit возвращается при некорректном размере страницы
+	ErrInvalidLimit = errors.New("invalid page size")
+
+	// ErrInvalidCursor возвращается, если курсор поврежден или не подходит к запросу
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// Params содержит параметры запроса страницы
+type Params struct {
+	Limit  int
+	Cursor string
+}
+
+// NewParams проверяет размер страницы и курсор из запроса.
+// Пустой limit заменяется на DefaultLimit.
+func NewParams(limit, cursor string) (Params, error) {
+	params := Params{Limit: DefaultLimit, Cursor: cursor}
+
+	if limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 || n > MaxLimit {
+			return Params{}, ErrInvalidLimit
+		}
+		params.Limit = n
+	}
+
+	return params, nil
+}
+
+// IsFirst сообщает, что запрашивается первая страница
+func (p Params) IsFirst() bool {
+	return p.Cursor == ""
+}
+
+// Page представляет страницу результатов
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Total заполняется только на первой странице
+	Total *int64 `json:"total,omitempty"`
+	Links *Links `json:"links,omitempty"`
+}
+
+// Links содержит ссылки на соседние страницы
+type Links struct {
+	Next string `json:"next,omitempty"`
+}
+
+// NewPage собирает страницу из выборки размером до limit+1 элементов.
+// Лишний элемент означает наличие следующей страницы, курсор строится по последнему элементу страницы.
+func NewPage[T any](items []T, limit int, cursor func(last T) any) (*Page[T], error) {
+	page := &Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		next, err := EncodeCursor(cursor(page.Items[limit-1]))
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = next
+	}
+
+	return page, nil
+}
+
+// EncodeCursor упаковывает значения ключа в непрозрачную строку
+func EncodeCursor(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor распаковывает курсор, созданный EncodeCursor
+func DecodeCursor(cursor string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrInvalidCursor
+	}
+	return nil
+}
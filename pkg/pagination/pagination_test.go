@@ -0,0 +1,143 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor testCursor
+	}{
+		{name: "zero", cursor: testCursor{}},
+		{name: "time and id", cursor: testCursor{CreatedAt: time.Date(2024, 3, 15, 10, 30, 0, 123456000, time.UTC), ID: 42}},
+		{name: "max id", cursor: testCursor{CreatedAt: time.Unix(0, 0).UTC(), ID: 1<<63 - 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := EncodeCursor(tt.cursor)
+			if err != nil {
+				t.Fatalf("EncodeCursor: %v", err)
+			}
+
+			var decoded testCursor
+			if err := DecodeCursor(encoded, &decoded); err != nil {
+				t.Fatalf("DecodeCursor(%q): %v", encoded, err)
+			}
+			if !decoded.CreatedAt.Equal(tt.cursor.CreatedAt) || decoded.ID != tt.cursor.ID {
+				t.Errorf("decoded %+v, want %+v", decoded, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	valid, err := EncodeCursor(testCursor{ID: 1})
+	if err != nil {
+		t.Fatalf("EncodeCursor: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!!"},
+		{name: "padded base64", cursor: valid + "="},
+		{name: "not json", cursor: "bm90IGpzb24"},
+		{name: "wrong type", cursor: "eyJpZCI6ImEifQ"},
+		{name: "truncated", cursor: valid[:len(valid)-2]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var decoded testCursor
+			if err := DecodeCursor(tt.cursor, &decoded); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor(%q) error = %v, want ErrInvalidCursor", tt.cursor, err)
+			}
+		})
+	}
+}
+
+func TestNewParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   string
+		want    int
+		wantErr error
+	}{
+		{name: "default", limit: "", want: DefaultLimit},
+		{name: "min", limit: "1", want: 1},
+		{name: "max", limit: "100", want: MaxLimit},
+		{name: "zero", limit: "0", wantErr: ErrInvalidLimit},
+		{name: "above max", limit: "101", wantErr: ErrInvalidLimit},
+		{name: "negative", limit: "-5", wantErr: ErrInvalidLimit},
+		{name: "not a number", limit: "ten", wantErr: ErrInvalidLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := NewParams(tt.limit, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewParams(%q) error = %v, want %v", tt.limit, err, tt.wantErr)
+			}
+			if err == nil && params.Limit != tt.want {
+				t.Errorf("NewParams(%q).Limit = %d, want %d", tt.limit, params.Limit, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPage(t *testing.T) {
+	cursor := func(last int) any { return testCursor{ID: int64(last)} }
+
+	tests := []struct {
+		name     string
+		items    []int
+		limit    int
+		want     []int
+		wantNext bool
+	}{
+		{name: "nil items", items: nil, limit: 2, want: []int{}},
+		{name: "partial page", items: []int{1}, limit: 2, want: []int{1}},
+		{name: "exact page", items: []int{1, 2}, limit: 2, want: []int{1, 2}},
+		{name: "extra item", items: []int{1, 2, 3}, limit: 2, want: []int{1, 2}, wantNext: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := NewPage(tt.items, tt.limit, cursor)
+			if err != nil {
+				t.Fatalf("NewPage: %v", err)
+			}
+			if len(page.Items) != len(tt.want) {
+				t.Fatalf("items = %v, want %v", page.Items, tt.want)
+			}
+			for i := range tt.want {
+				if page.Items[i] != tt.want[i] {
+					t.Fatalf("items = %v, want %v", page.Items, tt.want)
+				}
+			}
+
+			if (page.NextCursor != "") != tt.wantNext {
+				t.Fatalf("next cursor = %q, want present: %t", page.NextCursor, tt.wantNext)
+			}
+			if tt.wantNext {
+				var decoded testCursor
+				if err := DecodeCursor(page.NextCursor, &decoded); err != nil {
+					t.Fatalf("DecodeCursor: %v", err)
+				}
+				if last := tt.want[len(tt.want)-1]; decoded.ID != int64(last) {
+					t.Errorf("cursor id = %d, want last item %d", decoded.ID, last)
+				}
+			}
+		})
+	}
+}